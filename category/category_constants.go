@@ -7,4 +7,14 @@ const (
 	Offset = 0
 	//DefaultCategory default value when no category is specified
 	DefaultCategory = 0
+	//UnlimitedDepth to list all the levels of sub categories
+	UnlimitedDepth = -1
+	//IncludeVariants to list the products along with their variants
+	IncludeVariants = "variants"
+	//IncludeProducts to list the products without their variants
+	IncludeProducts = "products"
+	//IncludeNone to list the categories without products
+	IncludeNone = "none"
+	//PathSeparator to join the names of a category path, e.g. Men > Shoes > Sneakers
+	PathSeparator = " > "
 )
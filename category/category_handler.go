@@ -2,8 +2,10 @@ package category
 
 import (
 	"database/sql"
+	"ecommerce/search"
 	"ecommerce/utils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -19,6 +21,10 @@ type HandlerInterface interface {
 	UpdateCategory(w http.ResponseWriter, r *http.Request)
 	ListCategory(w http.ResponseWriter, r *http.Request)
 	DeleteCategory(w http.ResponseWriter, r *http.Request)
+	GetCategoryPath(w http.ResponseWriter, r *http.Request)
+	ListAttributes(w http.ResponseWriter, r *http.Request)
+	CreateAttribute(w http.ResponseWriter, r *http.Request)
+	DeleteAttribute(w http.ResponseWriter, r *http.Request)
 }
 
 //Handler struct for category management
@@ -27,9 +33,9 @@ type Handler struct {
 }
 
 //NewHTTPHandler to handle category requests
-func NewHTTPHandler(db *sql.DB) HandlerInterface {
+func NewHTTPHandler(db *sql.DB, index search.SearchIndex) HandlerInterface {
 	return &Handler{
-		cs: NewService(db),
+		cs: NewService(db, index),
 	}
 }
 
@@ -58,6 +64,11 @@ func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 			utils.Fail(w, 200, err.Error())
 			return
 		}
+		if err.Error() == utils.ParentCategoryNotExist {
+			log.Println("Error : Parent category error(CreateCategory) -", err.Error())
+			utils.Fail(w, 400, err.Error())
+			return
+		}
 		log.Println("Error : Create category error(CreateCategory) -", err.Error())
 		utils.Fail(w, 500, err.Error())
 		return
@@ -77,6 +88,13 @@ func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 		utils.Fail(w, 400, err.Error())
 		return
 	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(UpdateCategory) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
 	err = h.cs.UpdateCategory(&request)
 	if err != nil {
 		log.Println("Error : (UpdateCategory) -", err.Error())
@@ -92,6 +110,10 @@ func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 			utils.Fail(w, 400, err.Error())
 			return
 		}
+		if err.Error() == utils.ParentCategoryNotExist || err.Error() == utils.CategoryCycleError || err.Error() == utils.AttributeConflictError {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
 		utils.Fail(w, 500, err.Error())
 		return
 	}
@@ -136,14 +158,183 @@ func (h *Handler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	utils.Send(w, 200, &message)
 }
 
-//ListCategory to list all the categories
+//ListCategory to list all the categories, or the sub tree of the given category
 func (h *Handler) ListCategory(w http.ResponseWriter, r *http.Request) {
 	log.Println("App : /category GET API")
-	categoryList, err := h.cs.ListCategory()
+	request, err := parseListRequest(r)
+	if err != nil {
+		log.Println("Error : request validation error(ListCategory) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	categoryList, err := h.cs.ListCategory(request)
 	if err != nil {
 		log.Println("Error : category listing error(ListCategory) -", err.Error())
+		if err.Error() == utils.CategoryNOTExistsError {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
 		utils.Fail(w, 500, err.Error())
+		return
 	}
 	log.Println("App : Category listed successfully")
 	utils.Send(w, 200, &categoryList)
-}
\ No newline at end of file
+}
+
+//GetCategoryPath to get the breadcrumb of a category
+func (h *Handler) GetCategoryPath(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /category/{category_id}/path GET API")
+	categoryID, err := strconv.Atoi(chi.URLParam(r, "category_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (GetCategoryPath)")
+		utils.Fail(w, 400, errors.New(utils.InvalidCategoryID).Error())
+		return
+	}
+	path, err := h.cs.GetCategoryPath(categoryID)
+	if err != nil {
+		log.Println("Error : error fetching category path(GetCategoryPath) -", err.Error())
+		if err.Error() == utils.CategoryNOTExistsError {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Category path fetched successfully, category id -", categoryID)
+	utils.Send(w, 200, path)
+}
+
+//isAttributeRequestError to check if the attribute error is caused by the request
+func isAttributeRequestError(err error) bool {
+	switch err.Error() {
+	case utils.CategoryNOTExistsError, utils.InvalidAttributeError, utils.AttributeNotExist, utils.AttributeInUseError:
+		return true
+	}
+	return false
+}
+
+//ListAttributes to handle the category attribute listing request
+func (h *Handler) ListAttributes(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /category/{category_id}/attributes GET API")
+	categoryID, err := strconv.Atoi(chi.URLParam(r, "category_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (ListAttributes)")
+		utils.Fail(w, 400, utils.InvalidCategoryID)
+		return
+	}
+	attributes, err := h.cs.ListAttributes(categoryID)
+	if err != nil {
+		log.Println("Error : (ListAttributes) -", err.Error())
+		if isAttributeRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Category attributes listed successfully, category id -", categoryID)
+	utils.Send(w, 200, attributes)
+}
+
+//CreateAttribute to handle the category attribute post request
+func (h *Handler) CreateAttribute(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /category/{category_id}/attributes POST API")
+	categoryID, err := strconv.Atoi(chi.URLParam(r, "category_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (CreateAttribute)")
+		utils.Fail(w, 400, utils.InvalidCategoryID)
+		return
+	}
+	var request AttributeRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(CreateAttribute) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.CategoryID = categoryID
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(CreateAttribute) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	attribute, err := h.cs.CreateAttribute(&request)
+	if err != nil {
+		log.Println("Error : (CreateAttribute) -", err.Error())
+		if err.Error() == utils.AttributeExistsError {
+			utils.Fail(w, 200, err.Error())
+			return
+		}
+		if isAttributeRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Category attribute created successfully, attribute id -", attribute.ID)
+	utils.Send(w, 200, attribute)
+}
+
+//DeleteAttribute to handle the category attribute delete request
+func (h *Handler) DeleteAttribute(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /category/{category_id}/attributes/{attribute_id} DELETE API")
+	categoryID, err := strconv.Atoi(chi.URLParam(r, "category_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (DeleteAttribute)")
+		utils.Fail(w, 400, utils.InvalidCategoryID)
+		return
+	}
+	attributeID, err := strconv.Atoi(chi.URLParam(r, "attribute_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (DeleteAttribute)")
+		utils.Fail(w, 400, utils.AttributeNotExist)
+		return
+	}
+	err = h.cs.DeleteAttribute(categoryID, attributeID)
+	if err != nil {
+		log.Println("Error : (DeleteAttribute) -", err.Error())
+		if isAttributeRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	message := utils.Message{
+		Message: fmt.Sprintf("Category attribute deleted successfully, attribute id = %d", attributeID),
+	}
+	log.Println(message.Message)
+	utils.Send(w, 200, &message)
+}
+
+func parseListRequest(r *http.Request) (*ListRequest, error) {
+	request := ListRequest{
+		CategoryID: DefaultCategory,
+		Depth:      UnlimitedDepth,
+		Include:    IncludeVariants,
+	}
+	var err error
+	if value := chi.URLParam(r, "category_id"); value != utils.EmptyString {
+		request.CategoryID, err = strconv.Atoi(value)
+		if err != nil || request.CategoryID <= 0 {
+			return nil, errors.New(utils.InvalidCategoryID)
+		}
+	}
+	query := r.URL.Query()
+	if value := query.Get("depth"); value != utils.EmptyString {
+		request.Depth, err = strconv.Atoi(value)
+		if err != nil || request.Depth < 0 {
+			return nil, errors.New(utils.InvalidParameterError + " depth")
+		}
+	}
+	if value := query.Get("include"); value != utils.EmptyString {
+		if value != IncludeVariants && value != IncludeProducts && value != IncludeNone {
+			return nil, errors.New(utils.InvalidParameterError + " include")
+		}
+		request.Include = value
+	}
+	return &request, nil
+}
@@ -1,5 +1,7 @@
 package category
 
+import "ecommerce/utils"
+
 //CreateRequest to represent the category post request
 type CreateRequest struct {
 	Name     string `json:"name" validate:"required"`
@@ -13,21 +15,29 @@ type CreateResponse struct {
 	ParentID int    `json:"parent_id"`
 }
 
-//UpdateRequest to represent category update request
+//UpdateRequest to represent category update request, a nil ParentID keeps the current parent
+//and a ParentID of 0 moves the category to the root
 type UpdateRequest struct {
 	CategoryID int    `json:"category_id" validate:"required"`
 	Name       string `json:"name"`
-	ParentID   int    `json:"parent_id" validate:"omitempty, gt=0"`
+	ParentID   *int   `json:"parent_id" validate:"omitempty,gte=0"`
+}
+
+//ListRequest to represent the category listing request
+type ListRequest struct {
+	CategoryID int
+	Depth      int
+	Include    string
 }
 
 //Variant to represent variant struct
 type Variant struct {
-	VariantID     int     `json:"variant_id"`
-	Name          string  `json:"name,omitempty"`
-	MRP           float64 `json:"max_retail_price"`
-	DiscountPrice float64 `json:"discount_price,omitempty"`
-	Size          string  `json:"size,omitempty"`
-	Color         string  `json:"color,omitempty"`
+	VariantID     int          `json:"variant_id"`
+	Name          string       `json:"name,omitempty"`
+	MRP           utils.Money  `json:"max_retail_price"`
+	DiscountPrice *utils.Money `json:"discount_price,omitempty"`
+	Size          string       `json:"size,omitempty"`
+	Color         string       `json:"color,omitempty"`
 }
 
 //Product to represent product struct
@@ -44,16 +54,17 @@ type Product struct {
 type CategoryList struct {
 	CategoryID int            `json:"category_id"`
 	Name       string         `json:"category_name"`
-	Products   []Product      `json:"products"`
+	Products   []Product      `json:"products,omitempty"`
 	Categories []CategoryList `json:"categories"`
 }
 
 //CategoryRelationship represent the category sub category relationships
 type CategoryRelationship struct {
 	CategoryID  int
-	Ancestor    []int
-	level       int
-	FirstParent int
+	Name        string
+	Ancestor    []int //ancestor category IDs ordered from the root down
+	Level       int   //number of ancestors, 0 for a main category
+	FirstParent int   //immediate parent category ID, 0 for a main category
 }
 
 // ProductVariantRow to represent product variant combination row
@@ -65,8 +76,9 @@ type ProductVariantRow struct {
 	CategoryID    int
 	VariantID     int
 	VariantName   string
-	MRP           float64
-	DiscountPrice float64
+	MRP           int64
+	DiscountPrice int64
+	Currency      string
 	Size          string
 	Color         string
 }
@@ -77,3 +89,31 @@ type Category struct {
 	Name     string
 	ParentID int
 }
+
+//Breadcrumb to represent a category in the path from the root category
+type Breadcrumb struct {
+	ID   int    `json:"category_id"`
+	Name string `json:"name"`
+}
+
+//AttributeRequest to represent the category attribute create request, the values are the allowed
+//values of an enum attribute
+type AttributeRequest struct {
+	CategoryID int      `json:"-"`
+	Name       string   `json:"name" validate:"required,max=50"`
+	Type       string   `json:"type" validate:"required,oneof=string int float bool enum"`
+	Unit       string   `json:"unit,omitempty" validate:"max=20"`
+	Values     []string `json:"values,omitempty" validate:"dive,required,max=50"`
+	Required   bool     `json:"required"`
+}
+
+//Attribute to represent an attribute of the products of a category and its sub categories
+type Attribute struct {
+	ID         int      `json:"attribute_id"`
+	CategoryID int      `json:"category_id"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Unit       string   `json:"unit,omitempty"`
+	Values     []string `json:"values,omitempty"`
+	Required   bool     `json:"required"`
+}
@@ -5,8 +5,9 @@ import (
 	"ecommerce/utils"
 	"errors"
 	"fmt"
-	"log"
 	"strings"
+
+	"github.com/lib/pq"
 )
 
 //Repo is the DB repo struct
@@ -94,14 +95,19 @@ func (repo *Repo) IsCategoryIDExists(id int) (bool, error) {
 	return false, nil
 }
 
-//UpdateCategory to update a category
+//UpdateCategory to update a category. A new parent is checked again in the transaction of the update
+//once the rows of the category and of the chain of the parent are locked, so concurrent re-parenting
+//requests can't create a cycle between them
 func (repo *Repo) UpdateCategory(request *UpdateRequest) error {
 	var slice []string
+	args := []interface{}{request.CategoryID}
 	if len(request.Name) > 0 && request.Name != utils.EmptyString {
-		slice = append(slice, fmt.Sprintf(" name = '%s' ", request.Name))
+		args = append(args, request.Name)
+		slice = append(slice, fmt.Sprintf(" name = $%d ", len(args)))
 	}
-	if request.ParentID != 0 {
-		slice = append(slice, fmt.Sprintf(" parent_category_id = %d ", request.ParentID))
+	if request.ParentID != nil {
+		args = append(args, getNullInt32(*request.ParentID))
+		slice = append(slice, fmt.Sprintf(" parent_category_id = $%d ", len(args)))
 	}
 	slice = append(slice, fmt.Sprintf(" updated_at = NOW() "))
 	updateQuery := strings.Join(slice, ", ")
@@ -116,17 +122,193 @@ func (repo *Repo) UpdateCategory(request *UpdateRequest) error {
 			deleted_at IS NULL
 	`
 	query := fmt.Sprintf(mainQuery, updateQuery)
-	result, err := repo.DB.Exec(query, request.CategoryID)
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	//the parent chain stays empty, not NULL, when the category is moved to the root
+	chain := []int{}
+	if request.ParentID != nil && *request.ParentID != 0 {
+		chain, err = lockParentChain(tx, request.CategoryID, *request.ParentID)
+		if err != nil {
+			return err
+		}
+		if len(chain) == 0 {
+			return errors.New(utils.ParentCategoryNotExist)
+		}
+		for _, ancestorID := range chain {
+			if ancestorID == request.CategoryID {
+				return errors.New(utils.CategoryCycleError)
+			}
+		}
+		conflict, err := isAttributeNameConflict(tx, request.CategoryID, chain)
+		if err != nil {
+			return err
+		}
+		if conflict {
+			return errors.New(utils.AttributeConflictError)
+		}
+	}
+	result, err := tx.Exec(query, args...)
 	if err != nil {
 		return err
 	}
 	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
 	if rowsAffected == 0 {
 		return errors.New(utils.InvalidCategoryID)
 	}
+	if request.ParentID != nil {
+		err = deleteInheritedValues(tx, request.CategoryID, chain)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+//subtreeQuery selects the category $1 and its descendants
+const subtreeQuery = `
+		WITH RECURSIVE subtree AS (
+			SELECT
+				category_id, ARRAY[category_id] AS path
+			FROM
+				tbl_category
+			WHERE
+				category_id = $1
+			UNION ALL
+			SELECT
+				c.category_id, s.path || c.category_id
+			FROM
+				tbl_category c
+			JOIN
+				subtree s
+			ON
+				c.parent_category_id = s.category_id
+			WHERE
+				NOT c.category_id = ANY(s.path)
+		)
+`
+
+//isAttributeNameConflict to check if the category or its descendants define an attribute with the name of
+//an attribute of the new parent chain, as the products of the subtree would then inherit both
+func isAttributeNameConflict(tx *sql.Tx, categoryID int, chain []int) (bool, error) {
+	var count int
+	query := subtreeQuery + `
+		SELECT
+			count(*)
+		FROM
+			tbl_category_attribute moved
+		JOIN
+			tbl_category_attribute inherited
+		ON
+			lower(inherited.name) = lower(moved.name)
+		WHERE
+			moved.category_id IN (SELECT category_id FROM subtree)
+		AND
+			inherited.category_id = ANY($2)
+	`
+	err := tx.QueryRow(query, categoryID, pq.Array(chain)).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//deleteInheritedValues to delete the attribute values of the products of the moved category and its
+//descendants whose attribute is no longer defined by the subtree or by its new parent chain
+func deleteInheritedValues(tx *sql.Tx, categoryID int, chain []int) error {
+	query := subtreeQuery + `
+		DELETE FROM
+			tbl_product_attribute pa
+		USING
+			tbl_product p, tbl_category_attribute ca
+		WHERE
+			p.product_id = pa.product_id
+		AND
+			ca.attribute_id = pa.attribute_id
+		AND
+			p.category_id IN (SELECT category_id FROM subtree)
+		AND
+			ca.category_id NOT IN (SELECT category_id FROM subtree)
+		AND NOT
+			ca.category_id = ANY($2)
+	`
+	_, err := tx.Exec(query, categoryID, pq.Array(chain))
 	return err
 }
 
+//lockParentChain to lock the rows of the category and of the new parent with its ancestors, returning
+//the parent with its ancestors as read once the locks are held. The rows are locked in category ID
+//order and the chain is read again until every row of it is locked, as a concurrent re-parenting may
+//have changed it while waiting
+func lockParentChain(tx *sql.Tx, categoryID int, parentID int) ([]int, error) {
+	locked := make(map[int]bool)
+	for {
+		chain, err := getAncestorIDs(tx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		var missing []int
+		for _, id := range append(chain, categoryID) {
+			if !locked[id] {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) == 0 {
+			return chain, nil
+		}
+		query := `
+			SELECT
+				category_id
+			FROM
+				tbl_category
+			WHERE
+				category_id = ANY($1)
+			ORDER BY
+				category_id ASC
+			FOR UPDATE
+		`
+		rows, err := tx.Query(query, pq.Array(missing))
+		if err != nil {
+			return nil, err
+		}
+		rows.Close()
+		for _, id := range missing {
+			locked[id] = true
+		}
+	}
+}
+
+//getAncestorIDs to get the given category and its ancestors, empty when the category doesn't exist
+func getAncestorIDs(tx *sql.Tx, categoryID int) ([]int, error) {
+	rows, err := tx.Query(ancestryQuery+`
+		SELECT
+			category_id
+		FROM
+			ancestry
+		ORDER BY
+			distance ASC
+	`, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 //IsSubCategoryExist to check if there are any subcategories for the given category
 func (repo *Repo) IsSubCategoryExist(categoryID int) (bool, error) {
 	var count int
@@ -174,6 +356,34 @@ func (repo *Repo) IsProductExist(categoryID int) (bool, error) {
 	return false, nil
 }
 
+//GetSubtreeProductIDs to get the products of the category and its descendants, deleted ones included so
+//the search index drops them
+func (repo *Repo) GetSubtreeProductIDs(categoryID int) ([]int, error) {
+	query := subtreeQuery + `
+		SELECT
+			product_id
+		FROM
+			tbl_product
+		WHERE
+			category_id IN (SELECT category_id FROM subtree)
+	`
+	rows, err := repo.DB.Query(query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var productIDs []int
+	for rows.Next() {
+		var productID int
+		err := rows.Scan(&productID)
+		if err != nil {
+			return nil, err
+		}
+		productIDs = append(productIDs, productID)
+	}
+	return productIDs, rows.Err()
+}
+
 //DeleteCategory to check if the given category exists
 func (repo *Repo) DeleteCategory(categoryID int) error {
 	query := `
@@ -201,36 +411,46 @@ func (repo *Repo) DeleteCategory(categoryID int) error {
 }
 
 // GetProductVariantForEachCategory DB function to get the product and variants for each category
-func (repo *Repo) GetProductVariantForEachCategory(categoryIDs []int) ([]Product, error) {
+func (repo *Repo) GetProductVariantForEachCategory(categoryIDs []int, includeVariants bool) ([]Product, error) {
+	if len(categoryIDs) == 0 {
+		return nil, nil
+	}
 	var params []string
 	for i := range categoryIDs {
 		params = append(params, fmt.Sprintf("$%d", i+1))
 	}
-	var description, imageURL, variantName, size, color sql.NullString
-	var maxRetailPrice, discountPrice sql.NullFloat64
+	var description, imageURL, variantName, size, color, currency sql.NullString
+	var maxRetailPrice, discountPrice sql.NullInt64
 	var variantID sql.NullInt32
+	variantJoin := "AND FALSE"
+	if includeVariants {
+		variantJoin = "AND v.deleted_at IS NULL"
+	}
 	query := `
 		SELECT
 			p.product_id, p.name AS product_name, p.description, p.image_url, p.category_id,
 			v.variant_id, v.name AS variant_name, v.max_retail_price, v.discount_price,
-			v.size, v.color
+			v.currency, lo.size, lo.color
 		FROM
 			tbl_product p
 		LEFT JOIN
 			tbl_variant v
 		ON 
 			p.product_id = v.product_id
+			%s
+		LEFT JOIN
+			vw_variant_legacy_option lo
+		ON
+			lo.variant_id = v.variant_id
 		WHERE
-			p.product_id IN (%s)
+			p.category_id IN (%s)
 		AND
 			p.deleted_at IS NULL
-		AND
-			v.deleted_at IS NULL
 		ORDER BY 
-			product_id ASC,
-			variant_id ASC
+			p.product_id ASC,
+			v.variant_id ASC
 	`
-	mainQuery := fmt.Sprintf(query, strings.Join(params, ", "))
+	mainQuery := fmt.Sprintf(query, variantJoin, strings.Join(params, ", "))
 	categoryIDInterface := make([]interface{}, len(categoryIDs))
 	for i, v := range categoryIDs {
 		categoryIDInterface[i] = v
@@ -239,116 +459,402 @@ func (repo *Repo) GetProductVariantForEachCategory(categoryIDs []int) ([]Product
 	if err != nil {
 		return nil, err
 	}
-	var productVariantList []ProductVariantRow
+	defer rows.Close()
+	var productList []Product
+	productIndex := make(map[int]int) //to map product ID and its position in productList
 	for rows.Next() {
 		var prodVar ProductVariantRow
 		err = rows.Scan(&prodVar.ProductID, &prodVar.ProductName, &description, &imageURL,
 			&prodVar.CategoryID, &variantID, &variantName, &maxRetailPrice, &discountPrice,
-			&size, &color)
+			&currency, &size, &color)
 		if err != nil {
 			return nil, err
 		}
-		if description.Valid {
-			prodVar.Description = description.String
-		}
-		if imageURL.Valid {
-			prodVar.ImageURL = imageURL.String
-		}
-		if variantID.Valid {
-			prodVar.VariantID = int(variantID.Int32)
-			if variantName.Valid {
-				prodVar.VariantName = variantName.String
+		index, ok := productIndex[prodVar.ProductID]
+		if !ok {
+			product := Product{
+				ProductID:  prodVar.ProductID,
+				Name:       prodVar.ProductName,
+				CategoryID: prodVar.CategoryID,
 			}
-			if maxRetailPrice.Valid {
-				prodVar.MRP = float64(maxRetailPrice.Float64)
+			if description.Valid {
+				product.Description = description.String
 			}
-			if discountPrice.Valid {
-				prodVar.DiscountPrice = float64(discountPrice.Float64)
-			}
-			if size.Valid {
-				prodVar.Size = size.String
-			}
-			if color.Valid {
-				prodVar.Color = color.String
+			if imageURL.Valid {
+				product.ImageURL = imageURL.String
 			}
+			productList = append(productList, product)
+			index = len(productList) - 1
+			productIndex[prodVar.ProductID] = index
 		}
-		log.Println("Heree", prodVar)
-		productVariantList = append(productVariantList, prodVar)
-	}
-	var productList []Product
-	if len(productVariantList) > 0 {
-		for _, row := range productVariantList {
-			var product Product
-			var variant Variant
-			var variants []Variant
-			var isAppended bool
-			for k, v := range productList {
-				if v.ProductID == row.ProductID {
-					variant.VariantID = row.VariantID
-					variant.Name = row.VariantName
-					variant.MRP = row.MRP
-					variant.DiscountPrice = row.DiscountPrice
-					variant.Size = row.Size
-					variant.Color = row.Color
-					v.Variants = append(v.Variants, variant)
-					productList[k] = v
-					isAppended = true
-					break
-				}
-			}
-			if isAppended {
-				continue
+		if !variantID.Valid {
+			continue
+		}
+		variant := Variant{
+			VariantID: int(variantID.Int32),
+		}
+		if variantName.Valid {
+			variant.Name = variantName.String
+		}
+		if maxRetailPrice.Valid {
+			variant.MRP = utils.Money{
+				Amount:   maxRetailPrice.Int64,
+				Currency: currency.String,
 			}
-			if row.VariantID != 0 {
-				variant.VariantID = row.VariantID
-				variant.Name = row.VariantName
-				variant.MRP = row.MRP
-				variant.DiscountPrice = row.DiscountPrice
-				variant.Size = row.Size
-				variant.Color = row.Color
-				variants = append(variants, variant)
+		}
+		if discountPrice.Valid {
+			variant.DiscountPrice = &utils.Money{
+				Amount:   discountPrice.Int64,
+				Currency: currency.String,
 			}
-			product.ProductID = row.ProductID
-			product.Name = row.ProductName
-			product.Description = row.Description
-			product.ImageURL = row.ImageURL
-			product.CategoryID = row.CategoryID
-			product.Variants = variants
-			log.Println("Heree======", product)
-			productList = append(productList, product)
 		}
+		if size.Valid {
+			variant.Size = size.String
+		}
+		if color.Valid {
+			variant.Color = color.String
+		}
+		productList[index].Variants = append(productList[index].Variants, variant)
 	}
-	return productList, nil
+	return productList, rows.Err()
 }
 
-// GetCategories to get the categories from DB
-func (repo *Repo) GetCategories() (*[]Category, error) {
-	var categories []Category
-	var parentID sql.NullInt32
+// GetCategoryTree to get the given category (or all main categories when categoryID is 0) and its
+// sub categories up to the given depth, along with their ancestors and levels, in a single query
+func (repo *Repo) GetCategoryTree(categoryID int, depth int) ([]CategoryRelationship, error) {
 	query := `
-		SELECT 
-			category_id, name, parent_category_id
-		FROM 
-			tbl_category
-		WHERE
-			deleted_at IS NULL
+		WITH RECURSIVE ancestry AS (
+			SELECT
+				category_id, parent_category_id, 0 AS distance, ARRAY[category_id] AS path
+			FROM
+				tbl_category
+			WHERE
+				category_id = $1
+			AND
+				deleted_at IS NULL
+			UNION ALL
+			SELECT
+				c.category_id, c.parent_category_id, a.distance + 1, a.path || c.category_id
+			FROM
+				tbl_category c
+			JOIN
+				ancestry a
+			ON
+				c.category_id = a.parent_category_id
+			WHERE
+				c.deleted_at IS NULL
+			AND
+				NOT c.category_id = ANY(a.path)
+		),
+		tree AS (
+			SELECT
+				c.category_id, c.name, c.parent_category_id,
+				ARRAY(SELECT category_id FROM ancestry WHERE distance > 0 ORDER BY distance DESC) AS ancestors,
+				0 AS depth
+			FROM
+				tbl_category c
+			WHERE
+				c.deleted_at IS NULL
+			AND
+				(($1 = 0 AND c.parent_category_id IS NULL) OR c.category_id = $1)
+			UNION ALL
+			SELECT
+				c.category_id, c.name, c.parent_category_id, t.ancestors || t.category_id, t.depth + 1
+			FROM
+				tbl_category c
+			JOIN
+				tree t
+			ON
+				c.parent_category_id = t.category_id
+			WHERE
+				c.deleted_at IS NULL
+			AND
+				NOT c.category_id = ANY(t.ancestors || t.category_id)
+			AND
+				($2 < 0 OR t.depth < $2)
+		)
+		SELECT
+			category_id, name, parent_category_id, ancestors
+		FROM
+			tree
 		ORDER BY
+			depth ASC,
 			category_id ASC
 	`
-	rows, err := repo.DB.Query(query)
+	rows, err := repo.DB.Query(query, categoryID, depth)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	var relationships []CategoryRelationship
 	for rows.Next() {
-		var category Category
-		err := rows.Scan(&category.ID, &category.Name, &parentID)
+		var relationship CategoryRelationship
+		var parentID sql.NullInt32
+		var ancestors pq.Int64Array
+		err := rows.Scan(&relationship.CategoryID, &relationship.Name, &parentID, &ancestors)
 		if err != nil {
 			return nil, err
 		}
+		for _, ancestor := range ancestors {
+			relationship.Ancestor = append(relationship.Ancestor, int(ancestor))
+		}
+		relationship.Level = len(relationship.Ancestor)
 		if parentID.Valid {
-			category.ParentID = int(parentID.Int32)
+			relationship.FirstParent = int(parentID.Int32)
 		}
-		categories = append(categories, category)
+		relationships = append(relationships, relationship)
 	}
-	return &categories, nil
+	return relationships, rows.Err()
+}
+
+//ancestryQuery selects the live category $1 and its live ancestors with their distance from it
+const ancestryQuery = `
+		WITH RECURSIVE ancestry AS (
+			SELECT
+				category_id, name, parent_category_id, 0 AS distance, ARRAY[category_id] AS path
+			FROM
+				tbl_category
+			WHERE
+				category_id = $1
+			AND
+				deleted_at IS NULL
+			UNION ALL
+			SELECT
+				c.category_id, c.name, c.parent_category_id, a.distance + 1, a.path || c.category_id
+			FROM
+				tbl_category c
+			JOIN
+				ancestry a
+			ON
+				c.category_id = a.parent_category_id
+			WHERE
+				c.deleted_at IS NULL
+			AND
+				NOT c.category_id = ANY(a.path)
+		)
+`
+
+// GetCategoryPath to get the given category and its ancestors ordered from the root down
+func (repo *Repo) GetCategoryPath(categoryID int) ([]Breadcrumb, error) {
+	query := ancestryQuery + `
+		SELECT
+			category_id, name
+		FROM
+			ancestry
+		ORDER BY
+			distance DESC
+	`
+	rows, err := repo.DB.Query(query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var path []Breadcrumb
+	for rows.Next() {
+		var breadcrumb Breadcrumb
+		err := rows.Scan(&breadcrumb.ID, &breadcrumb.Name)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, breadcrumb)
+	}
+	return path, rows.Err()
+}
+
+// ListAttributes to list the attributes defined by the given category and its ancestors, the
+// attributes of the ancestors closest to the root come first
+func (repo *Repo) ListAttributes(categoryID int) ([]Attribute, error) {
+	query := `
+		WITH RECURSIVE ancestry AS (
+			SELECT
+				category_id, parent_category_id, 0 AS distance, ARRAY[category_id] AS path
+			FROM
+				tbl_category
+			WHERE
+				category_id = $1
+			AND
+				deleted_at IS NULL
+			UNION ALL
+			SELECT
+				c.category_id, c.parent_category_id, a.distance + 1, a.path || c.category_id
+			FROM
+				tbl_category c
+			JOIN
+				ancestry a
+			ON
+				c.category_id = a.parent_category_id
+			WHERE
+				c.deleted_at IS NULL
+			AND
+				NOT c.category_id = ANY(a.path)
+		)
+		SELECT
+			ca.attribute_id, ca.category_id, ca.name, ca.type, ca.unit, ca.enum_values, ca.is_required
+		FROM
+			tbl_category_attribute ca
+		JOIN
+			ancestry a
+		ON
+			a.category_id = ca.category_id
+		ORDER BY
+			a.distance DESC,
+			ca.attribute_id ASC
+	`
+	rows, err := repo.DB.Query(query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	attributes := []Attribute{}
+	for rows.Next() {
+		var attribute Attribute
+		var unit sql.NullString
+		var values pq.StringArray
+		err := rows.Scan(&attribute.ID, &attribute.CategoryID, &attribute.Name, &attribute.Type, &unit,
+			&values, &attribute.Required)
+		if err != nil {
+			return nil, err
+		}
+		attribute.Unit = unit.String
+		attribute.Values = values
+		attributes = append(attributes, attribute)
+	}
+	return attributes, rows.Err()
+}
+
+// IsAttributeNameUsed to check if the given category, its ancestors or its descendants define an
+// attribute with the name
+func (repo *Repo) IsAttributeNameUsed(categoryID int, name string) (bool, error) {
+	var count int
+	query := `
+		WITH RECURSIVE ancestry AS (
+			SELECT
+				category_id, parent_category_id, ARRAY[category_id] AS path
+			FROM
+				tbl_category
+			WHERE
+				category_id = $1
+			UNION ALL
+			SELECT
+				c.category_id, c.parent_category_id, a.path || c.category_id
+			FROM
+				tbl_category c
+			JOIN
+				ancestry a
+			ON
+				c.category_id = a.parent_category_id
+			WHERE
+				NOT c.category_id = ANY(a.path)
+		), subtree AS (
+			SELECT
+				category_id
+			FROM
+				tbl_category
+			WHERE
+				category_id = $1
+			UNION
+			SELECT
+				c.category_id
+			FROM
+				tbl_category c
+			JOIN
+				subtree s
+			ON
+				c.parent_category_id = s.category_id
+		)
+		SELECT
+			count(*)
+		FROM
+			tbl_category_attribute
+		WHERE
+			lower(name) = lower($2)
+		AND (
+			category_id IN (SELECT category_id FROM ancestry)
+			OR
+			category_id IN (SELECT category_id FROM subtree)
+		)
+	`
+	err := repo.DB.QueryRow(query, categoryID, name).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateAttribute to define an attribute on the category
+func (repo *Repo) CreateAttribute(request *AttributeRequest) (*Attribute, error) {
+	attribute := Attribute{
+		CategoryID: request.CategoryID,
+		Name:       request.Name,
+		Type:       request.Type,
+		Unit:       request.Unit,
+		Values:     request.Values,
+		Required:   request.Required,
+	}
+	var values interface{}
+	if len(request.Values) > 0 {
+		values = pq.Array(request.Values)
+	}
+	query := `
+		INSERT INTO
+			tbl_category_attribute (category_id, name, type, unit, enum_values, is_required, created_at, updated_at)
+		VALUES
+			($1, $2, $3, NULLIF($4, ''), $5, $6, NOW(), NOW())
+		RETURNING
+			attribute_id
+	`
+	err := repo.DB.QueryRow(query, request.CategoryID, request.Name, request.Type, request.Unit, values,
+		request.Required).Scan(&attribute.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &attribute, nil
+}
+
+// IsAttributeInUse to check if any product has a value for the attribute
+func (repo *Repo) IsAttributeInUse(attributeID int) (bool, error) {
+	var count int
+	query := `
+		SELECT
+			count(*)
+		FROM
+			tbl_product_attribute pa
+		JOIN
+			tbl_product p
+		ON
+			p.product_id = pa.product_id
+		WHERE
+			pa.attribute_id = $1
+		AND
+			p.deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, attributeID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteAttribute to delete an attribute defined by the category
+func (repo *Repo) DeleteAttribute(categoryID int, attributeID int) error {
+	query := `
+		DELETE FROM
+			tbl_category_attribute
+		WHERE
+			attribute_id = $1
+		AND
+			category_id = $2
+	`
+	result, err := repo.DB.Exec(query, attributeID, categoryID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New(utils.AttributeNotExist)
+	}
+	return nil
 }
@@ -11,8 +11,15 @@ type RepoInterface interface {
 	DeleteCategory(int) error
 	IsSubCategoryExist(int) (bool, error)
 	IsProductExist(int) (bool, error)
-	GetProductVariantForEachCategory([]int) ([]Product, error)
-	GetCategories() (*[]Category, error)
+	GetSubtreeProductIDs(int) ([]int, error)
+	GetProductVariantForEachCategory([]int, bool) ([]Product, error)
+	GetCategoryTree(int, int) ([]CategoryRelationship, error)
+	GetCategoryPath(int) ([]Breadcrumb, error)
+	ListAttributes(int) ([]Attribute, error)
+	IsAttributeNameUsed(int, string) (bool, error)
+	CreateAttribute(*AttributeRequest) (*Attribute, error)
+	IsAttributeInUse(int) (bool, error)
+	DeleteAttribute(int, int) error
 }
 
 //NewRepo returns repository interface
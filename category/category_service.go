@@ -2,36 +2,52 @@ package category
 
 import (
 	"database/sql"
+	"ecommerce/search"
 	"ecommerce/utils"
 	"errors"
 	"log"
+	"strings"
 )
 
-//Visited to mark the category visited while looping through categories
-var Visited = make(map[int]bool)
-
 //ServiceInterface is category service interface
 type ServiceInterface interface {
 	CreateCategory(*CreateRequest) (*CreateResponse, error)
 	UpdateCategory(*UpdateRequest) error
 	DeleteCategory(int) error
-	ListCategory() (*[]CategoryList, error)
+	ListCategory(*ListRequest) (*[]CategoryList, error)
+	GetCategoryPath(int) ([]Breadcrumb, error)
+	GetCategoryPaths(int) (map[int][]Breadcrumb, error)
+	ListAttributes(int) ([]Attribute, error)
+	CreateAttribute(*AttributeRequest) (*Attribute, error)
+	DeleteAttribute(int, int) error
 }
 
 //Service struct for service functionalities
 type Service struct {
-	repo RepoInterface
+	repo  RepoInterface
+	index search.SearchIndex
 }
 
-//NewService :
-func NewService(db *sql.DB) ServiceInterface {
+//NewService : the index is refreshed with the products of the categories changed, it is nil for the
+//services that only read categories
+func NewService(db *sql.DB, index search.SearchIndex) ServiceInterface {
 	return &Service{
-		repo: NewRepo(db),
+		repo:  NewRepo(db),
+		index: index,
 	}
 }
 
 //CreateCategory service function to create category
 func (service *Service) CreateCategory(req *CreateRequest) (*CreateResponse, error) {
+	if req.ParentID != 0 {
+		parentExists, err := service.repo.IsCategoryIDExists(req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if !parentExists {
+			return nil, errors.New(utils.ParentCategoryNotExist)
+		}
+	}
 	categoryExists, err := service.repo.CheckCategoryNameExists(req.Name)
 	if err != nil {
 		return nil, err
@@ -55,9 +71,15 @@ func (service *Service) UpdateCategory(request *UpdateRequest) error {
 	if !isExist {
 		return errors.New(utils.InvalidCategoryID)
 	}
-	if len(request.Name) <= 0 && request.ParentID == 0 {
+	if len(request.Name) <= 0 && request.ParentID == nil {
 		return errors.New(utils.NothingToUpdateInCategory)
 	}
+	if request.ParentID != nil && *request.ParentID != DefaultCategory {
+		err = service.validateParent(request.CategoryID, *request.ParentID)
+		if err != nil {
+			return err
+		}
+	}
 	categoryExists, err := service.repo.CheckCategoryNameExists(request.Name)
 	if err != nil {
 		return err
@@ -65,7 +87,51 @@ func (service *Service) UpdateCategory(request *UpdateRequest) error {
 	if categoryExists {
 		return errors.New(utils.CategoryExistsError)
 	}
-	return service.repo.UpdateCategory(request)
+	err = service.repo.UpdateCategory(request)
+	if err != nil {
+		return err
+	}
+	service.reindex(request.CategoryID)
+	return nil
+}
+
+//reindex to refresh the products of the category and its sub categories in the search index, as their
+//category path is indexed. The change is already saved so a failure is only logged and the next rebuild
+//of the index picks it up
+func (service *Service) reindex(categoryID int) {
+	if service.index == nil {
+		return
+	}
+	productIDs, err := service.repo.GetSubtreeProductIDs(categoryID)
+	if err != nil {
+		log.Println("Error : error refreshing the search index(reindex) - category", categoryID, "-", err.Error())
+		return
+	}
+	err = service.index.IndexProducts(productIDs...)
+	if err != nil {
+		log.Println("Error : error refreshing the search index(reindex) - category", categoryID, "-", err.Error())
+	}
+}
+
+//validateParent to check that the new parent exists and is not the category itself or one of its sub categories,
+//the update checks it again with the rows locked
+func (service *Service) validateParent(categoryID int, parentID int) error {
+	if categoryID == parentID {
+		return errors.New(utils.CategoryCycleError)
+	}
+	parent, err := service.repo.GetCategoryTree(parentID, 0)
+	if err != nil {
+		return err
+	}
+	if len(parent) == 0 {
+		return errors.New(utils.ParentCategoryNotExist)
+	}
+	for _, ancestorID := range parent[0].Ancestor {
+		if ancestorID == categoryID {
+			return errors.New(utils.CategoryCycleError)
+		}
+	}
+	return nil
 }
 
 //DeleteCategory to delete a category
@@ -91,71 +157,152 @@ func (service Service) DeleteCategory(categoryID int) error {
 	if isExistProduct {
 		return errors.New(utils.ProductExistCategoryError)
 	}
-	return service.repo.DeleteCategory(categoryID)
+	err = service.repo.DeleteCategory(categoryID)
+	if err != nil {
+		return err
+	}
+	service.reindex(categoryID)
+	return nil
 }
 
-//ListCategory lists all the categories and its child elements
-func (service *Service) ListCategory() (*[]CategoryList, error) {
-	Visited = map[int]bool{}
-	//Get the details of existing categories
-	categoryDetails, err := service.repo.GetCategories()
-	log.Println(categoryDetails)
+//ListCategory lists the categories and their child elements, optionally scoped to a sub tree
+func (service *Service) ListCategory(request *ListRequest) (*[]CategoryList, error) {
+	//Get the categories of the requested tree along with their ancestry
+	relationships, err := service.repo.GetCategoryTree(request.CategoryID, request.Depth)
 	if err != nil {
 		return nil, err
 	}
-	var categoryIDs []int                   //to store all the category IDs
-	var mainCategories []int                //to store main categories which doesn't have a child
-	categoryChildMap := make(map[int][]int) //to store category and its child relation
-	categoryNameMap := make(map[int]string) //to map category and its name
-	//generating categoryChildMap, categoryNameMap, categoryIDs and mainCategories
-	for _, v := range *categoryDetails {
-		if v.ParentID == 0 {
-			mainCategories = append(mainCategories, v.ID)
-			categoryNameMap[v.ID] = v.Name
-			categoryIDs = append(categoryIDs, v.ID)
-			continue
+	if len(relationships) == 0 && request.CategoryID != DefaultCategory {
+		return nil, errors.New(utils.CategoryNOTExistsError)
+	}
+	tree := NewTreeBuilder(relationships)
+	if request.Include != IncludeNone && len(relationships) > 0 {
+		//To get the products (and variants) of the categories in the tree
+		products, err := service.repo.GetProductVariantForEachCategory(tree.CategoryIDs(), request.Include == IncludeVariants)
+		if err != nil {
+			return nil, err
 		}
-		categoryChildMap[v.ParentID] = append(categoryChildMap[v.ParentID], v.ID)
-		categoryNameMap[v.ID] = v.Name
-		categoryIDs = append(categoryIDs, v.ID)
+		tree.AddProducts(products)
 	}
-	//To get all the product and its variants
-	productVariantForCategory, err := service.repo.GetProductVariantForEachCategory(categoryIDs)
+	categoryList := tree.Build()
+	return &categoryList, nil
+}
+
+//GetCategoryPath to get the breadcrumb of the given category from the root category
+func (service *Service) GetCategoryPath(categoryID int) ([]Breadcrumb, error) {
+	path, err := service.repo.GetCategoryPath(categoryID)
 	if err != nil {
 		return nil, err
 	}
-	log.Println(productVariantForCategory)
-	//generating category and its associated products mapping
-	categoryProductMap := make(map[int][]Product)
-	for _, v := range productVariantForCategory {
-		categoryProductMap[v.CategoryID] = append(categoryProductMap[v.CategoryID], v)
+	if len(path) == 0 {
+		return nil, errors.New(utils.CategoryNOTExistsError)
 	}
-	log.Println(categoryProductMap)
-	var categoryList []CategoryList //Final result category listing
-	for _, categoryID := range mainCategories {
+	return path, nil
+}
 
-		catList := formatCategory(categoryID, categoryProductMap, categoryChildMap, categoryNameMap)
-		if catList.CategoryID != 0 {
-			categoryList = append(categoryList, catList)
+//GetCategoryPaths to get the path from the root category of the given category and of each of its sub
+//categories, of every category when the category is 0. The tree is loaded in one query and walked with
+//a TreeBuilder, for the features that need the path of many categories at once
+func (service *Service) GetCategoryPaths(categoryID int) (map[int][]Breadcrumb, error) {
+	var prefix []Breadcrumb
+	if categoryID != DefaultCategory {
+		path, err := service.GetCategoryPath(categoryID)
+		if err != nil {
+			return nil, err
 		}
+		prefix = path[:len(path)-1]
 	}
-	return &categoryList, nil
+	relationships, err := service.repo.GetCategoryTree(categoryID, UnlimitedDepth)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[int][]Breadcrumb)
+	NewTreeBuilder(relationships).Walk(func(categoryID int, path []Breadcrumb) {
+		fullPath := make([]Breadcrumb, 0, len(prefix)+len(path))
+		fullPath = append(fullPath, prefix...)
+		paths[categoryID] = append(fullPath, path...)
+	})
+	return paths, nil
 }
 
-//To format the categories and its sub categories
-func formatCategory(categoryID int, categoryProductMap map[int][]Product, categoryChildMap map[int][]int, categoryNameMap map[int]string) CategoryList {
-	//if already visited, return null for the category
-	if Visited[categoryID] {
-		return CategoryList{}
-	}
-	Visited[categoryID] = true
-	var catList CategoryList
-	catList.Products = categoryProductMap[categoryID]
-	catList.Name = categoryNameMap[categoryID]
-	catList.CategoryID = categoryID
-	for _, childID := range categoryChildMap[categoryID] {
-		cList := formatCategory(childID, categoryProductMap, categoryChildMap, categoryNameMap)
-		catList.Categories = append(catList.Categories, cList)
-	}
-	return catList
+//JoinPath returns the names of the path joined with the PathSeparator
+func JoinPath(path []Breadcrumb) string {
+	names := make([]string, len(path))
+	for i, breadcrumb := range path {
+		names[i] = breadcrumb.Name
+	}
+	return strings.Join(names, PathSeparator)
+}
+
+//ListAttributes to list the attributes of the products of the category, including the attributes
+//inherited from its parent categories
+func (service *Service) ListAttributes(categoryID int) ([]Attribute, error) {
+	isExist, err := service.repo.IsCategoryIDExists(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if !isExist {
+		return nil, errors.New(utils.CategoryNOTExistsError)
+	}
+	return service.repo.ListAttributes(categoryID)
+}
+
+//CreateAttribute to define an attribute for the products of the category and its sub categories
+func (service *Service) CreateAttribute(request *AttributeRequest) (*Attribute, error) {
+	isExist, err := service.repo.IsCategoryIDExists(request.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if !isExist {
+		return nil, errors.New(utils.CategoryNOTExistsError)
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	request.Unit = strings.TrimSpace(request.Unit)
+	var values []string
+	seen := make(map[string]bool)
+	for _, value := range request.Values {
+		value = strings.TrimSpace(value)
+		if value == utils.EmptyString || seen[value] {
+			continue
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+	request.Values = values
+	if request.Name == utils.EmptyString || (request.Type == utils.AttributeEnum) != (len(request.Values) > 0) {
+		return nil, errors.New(utils.InvalidAttributeError)
+	}
+	isUsed, err := service.repo.IsAttributeNameUsed(request.CategoryID, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	if isUsed {
+		return nil, errors.New(utils.AttributeExistsError)
+	}
+	return service.repo.CreateAttribute(request)
+}
+
+//DeleteAttribute to delete an attribute defined by the category when no product has a value for it
+func (service *Service) DeleteAttribute(categoryID int, attributeID int) error {
+	attributes, err := service.ListAttributes(categoryID)
+	if err != nil {
+		return err
+	}
+	isOwn := false
+	for _, attribute := range attributes {
+		if attribute.ID == attributeID && attribute.CategoryID == categoryID {
+			isOwn = true
+		}
+	}
+	if !isOwn {
+		return errors.New(utils.AttributeNotExist)
+	}
+	inUse, err := service.repo.IsAttributeInUse(attributeID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New(utils.AttributeInUseError)
+	}
+	return service.repo.DeleteAttribute(categoryID, attributeID)
 }
@@ -0,0 +1,109 @@
+package category
+
+//TreeBuilder builds the nested category listing from category relationships. A TreeBuilder
+//holds request scoped state, so create one per request instead of sharing it between goroutines
+type TreeBuilder struct {
+	roots      []int             //to store the top level categories of the tree
+	categories []int             //to store all the category IDs in tree order
+	childMap   map[int][]int     //to store category and its child relation
+	nameMap    map[int]string    //to map category and its name
+	productMap map[int][]Product //to map category and its products
+	visited    map[int]bool      //to mark the category visited while looping through categories
+}
+
+//NewTreeBuilder returns a tree builder for the given relationships, the relationships with the
+//lowest level become the top level categories of the tree
+func NewTreeBuilder(relationships []CategoryRelationship) *TreeBuilder {
+	tb := &TreeBuilder{
+		childMap:   make(map[int][]int),
+		nameMap:    make(map[int]string),
+		productMap: make(map[int][]Product),
+	}
+	if len(relationships) == 0 {
+		return tb
+	}
+	rootLevel := relationships[0].Level
+	for _, v := range relationships {
+		if v.Level < rootLevel {
+			rootLevel = v.Level
+		}
+	}
+	for _, v := range relationships {
+		tb.nameMap[v.CategoryID] = v.Name
+		tb.categories = append(tb.categories, v.CategoryID)
+		if v.Level == rootLevel {
+			tb.roots = append(tb.roots, v.CategoryID)
+			continue
+		}
+		tb.childMap[v.FirstParent] = append(tb.childMap[v.FirstParent], v.CategoryID)
+	}
+	return tb
+}
+
+//CategoryIDs returns the IDs of all the categories in the tree
+func (tb *TreeBuilder) CategoryIDs() []int {
+	return tb.categories
+}
+
+//AddProducts attaches the products to their categories
+func (tb *TreeBuilder) AddProducts(products []Product) {
+	for _, v := range products {
+		tb.productMap[v.CategoryID] = append(tb.productMap[v.CategoryID], v)
+	}
+}
+
+//Build returns the nested category listing
+func (tb *TreeBuilder) Build() []CategoryList {
+	tb.visited = make(map[int]bool)
+	categoryList := []CategoryList{}
+	for _, categoryID := range tb.roots {
+		catList := tb.formatCategory(categoryID)
+		if catList.CategoryID != 0 {
+			categoryList = append(categoryList, catList)
+		}
+	}
+	return categoryList
+}
+
+//Walk calls fn for every category of the tree in depth first order along with its path from the
+//top level category of the tree, the path slice is only valid during the call
+func (tb *TreeBuilder) Walk(fn func(categoryID int, path []Breadcrumb)) {
+	tb.visited = make(map[int]bool)
+	var path []Breadcrumb
+	var walk func(categoryID int)
+	walk = func(categoryID int) {
+		if tb.visited[categoryID] {
+			return
+		}
+		tb.visited[categoryID] = true
+		path = append(path, Breadcrumb{ID: categoryID, Name: tb.nameMap[categoryID]})
+		fn(categoryID, path)
+		for _, childID := range tb.childMap[categoryID] {
+			walk(childID)
+		}
+		path = path[:len(path)-1]
+	}
+	for _, categoryID := range tb.roots {
+		walk(categoryID)
+	}
+}
+
+//To format the categories and its sub categories
+func (tb *TreeBuilder) formatCategory(categoryID int) CategoryList {
+	//if already visited, return null for the category
+	if tb.visited[categoryID] {
+		return CategoryList{}
+	}
+	tb.visited[categoryID] = true
+	var catList CategoryList
+	catList.Products = tb.productMap[categoryID]
+	catList.Name = tb.nameMap[categoryID]
+	catList.CategoryID = categoryID
+	for _, childID := range tb.childMap[categoryID] {
+		cList := tb.formatCategory(childID)
+		if cList.CategoryID != 0 {
+			catList.Categories = append(catList.Categories, cList)
+		}
+	}
+	return catList
+}
@@ -0,0 +1,339 @@
+package category
+
+import (
+	"ecommerce/utils"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi"
+)
+
+//testCategories is the category tree of the tests, Men > Shoes > Sneakers, Boots and Men > Shirts
+//with Women > Dresses
+var testCategories = []Category{
+	{ID: 1, Name: "Men"},
+	{ID: 2, Name: "Shoes", ParentID: 1},
+	{ID: 3, Name: "Shirts", ParentID: 1},
+	{ID: 4, Name: "Sneakers", ParentID: 2},
+	{ID: 5, Name: "Boots", ParentID: 2},
+	{ID: 6, Name: "Women"},
+	{ID: 7, Name: "Dresses", ParentID: 6},
+}
+
+//testProducts are the products of the test categories
+var testProducts = []Product{
+	{ProductID: 101, Name: "Runner", CategoryID: 4, Variants: []Variant{{VariantID: 1001, Name: "42"}}},
+	{ProductID: 102, Name: "Chelsea", CategoryID: 5},
+	{ProductID: 103, Name: "Maxi", CategoryID: 7, Variants: []Variant{{VariantID: 1003, Name: "S"}}},
+}
+
+//stubRepo serves the test categories and products as GetCategoryTree and GetProductVariantForEachCategory
+//of the Postgres repository do
+type stubRepo struct {
+	RepoInterface
+	categories []Category
+	products   []Product
+}
+
+func (repo *stubRepo) GetCategoryTree(categoryID int, depth int) ([]CategoryRelationship, error) {
+	byID := make(map[int]Category)
+	for _, c := range repo.categories {
+		byID[c.ID] = c
+	}
+	var level []CategoryRelationship
+	for _, c := range repo.categories {
+		if (categoryID == DefaultCategory && c.ParentID == 0) || c.ID == categoryID {
+			var ancestors []int
+			for parentID := c.ParentID; parentID != 0; parentID = byID[parentID].ParentID {
+				ancestors = append([]int{parentID}, ancestors...)
+			}
+			level = append(level, CategoryRelationship{CategoryID: c.ID, Name: c.Name, Ancestor: ancestors,
+				Level: len(ancestors), FirstParent: c.ParentID})
+		}
+	}
+	var relationships []CategoryRelationship
+	for d := 0; len(level) > 0; d++ {
+		sort.Slice(level, func(i, j int) bool { return level[i].CategoryID < level[j].CategoryID })
+		relationships = append(relationships, level...)
+		if depth >= 0 && d >= depth {
+			break
+		}
+		var next []CategoryRelationship
+		for _, parent := range level {
+			for _, c := range repo.categories {
+				if c.ParentID == parent.CategoryID {
+					ancestors := append(append([]int{}, parent.Ancestor...), parent.CategoryID)
+					next = append(next, CategoryRelationship{CategoryID: c.ID, Name: c.Name, Ancestor: ancestors,
+						Level: len(ancestors), FirstParent: c.ParentID})
+				}
+			}
+		}
+		level = next
+	}
+	return relationships, nil
+}
+
+func (repo *stubRepo) GetProductVariantForEachCategory(categoryIDs []int, includeVariants bool) ([]Product, error) {
+	inTree := make(map[int]bool)
+	for _, id := range categoryIDs {
+		inTree[id] = true
+	}
+	var products []Product
+	for _, p := range repo.products {
+		if !inTree[p.CategoryID] {
+			continue
+		}
+		if !includeVariants {
+			p.Variants = nil
+		}
+		products = append(products, p)
+	}
+	return products, nil
+}
+
+func (repo *stubRepo) GetCategoryPath(categoryID int) ([]Breadcrumb, error) {
+	byID := make(map[int]Category)
+	for _, c := range repo.categories {
+		byID[c.ID] = c
+	}
+	var path []Breadcrumb
+	for id := categoryID; id != 0; id = byID[id].ParentID {
+		c, ok := byID[id]
+		if !ok {
+			return nil, nil
+		}
+		path = append([]Breadcrumb{{ID: c.ID, Name: c.Name}}, path...)
+	}
+	return path, nil
+}
+
+func newStubRepo() *stubRepo {
+	return &stubRepo{categories: testCategories, products: testProducts}
+}
+
+func relationshipsOf(t *testing.T, categoryID int, depth int) []CategoryRelationship {
+	relationships, err := newStubRepo().GetCategoryTree(categoryID, depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return relationships
+}
+
+func TestTreeBuilderBuild(t *testing.T) {
+	sneakers := CategoryList{CategoryID: 4, Name: "Sneakers"}
+	boots := CategoryList{CategoryID: 5, Name: "Boots"}
+	shoes := CategoryList{CategoryID: 2, Name: "Shoes", Categories: []CategoryList{sneakers, boots}}
+	shirts := CategoryList{CategoryID: 3, Name: "Shirts"}
+	men := CategoryList{CategoryID: 1, Name: "Men", Categories: []CategoryList{shoes, shirts}}
+	women := CategoryList{CategoryID: 6, Name: "Women", Categories: []CategoryList{{CategoryID: 7, Name: "Dresses"}}}
+	withProducts := func(list CategoryList, products ...Product) CategoryList {
+		list.Products = products
+		return list
+	}
+	tests := []struct {
+		name          string
+		relationships []CategoryRelationship
+		products      []Product
+		want          []CategoryList
+	}{
+		{
+			name: "empty tree",
+			want: []CategoryList{},
+		},
+		{
+			name:          "all categories",
+			relationships: relationshipsOf(t, DefaultCategory, UnlimitedDepth),
+			want:          []CategoryList{men, women},
+		},
+		{
+			name:          "main categories only",
+			relationships: relationshipsOf(t, DefaultCategory, 0),
+			want:          []CategoryList{{CategoryID: 1, Name: "Men"}, {CategoryID: 6, Name: "Women"}},
+		},
+		{
+			name:          "sub tree",
+			relationships: relationshipsOf(t, 2, UnlimitedDepth),
+			want:          []CategoryList{shoes},
+		},
+		{
+			name:          "sub tree with depth",
+			relationships: relationshipsOf(t, 1, 1),
+			want: []CategoryList{{CategoryID: 1, Name: "Men", Categories: []CategoryList{
+				{CategoryID: 2, Name: "Shoes"}, shirts,
+			}}},
+		},
+		{
+			name:          "leaf category with products",
+			relationships: relationshipsOf(t, 4, UnlimitedDepth),
+			products:      testProducts[:1],
+			want:          []CategoryList{withProducts(sneakers, testProducts[0])},
+		},
+		{
+			name: "category listed under two parents is built once",
+			relationships: append(relationshipsOf(t, 2, UnlimitedDepth),
+				CategoryRelationship{CategoryID: 4, Name: "Sneakers", Ancestor: []int{2, 5}, Level: 2, FirstParent: 5}),
+			want: []CategoryList{shoes},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree := NewTreeBuilder(test.relationships)
+			tree.AddProducts(test.products)
+			got := tree.Build()
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Build() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTreeBuilderWalk(t *testing.T) {
+	tests := []struct {
+		name          string
+		relationships []CategoryRelationship
+		want          map[int]string
+	}{
+		{
+			name: "empty tree",
+			want: map[int]string{},
+		},
+		{
+			name:          "all categories",
+			relationships: relationshipsOf(t, DefaultCategory, UnlimitedDepth),
+			want: map[int]string{
+				1: "Men", 2: "Men > Shoes", 3: "Men > Shirts", 4: "Men > Shoes > Sneakers", 5: "Men > Shoes > Boots",
+				6: "Women", 7: "Women > Dresses",
+			},
+		},
+		{
+			name:          "sub tree paths start at its top category",
+			relationships: relationshipsOf(t, 2, UnlimitedDepth),
+			want:          map[int]string{2: "Shoes", 4: "Shoes > Sneakers", 5: "Shoes > Boots"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := make(map[int]string)
+			NewTreeBuilder(test.relationships).Walk(func(categoryID int, path []Breadcrumb) {
+				got[categoryID] = JoinPath(path)
+			})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Walk() paths = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetCategoryPaths(t *testing.T) {
+	service := &Service{repo: newStubRepo()}
+	tests := []struct {
+		categoryID int
+		want       map[int]string
+	}{
+		{
+			categoryID: DefaultCategory,
+			want: map[int]string{
+				1: "Men", 2: "Men > Shoes", 3: "Men > Shirts", 4: "Men > Shoes > Sneakers", 5: "Men > Shoes > Boots",
+				6: "Women", 7: "Women > Dresses",
+			},
+		},
+		{
+			categoryID: 2,
+			want:       map[int]string{2: "Men > Shoes", 4: "Men > Shoes > Sneakers", 5: "Men > Shoes > Boots"},
+		},
+		{
+			categoryID: 7,
+			want:       map[int]string{7: "Women > Dresses"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.categoryID), func(t *testing.T) {
+			paths, err := service.GetCategoryPaths(test.categoryID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[int]string)
+			for categoryID, path := range paths {
+				got[categoryID] = JoinPath(path)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetCategoryPaths(%d) = %v, want %v", test.categoryID, got, test.want)
+			}
+		})
+	}
+	_, err := service.GetCategoryPaths(99)
+	if err == nil || err.Error() != utils.CategoryNOTExistsError {
+		t.Errorf("GetCategoryPaths(99) error = %v, want %s", err, utils.CategoryNOTExistsError)
+	}
+}
+
+//TestListCategoryConcurrent fires parallel GET /category requests for different trees, run it with -race.
+//Each response must match the response to the same request made alone
+func TestListCategoryConcurrent(t *testing.T) {
+	handler := &Handler{cs: &Service{repo: newStubRepo()}}
+	router := chi.NewRouter()
+	router.Get("/category", handler.ListCategory)
+	router.Get("/category/{category_id}", handler.ListCategory)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	paths := []string{
+		"/category",
+		"/category?include=none",
+		"/category?include=products&depth=1",
+		"/category/1",
+		"/category/2?depth=0",
+		"/category/6",
+		"/category/4?include=variants",
+	}
+	get := func(path string) (string, error) {
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			return "", err
+		}
+		defer response.Body.Close()
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return "", err
+		}
+		if response.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("GET %s status %d: %s", path, response.StatusCode, body)
+		}
+		return string(body), nil
+	}
+	want := make(map[string]string)
+	for _, path := range paths {
+		body, err := get(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[path] = body
+	}
+	const requests = 200
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			body, err := get(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if body != want[path] {
+				errs <- fmt.Errorf("GET %s = %s, want %s", path, body, want[path])
+			}
+		}(paths[i%len(paths)])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
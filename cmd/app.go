@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"database/sql"
 	"ecommerce/router"
+	"ecommerce/search"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 //App struct
@@ -13,20 +19,48 @@ type App struct {
 }
 
 //NewApp returns new app struct
-func NewApp(db *sql.DB) *App {
+func NewApp(db *sql.DB, index search.SearchIndex) *App {
 	return &App{
-		Router: router.NewRouter(db),
+		Router: router.NewRouter(db, index),
 	}
 }
 
-//Serve to serve the server
+//ShutdownTimeout time the requests in flight are given to finish when the server shuts down
+const ShutdownTimeout = 30 * time.Second
+
+//Serve to serve the server until the process gets SIGINT or SIGTERM, the server then stops accepting
+//connections and Serve returns once the requests in flight are done
 func (a *App) Serve() {
 	port, err := getPort()
 	if err != nil {
 		log.Println("Error : Can't find the server address")
 		panic(err)
 	}
-	r := a.Router.Setup()
+	server := &http.Server{
+		Addr:    "localhost:" + port,
+		Handler: a.Router.Setup(),
+	}
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		signal.Stop(signals)
+		log.Println("App : Server is shutting down")
+		ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		err := server.Shutdown(ctx)
+		if err != nil {
+			log.Println("Error : error shutting down the server(Serve) -", err.Error())
+		}
+	}()
 	log.Println("App : Server is listening")
-	http.ListenAndServe("localhost:"+port, r)
+	err = server.ListenAndServe()
+	if err != http.ErrServerClosed {
+		log.Println("Error : Server stopped(Serve) -", err.Error())
+		return
+	}
+	<-shutdown
+	log.Println("App : Server stopped")
 }
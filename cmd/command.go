@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"database/sql"
+	"ecommerce/feed"
+	"ecommerce/search"
+	"errors"
+	"log"
+	"os"
+)
+
+//commands the app runs instead of serving when named in the arguments, given the arguments after the name
+var commands = map[string]func(*sql.DB, []string) error{
+	"reindex": reindex,
+	"feed":    writeFeed,
+}
+
+//runCommand to run the named command on the database
+func runCommand(name string, args []string, db *sql.DB) error {
+	command, ok := commands[name]
+	if !ok {
+		return errors.New("Unknown command " + name)
+	}
+	return command(db, args)
+}
+
+//reindex to rebuild the configured search index from the database. The memory index lives in the
+//serving process and is built on its start, a separate process cannot rebuild it
+func reindex(db *sql.DB, args []string) error {
+	if search.IndexType() == search.MemoryIndexType {
+		return errors.New("The memory search index is rebuilt when the app starts, restart the app to rebuild it")
+	}
+	index, err := search.NewIndex(db)
+	if err != nil {
+		return err
+	}
+	count, err := index.Rebuild()
+	if err != nil {
+		return err
+	}
+	log.Println("App : Search index rebuilt, count =", count)
+	return nil
+}
+
+//writeFeed to write the feed of the format to the file, e.g. feed google feed.xml
+func writeFeed(db *sql.DB, args []string) error {
+	if len(args) != 2 {
+		return errors.New("Usage : feed <google|facebook> <file>")
+	}
+	file, err := os.Create(args[1])
+	if err != nil {
+		return err
+	}
+	err = feed.NewService(db).WriteFeed(args[0], file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	log.Println("App : Feed written, file =", args[1])
+	return nil
+}
@@ -1,6 +1,13 @@
 package cmd
 
-import "log"
+import (
+	"ecommerce/inventory"
+	"ecommerce/jobs"
+	"ecommerce/pricing"
+	"ecommerce/search"
+	"log"
+	"os"
+)
 
 //Begin is the beginning of the app
 func Begin() {
@@ -15,7 +22,38 @@ func Begin() {
 		panic(err)
 	} else {
 		log.Println("App : Database connected successfully")
-		app := NewApp(db)
+		if len(os.Args) > 1 {
+			err = runCommand(os.Args[1], os.Args[2:], db)
+			if err != nil {
+				log.Println("Error in command", os.Args[1], err.Error())
+				panic(err)
+			}
+			return
+		}
+		index, err := search.NewIndex(db)
+		if err != nil {
+			log.Println("Error in search index", err.Error())
+			panic(err)
+		}
+		if search.IndexType() == search.MemoryIndexType {
+			count, err := index.Rebuild()
+			if err != nil {
+				log.Println("Error in search index", err.Error())
+				panic(err)
+			}
+			log.Println("App : Search index loaded, count =", count)
+		}
+		sweeper := inventory.NewSweeper(db, inventory.SweepInterval)
+		sweeper.Start()
+		defer sweeper.Stop()
+		scheduler := pricing.NewScheduler(db, pricing.ApplyInterval)
+		scheduler.Start()
+		defer scheduler.Stop()
+		//the deferred stops run once Serve returns on shutdown, the pool stops first and releases its jobs
+		pool := jobs.NewPool(db, index, jobs.WorkerCount, jobs.PollInterval)
+		pool.Start()
+		defer pool.Stop()
+		app := NewApp(db, index)
 		app.Serve()
 	}
 }
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"database/sql"
+	"ecommerce/feed"
+	"ecommerce/pricing"
+	"ecommerce/search"
 	"ecommerce/utils"
 	"errors"
+	"net/url"
 	"os"
 )
 
@@ -32,5 +36,19 @@ func checkEnv() error {
 	if !ok {
 		return errors.New("PORT environment variable missing")
 	}
+	if !utils.IsValidCurrency(pricing.BaseCurrency()) {
+		return errors.New("BaseCurrency environment variable is not a supported currency code")
+	}
+	indexType := search.IndexType()
+	if indexType != search.PostgresIndexType && indexType != search.MemoryIndexType {
+		return errors.New(utils.InvalidSearchIndexError)
+	}
+	storefront := feed.StorefrontURL()
+	if storefront != utils.EmptyString {
+		parsed, err := url.Parse(storefront)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == utils.EmptyString {
+			return errors.New(utils.StorefrontURLError)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,27 @@
+package export
+
+const (
+	//FormatCSV export format of comma separated values, one row per variant
+	FormatCSV = "csv"
+	//FormatJSONL export format of JSON Lines, one line per product with its variants
+	FormatJSONL = "jsonl"
+	//FormatXLSX export format of an Excel workbook, one row per variant
+	FormatXLSX = "xlsx"
+	//ExportFileName name of the downloaded file without the extension
+	ExportFileName = "catalogue"
+	//ProductIDColumn column of the product ID, ignored by the import
+	ProductIDColumn = "product_id"
+	//VariantIDColumn column of the variant ID, ignored by the import
+	VariantIDColumn = "variant_id"
+	//CategoryIDColumn column of the category ID, ignored by the import
+	CategoryIDColumn = "category_id"
+	//SheetName name of the worksheet of the XLSX export
+	SheetName = "Catalogue"
+)
+
+//contentTypes maps the export formats to the content type of the response
+var contentTypes = map[string]string{
+	FormatCSV:   "text/csv",
+	FormatJSONL: "application/x-ndjson",
+	FormatXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
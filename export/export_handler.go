@@ -0,0 +1,67 @@
+package export
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//HandlerInterface for export management
+type HandlerInterface interface {
+	Export(http.ResponseWriter, *http.Request)
+}
+
+//Handler struct for export management
+type Handler struct {
+	cs ServiceInterface
+}
+
+//NewHTTPHandler to handle export requests
+func NewHTTPHandler(db *sql.DB) HandlerInterface {
+	return &Handler{
+		cs: NewService(db),
+	}
+}
+
+//Export to handle the catalogue export request, the file is streamed as it is written so an error
+//after the start can only end the download early
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /export GET API")
+	request := ExportRequest{
+		Format: strings.ToLower(r.URL.Query().Get("format")),
+	}
+	if request.Format == utils.EmptyString {
+		request.Format = FormatCSV
+	}
+	if value := r.URL.Query().Get("category_id"); value != utils.EmptyString {
+		categoryID, err := strconv.Atoi(value)
+		if err != nil || categoryID < 0 {
+			log.Println("Error :", utils.InvalidParameterError, " (Export)")
+			utils.Fail(w, 400, utils.InvalidParameterError+" category_id")
+			return
+		}
+		request.CategoryID = categoryID
+	}
+	err := h.cs.CheckRequest(&request)
+	if err != nil {
+		if err.Error() == utils.InvalidExportFormatError || err.Error() == utils.CategoryNOTExistsError {
+			log.Println("Error : Validation error(Export) -", err.Error())
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		log.Println("Error : Export error(Export) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentTypes[request.Format])
+	w.Header().Set("Content-Disposition", "attachment; filename="+ExportFileName+"."+request.Format)
+	err = h.cs.Export(&request, w)
+	if err != nil {
+		log.Println("Error : Export error(Export) -", err.Error())
+		return
+	}
+	log.Println("App : Catalogue exported successfully, format =", request.Format)
+}
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"ecommerce/utils"
+	"time"
+)
+
+//ExportRequest to represent the catalogue export request of a category subtree, the whole catalogue
+//when the category is 0
+type ExportRequest struct {
+	Format     string
+	CategoryID int
+}
+
+//Row to represent a variant with its product, a product without variants has a row with a variant ID
+//of 0. The options and attributes are keyed by their name
+type Row struct {
+	CategoryID         int
+	CategoryPath       string
+	ProductID          int
+	ProductName        string
+	Description        string
+	ImageURL           string
+	Attributes         map[string]string
+	VariantID          int
+	VariantName        string
+	SKU                string
+	Barcode            string
+	MRP                utils.Money
+	DiscountPrice      *utils.Money
+	DiscountValidFrom  *time.Time
+	DiscountValidUntil *time.Time
+	Options            map[string]string
+}
+
+//Product to represent a product line of the JSON Lines export
+type Product struct {
+	ID           int               `json:"product_id"`
+	Name         string            `json:"product_name"`
+	Description  string            `json:"description,omitempty"`
+	ImageURL     string            `json:"image_url,omitempty"`
+	CategoryID   int               `json:"category_id"`
+	CategoryPath string            `json:"category_path"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Variants     []Variant         `json:"variants"`
+}
+
+//Variant to represent a variant of a product line of the JSON Lines export
+type Variant struct {
+	ID                 int               `json:"variant_id"`
+	Name               string            `json:"name,omitempty"`
+	SKU                string            `json:"sku,omitempty"`
+	Barcode            string            `json:"barcode,omitempty"`
+	MRP                utils.Money       `json:"max_retail_price"`
+	DiscountPrice      *utils.Money      `json:"discount_price,omitempty"`
+	DiscountValidFrom  *time.Time        `json:"discount_valid_from,omitempty"`
+	DiscountValidUntil *time.Time        `json:"discount_valid_until,omitempty"`
+	Options            map[string]string `json:"options,omitempty"`
+}
+
+//cell to represent a value of a table row, numbers are written as numbers in the XLSX export
+type cell struct {
+	value  string
+	number bool
+}
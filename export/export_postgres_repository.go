@@ -0,0 +1,212 @@
+package export
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+//Repo is the DB repo struct
+type Repo struct {
+	DB *sql.DB
+}
+
+// scopeQuery selects the products of the categories $1
+const scopeQuery = `
+		WITH products AS (
+			SELECT
+				p.product_id, p.category_id
+			FROM
+				tbl_product p
+			WHERE
+				p.deleted_at IS NULL
+			AND
+				p.category_id = ANY($1)
+		)
+`
+
+//CheckCategoryExists function to check if the given category exist in our DB
+func (repo *Repo) CheckCategoryExists(categoryID int) (bool, error) {
+	var count int
+	query := `
+		SELECT
+			count(*)
+		FROM
+			tbl_category
+		WHERE
+			category_id = $1
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, categoryID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//GetOptionNames to get the option names of the products of the categories, the names differing in case
+//only are given once
+func (repo *Repo) GetOptionNames(categoryIDs []int) ([]string, error) {
+	query := scopeQuery + `
+		SELECT
+			MIN(o.name)
+		FROM
+			tbl_product_option o
+		JOIN
+			products p
+		ON
+			p.product_id = o.product_id
+		GROUP BY
+			lower(o.name)
+		ORDER BY
+			lower(o.name) ASC
+	`
+	return repo.getNames(query, categoryIDs)
+}
+
+//GetAttributeNames to get the names of the attributes the products of the categories have values for,
+//the names differing in case only are given once
+func (repo *Repo) GetAttributeNames(categoryIDs []int) ([]string, error) {
+	query := scopeQuery + `
+		SELECT
+			MIN(a.name)
+		FROM
+			tbl_product_attribute pa
+		JOIN
+			tbl_category_attribute a
+		ON
+			a.attribute_id = pa.attribute_id
+		JOIN
+			products p
+		ON
+			p.product_id = pa.product_id
+		GROUP BY
+			lower(a.name)
+		ORDER BY
+			lower(a.name) ASC
+	`
+	return repo.getNames(query, categoryIDs)
+}
+
+func (repo *Repo) getNames(query string, categoryIDs []int) ([]string, error) {
+	rows, err := repo.DB.Query(query, pq.Array(categoryIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		err := rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+//StreamRows to call the function for each variant of the products of the categories, ordered by product
+//and variant. The rows are scanned one at a time as they are read from the database
+func (repo *Repo) StreamRows(categoryIDs []int, fn func(*Row) error) error {
+	query := scopeQuery + `
+		SELECT
+			p.category_id, pr.product_id, pr.name, COALESCE(pr.description, ''), COALESCE(pr.image_url, ''),
+			ARRAY(
+				SELECT a.name FROM tbl_product_attribute pa
+				JOIN tbl_category_attribute a ON a.attribute_id = pa.attribute_id
+				WHERE pa.product_id = pr.product_id ORDER BY pa.attribute_id
+			),
+			ARRAY(
+				SELECT pa.value_text FROM tbl_product_attribute pa
+				WHERE pa.product_id = pr.product_id ORDER BY pa.attribute_id
+			),
+			v.variant_id, v.name, v.sku, v.barcode, v.max_retail_price, v.currency, v.discount_price,
+			v.discount_valid_from, v.discount_valid_until,
+			ARRAY(
+				SELECT o.name FROM tbl_variant_option_value vo
+				JOIN tbl_product_option o ON o.option_id = vo.option_id
+				WHERE vo.variant_id = v.variant_id ORDER BY vo.option_id
+			),
+			ARRAY(
+				SELECT ov.value FROM tbl_variant_option_value vo
+				JOIN tbl_product_option_value ov ON ov.value_id = vo.value_id
+				WHERE vo.variant_id = v.variant_id ORDER BY vo.option_id
+			)
+		FROM
+			products p
+		JOIN
+			tbl_product pr
+		ON
+			pr.product_id = p.product_id
+		LEFT JOIN
+			tbl_variant v
+		ON
+			v.product_id = pr.product_id
+		AND
+			v.deleted_at IS NULL
+		ORDER BY
+			pr.product_id ASC,
+			v.variant_id ASC
+	`
+	rows, err := repo.DB.Query(query, pq.Array(categoryIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row Row
+		var attributeNames, attributeValues, optionNames, optionValues pq.StringArray
+		var variantID, mrp, discountPrice sql.NullInt64
+		var variantName, sku, barcode, currency sql.NullString
+		var discountValidFrom, discountValidUntil sql.NullTime
+		err := rows.Scan(&row.CategoryID, &row.ProductID, &row.ProductName, &row.Description,
+			&row.ImageURL, &attributeNames, &attributeValues, &variantID, &variantName, &sku, &barcode, &mrp,
+			&currency, &discountPrice, &discountValidFrom, &discountValidUntil, &optionNames, &optionValues)
+		if err != nil {
+			return err
+		}
+		row.Attributes = zipNames(attributeNames, attributeValues)
+		if variantID.Valid {
+			row.VariantID = int(variantID.Int64)
+			row.VariantName = variantName.String
+			row.SKU = sku.String
+			row.Barcode = barcode.String
+			row.MRP.Amount = mrp.Int64
+			row.MRP.Currency = strings.TrimSpace(currency.String)
+			if discountPrice.Valid {
+				row.DiscountPrice = &utils.Money{Amount: discountPrice.Int64, Currency: row.MRP.Currency}
+			}
+			row.DiscountValidFrom = getTime(discountValidFrom)
+			row.DiscountValidUntil = getTime(discountValidUntil)
+			row.Options = zipNames(optionNames, optionValues)
+		}
+		err = fn(&row)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func zipNames(names []string, values []string) map[string]string {
+	zipped := make(map[string]string)
+	for i, name := range names {
+		if i < len(values) {
+			zipped[name] = values[i]
+		}
+	}
+	return zipped
+}
+
+func getTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	at := value.Time
+	return &at
+}
@@ -0,0 +1,18 @@
+package export
+
+import "database/sql"
+
+//RepoInterface for DB operations
+type RepoInterface interface {
+	CheckCategoryExists(int) (bool, error)
+	GetOptionNames([]int) ([]string, error)
+	GetAttributeNames([]int) ([]string, error)
+	StreamRows([]int, func(*Row) error) error
+}
+
+//NewRepo returns repository interface
+func NewRepo(db *sql.DB) RepoInterface {
+	return &Repo{
+		DB: db,
+	}
+}
@@ -0,0 +1,236 @@
+package export
+
+import (
+	"database/sql"
+	"ecommerce/category"
+	"ecommerce/importer"
+	"ecommerce/utils"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ServiceInterface is export service interface
+type ServiceInterface interface {
+	CheckRequest(*ExportRequest) error
+	Export(*ExportRequest, io.Writer) error
+}
+
+//Service struct for service functionalities
+type Service struct {
+	repo       RepoInterface
+	categories category.ServiceInterface
+}
+
+//NewService :
+func NewService(db *sql.DB) ServiceInterface {
+	return &Service{
+		repo:       NewRepo(db),
+		categories: category.NewService(db, nil),
+	}
+}
+
+//CheckRequest to validate the export request before anything is written
+func (service *Service) CheckRequest(request *ExportRequest) error {
+	if _, ok := contentTypes[request.Format]; !ok {
+		return errors.New(utils.InvalidExportFormatError)
+	}
+	if request.CategoryID == 0 {
+		return nil
+	}
+	categoryExists, err := service.repo.CheckCategoryExists(request.CategoryID)
+	if err != nil {
+		return err
+	}
+	if !categoryExists {
+		return errors.New(utils.CategoryNOTExistsError)
+	}
+	return nil
+}
+
+//Export to write the products of the request as they are read from the database, a line per product
+//in JSON Lines and otherwise a row per variant with the columns of the import, so an export can be
+//imported back
+func (service *Service) Export(request *ExportRequest, w io.Writer) error {
+	categoryIDs, paths, err := service.categoryPaths(request.CategoryID)
+	if err != nil {
+		return err
+	}
+	if request.Format == FormatJSONL {
+		return service.exportJSONL(categoryIDs, paths, w)
+	}
+	optionNames, err := service.repo.GetOptionNames(categoryIDs)
+	if err != nil {
+		return err
+	}
+	attributeNames, err := service.repo.GetAttributeNames(categoryIDs)
+	if err != nil {
+		return err
+	}
+	writer, err := newTableWriter(request.Format, w)
+	if err != nil {
+		return err
+	}
+	err = writer.WriteRow(headerCells(optionNames, attributeNames))
+	if err != nil {
+		return err
+	}
+	err = service.repo.StreamRows(categoryIDs, func(row *Row) error {
+		row.CategoryPath = paths[row.CategoryID]
+		return writer.WriteRow(rowCells(row, optionNames, attributeNames))
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+//categoryPaths to get the categories of the export, the category and its sub categories or every
+//category when it is 0, with their path keyed by category
+func (service *Service) categoryPaths(categoryID int) ([]int, map[int]string, error) {
+	categoryPaths, err := service.categories.GetCategoryPaths(categoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	categoryIDs := make([]int, 0, len(categoryPaths))
+	paths := make(map[int]string, len(categoryPaths))
+	for id, path := range categoryPaths {
+		categoryIDs = append(categoryIDs, id)
+		paths[id] = category.JoinPath(path)
+	}
+	return categoryIDs, paths, nil
+}
+
+//exportJSONL to write a line per product, the rows of a product are consecutive so only the current
+//product is held in memory
+func (service *Service) exportJSONL(categoryIDs []int, paths map[int]string, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	var product *Product
+	err := service.repo.StreamRows(categoryIDs, func(row *Row) error {
+		if product != nil && product.ID != row.ProductID {
+			err := encoder.Encode(product)
+			if err != nil {
+				return err
+			}
+			product = nil
+		}
+		if product == nil {
+			product = &Product{
+				ID:           row.ProductID,
+				Name:         row.ProductName,
+				Description:  row.Description,
+				ImageURL:     row.ImageURL,
+				CategoryID:   row.CategoryID,
+				CategoryPath: paths[row.CategoryID],
+				Attributes:   row.Attributes,
+				Variants:     []Variant{},
+			}
+		}
+		if row.VariantID != 0 {
+			product.Variants = append(product.Variants, Variant{
+				ID:                 row.VariantID,
+				Name:               row.VariantName,
+				SKU:                row.SKU,
+				Barcode:            row.Barcode,
+				MRP:                row.MRP,
+				DiscountPrice:      row.DiscountPrice,
+				DiscountValidFrom:  row.DiscountValidFrom,
+				DiscountValidUntil: row.DiscountValidUntil,
+				Options:            row.Options,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if product != nil {
+		return encoder.Encode(product)
+	}
+	return nil
+}
+
+//headerCells to get the header row, the IDs followed by the import columns
+func headerCells(optionNames []string, attributeNames []string) []cell {
+	names := []string{
+		ProductIDColumn, VariantIDColumn, CategoryIDColumn, importer.CategoryColumn, importer.ProductNameColumn,
+		importer.DescriptionColumn, importer.ImageURLColumn, importer.VariantNameColumn, importer.SKUColumn,
+		importer.BarcodeColumn, importer.MRPColumn, importer.DiscountPriceColumn, importer.CurrencyColumn,
+		importer.DiscountValidFromColumn, importer.DiscountValidUntilColumn,
+	}
+	for _, name := range optionNames {
+		names = append(names, importer.OptionPrefix+name)
+	}
+	for _, name := range attributeNames {
+		names = append(names, importer.AttributePrefix+name)
+	}
+	cells := make([]cell, len(names))
+	for i, name := range names {
+		cells[i] = cell{value: name}
+	}
+	return cells
+}
+
+//rowCells to get the cells of a row in the order of the header, the prices in minor units
+func rowCells(row *Row, optionNames []string, attributeNames []string) []cell {
+	cells := []cell{
+		{value: strconv.Itoa(row.ProductID), number: true},
+		{value: formatID(row.VariantID), number: true},
+		{value: strconv.Itoa(row.CategoryID), number: true},
+		{value: row.CategoryPath},
+		{value: row.ProductName},
+		{value: row.Description},
+		{value: row.ImageURL},
+		{value: row.VariantName},
+		{value: row.SKU},
+		{value: row.Barcode},
+	}
+	if row.VariantID != 0 {
+		cells = append(cells, cell{value: strconv.FormatInt(row.MRP.Amount, 10), number: true})
+	} else {
+		cells = append(cells, cell{})
+	}
+	if row.DiscountPrice != nil {
+		cells = append(cells, cell{value: strconv.FormatInt(row.DiscountPrice.Amount, 10), number: true})
+	} else {
+		cells = append(cells, cell{})
+	}
+	cells = append(cells,
+		cell{value: row.MRP.Currency},
+		cell{value: formatTime(row.DiscountValidFrom)},
+		cell{value: formatTime(row.DiscountValidUntil)},
+	)
+	cells = append(cells, namedCells(row.Options, optionNames)...)
+	return append(cells, namedCells(row.Attributes, attributeNames)...)
+}
+
+//namedCells to get the values of the names in order, the names are matched case insensitively
+func namedCells(values map[string]string, names []string) []cell {
+	lowered := make(map[string]string)
+	for name, value := range values {
+		lowered[strings.ToLower(name)] = value
+	}
+	cells := make([]cell, len(names))
+	for i, name := range names {
+		cells[i] = cell{value: lowered[strings.ToLower(name)]}
+	}
+	return cells
+}
+
+func formatID(id int) string {
+	if id == 0 {
+		return utils.EmptyString
+	}
+	return strconv.Itoa(id)
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return utils.EmptyString
+	}
+	return value.Format(time.RFC3339)
+}
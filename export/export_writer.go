@@ -0,0 +1,149 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+//tableWriter writes the rows of a table export as they come
+type tableWriter interface {
+	WriteRow([]cell) error
+	Close() error
+}
+
+//newTableWriter returns the table writer of the format
+func newTableWriter(format string, w io.Writer) (tableWriter, error) {
+	if format == FormatXLSX {
+		return newXLSXWriter(w)
+	}
+	return &csvWriter{writer: csv.NewWriter(w)}, nil
+}
+
+//csvWriter writes the rows as comma separated values
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+//WriteRow to write the values of the row
+func (c *csvWriter) WriteRow(cells []cell) error {
+	record := make([]string, len(cells))
+	for i, value := range cells {
+		record[i] = value.value
+	}
+	return c.writer.Write(record)
+}
+
+//Close to flush the buffered rows
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+//xlsxParts static parts of the XLSX package, the worksheet is streamed after them
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + SheetName + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+//xlsxWriter writes the rows into the single worksheet of an XLSX package, the zip entries are
+//streamed so the workbook is never held in memory
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	rows    int
+}
+
+//newXLSXWriter to write the static parts of the package and open the worksheet
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		entry, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.WriteString(entry, part.content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	entry, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(entry)
+	_, err = sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{
+		archive: archive,
+		sheet:   sheet,
+	}, nil
+}
+
+//WriteRow to write the row with its texts as inline strings
+func (x *xlsxWriter) WriteRow(cells []cell) error {
+	x.rows++
+	row := strconv.Itoa(x.rows)
+	x.sheet.WriteString(`<row r="` + row + `">`)
+	for i, value := range cells {
+		if value.value == "" {
+			continue
+		}
+		reference := columnName(i) + row
+		if value.number {
+			x.sheet.WriteString(`<c r="` + reference + `"><v>` + value.value + `</v></c>`)
+			continue
+		}
+		x.sheet.WriteString(`<c r="` + reference + `" t="inlineStr"><is><t xml:space="preserve">`)
+		err := xml.EscapeText(x.sheet, []byte(value.value))
+		if err != nil {
+			return err
+		}
+		x.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+//Close to end the worksheet and the package
+func (x *xlsxWriter) Close() error {
+	_, err := x.sheet.WriteString(`</sheetData></worksheet>`)
+	if err != nil {
+		return err
+	}
+	err = x.sheet.Flush()
+	if err != nil {
+		return err
+	}
+	return x.archive.Close()
+}
+
+//columnName to get the spreadsheet name of the zero based column, A to Z then AA
+func columnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,36 @@
+package feed
+
+const (
+	//FormatGoogle feed format of the Google Shopping RSS 2.0 XML
+	FormatGoogle = "google"
+	//FormatFacebook feed format of the Facebook catalog CSV
+	FormatFacebook = "facebook"
+	//StorefrontURLEnv environment variable holding the base URL of the storefront, e.g. https://shop.example.com
+	StorefrontURLEnv = "StorefrontURL"
+	//ProductLinkPath path of the storefront page of a variant, formatted with the product and variant IDs
+	ProductLinkPath = "/product/%d?variant=%d"
+	//FeedTitle title of the Google feed channel
+	FeedTitle = "Product catalogue"
+	//GoogleNamespace XML namespace of the Google product attributes
+	GoogleNamespace = "http://base.google.com/ns/1.0"
+	//InStock availability of a variant with available stock
+	InStock = "in stock"
+	//OutOfStock availability of a variant without available stock
+	OutOfStock = "out of stock"
+	//ConditionNew condition of the products of the catalogue
+	ConditionNew = "new"
+	//PriceBatchSize number of items whose prices are resolved at a time
+	PriceBatchSize = 100
+)
+
+//contentTypes maps the feed formats to the content type of the response
+var contentTypes = map[string]string{
+	FormatGoogle:   "application/xml; charset=utf-8",
+	FormatFacebook: "text/csv",
+}
+
+//facebookColumns columns of the Facebook catalog CSV
+var facebookColumns = []string{
+	"id", "item_group_id", "title", "description", "availability", "condition", "price", "sale_price",
+	"sale_price_effective_date", "link", "image_link", "brand", "product_type", "gtin", "mpn", "size", "color",
+}
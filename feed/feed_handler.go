@@ -0,0 +1,55 @@
+package feed
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"log"
+	"net/http"
+)
+
+//HandlerInterface for feed management
+type HandlerInterface interface {
+	GoogleFeed(http.ResponseWriter, *http.Request)
+	FacebookFeed(http.ResponseWriter, *http.Request)
+}
+
+//Handler struct for feed management
+type Handler struct {
+	cs ServiceInterface
+}
+
+//NewHTTPHandler to handle feed requests
+func NewHTTPHandler(db *sql.DB) HandlerInterface {
+	return &Handler{
+		cs: NewService(db),
+	}
+}
+
+//GoogleFeed to handle the Google Shopping feed request
+func (h *Handler) GoogleFeed(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /feeds/google.xml GET API")
+	h.writeFeed(w, FormatGoogle)
+}
+
+//FacebookFeed to handle the Facebook catalog feed request
+func (h *Handler) FacebookFeed(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /feeds/facebook.csv GET API")
+	h.writeFeed(w, FormatFacebook)
+}
+
+//writeFeed to stream the feed of the format, an error after the start can only end the response early
+func (h *Handler) writeFeed(w http.ResponseWriter, format string) {
+	err := h.cs.CheckFeed(format)
+	if err != nil {
+		log.Println("Error : Feed error(WriteFeed) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentTypes[format])
+	err = h.cs.WriteFeed(format, w)
+	if err != nil {
+		log.Println("Error : Feed error(WriteFeed) -", err.Error())
+		return
+	}
+	log.Println("App : Feed written successfully, format =", format)
+}
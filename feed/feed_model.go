@@ -0,0 +1,70 @@
+package feed
+
+import (
+	"ecommerce/utils"
+	"time"
+)
+
+//Item to represent a variant of the feed with its product
+type Item struct {
+	VariantID          int
+	ProductID          int
+	ProductName        string
+	VariantName        string
+	Description        string
+	ImageURL           string
+	CategoryID         int
+	CategoryPath       string
+	SKU                string
+	Barcode            string
+	MRP                utils.Money
+	DiscountPrice      *utils.Money
+	DiscountValidFrom  *time.Time
+	DiscountValidUntil *time.Time
+	AvailableQuantity  int
+	Brand              string
+	Size               string
+	Color              string
+}
+
+//Entry to represent the feed attributes of an item, shared by the feed formats
+type Entry struct {
+	ID                     string
+	ItemGroupID            string
+	Title                  string
+	Description            string
+	Availability           string
+	Condition              string
+	Price                  string
+	SalePrice              string
+	SalePriceEffectiveDate string
+	Link                   string
+	ImageLink              string
+	Brand                  string
+	ProductType            string
+	GTIN                   string
+	MPN                    string
+	Size                   string
+	Color                  string
+}
+
+//GoogleItem to represent an item element of the Google Shopping feed, it has the fields of Entry
+type GoogleItem struct {
+	ID                     string `xml:"g:id"`
+	ItemGroupID            string `xml:"g:item_group_id"`
+	Title                  string `xml:"g:title"`
+	Description            string `xml:"g:description"`
+	Availability           string `xml:"g:availability"`
+	Condition              string `xml:"g:condition"`
+	Price                  string `xml:"g:price"`
+	SalePrice              string `xml:"g:sale_price,omitempty"`
+	SalePriceEffectiveDate string `xml:"g:sale_price_effective_date,omitempty"`
+	Link                   string `xml:"g:link"`
+	ImageLink              string `xml:"g:image_link,omitempty"`
+	Brand                  string `xml:"g:brand,omitempty"`
+	ProductType            string `xml:"g:product_type,omitempty"`
+	GTIN                   string `xml:"g:gtin,omitempty"`
+	MPN                    string `xml:"g:mpn,omitempty"`
+	Size                   string `xml:"g:size,omitempty"`
+	Color                  string `xml:"g:color,omitempty"`
+}
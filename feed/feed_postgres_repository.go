@@ -0,0 +1,106 @@
+package feed
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"strings"
+	"time"
+)
+
+//Repo is the DB repo struct
+type Repo struct {
+	DB *sql.DB
+}
+
+//StreamItems to call the function for each live variant of the live products, ordered by product and
+//variant, with the available stock of the variant. The image is the first image of the variant, the
+//primary image of the product when the variant has none
+func (repo *Repo) StreamItems(fn func(*Item) error) error {
+	query := `
+		SELECT
+			v.variant_id, p.product_id, p.name, COALESCE(v.name, ''), COALESCE(p.description, ''),
+			COALESCE((
+				SELECT m.url FROM tbl_variant_media vm
+				JOIN tbl_product_media m ON m.media_id = vm.media_id
+				WHERE vm.variant_id = v.variant_id
+				ORDER BY m.position
+				LIMIT 1
+			), (
+				SELECT m.url FROM tbl_product_media m
+				WHERE m.product_id = p.product_id AND m.is_primary
+			), ''), p.category_id, COALESCE(v.sku, ''), COALESCE(v.barcode, ''),
+			v.max_retail_price, v.currency, v.discount_price, v.discount_valid_from, v.discount_valid_until,
+			COALESCE(s.available_quantity, 0),
+			COALESCE((
+				SELECT pa.value_text FROM tbl_product_attribute pa
+				JOIN tbl_category_attribute a ON a.attribute_id = pa.attribute_id
+				WHERE pa.product_id = p.product_id AND lower(a.name) = lower($1)
+				LIMIT 1
+			), ''),
+			COALESCE((
+				SELECT ov.value FROM tbl_variant_option_value vo
+				JOIN tbl_product_option o ON o.option_id = vo.option_id
+				JOIN tbl_product_option_value ov ON ov.value_id = vo.value_id
+				WHERE vo.variant_id = v.variant_id AND lower(o.name) = lower($2)
+			), ''),
+			COALESCE((
+				SELECT ov.value FROM tbl_variant_option_value vo
+				JOIN tbl_product_option o ON o.option_id = vo.option_id
+				JOIN tbl_product_option_value ov ON ov.value_id = vo.value_id
+				WHERE vo.variant_id = v.variant_id AND lower(o.name) = lower($3)
+			), '')
+		FROM
+			tbl_variant v
+		JOIN
+			tbl_product p
+		ON
+			p.product_id = v.product_id
+		LEFT JOIN
+			vw_variant_stock s
+		ON
+			s.variant_id = v.variant_id
+		WHERE
+			v.deleted_at IS NULL
+		AND
+			p.deleted_at IS NULL
+		ORDER BY
+			p.product_id ASC,
+			v.variant_id ASC
+	`
+	rows, err := repo.DB.Query(query, utils.BrandAttribute, utils.SizeOption, utils.ColorOption)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item Item
+		var currency string
+		var discountPrice sql.NullInt64
+		var discountValidFrom, discountValidUntil sql.NullTime
+		err := rows.Scan(&item.VariantID, &item.ProductID, &item.ProductName, &item.VariantName, &item.Description,
+			&item.ImageURL, &item.CategoryID, &item.SKU, &item.Barcode, &item.MRP.Amount, &currency, &discountPrice,
+			&discountValidFrom, &discountValidUntil, &item.AvailableQuantity, &item.Brand, &item.Size, &item.Color)
+		if err != nil {
+			return err
+		}
+		item.MRP.Currency = strings.TrimSpace(currency)
+		if discountPrice.Valid {
+			item.DiscountPrice = &utils.Money{Amount: discountPrice.Int64, Currency: item.MRP.Currency}
+		}
+		item.DiscountValidFrom = getTime(discountValidFrom)
+		item.DiscountValidUntil = getTime(discountValidUntil)
+		err = fn(&item)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func getTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	at := value.Time
+	return &at
+}
@@ -0,0 +1,15 @@
+package feed
+
+import "database/sql"
+
+//RepoInterface for DB operations
+type RepoInterface interface {
+	StreamItems(func(*Item) error) error
+}
+
+//NewRepo returns repository interface
+func NewRepo(db *sql.DB) RepoInterface {
+	return &Repo{
+		DB: db,
+	}
+}
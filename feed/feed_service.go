@@ -0,0 +1,223 @@
+package feed
+
+import (
+	"database/sql"
+	"ecommerce/category"
+	"ecommerce/pricing"
+	"ecommerce/utils"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ServiceInterface is feed service interface
+type ServiceInterface interface {
+	CheckFeed(string) error
+	WriteFeed(string, io.Writer) error
+}
+
+//Service struct for service functionalities
+type Service struct {
+	repo       RepoInterface
+	categories category.ServiceInterface
+	prices     pricing.ServiceInterface
+}
+
+//NewService :
+func NewService(db *sql.DB) ServiceInterface {
+	return &Service{
+		repo:       NewRepo(db),
+		categories: category.NewService(db, nil),
+		prices:     pricing.NewService(db),
+	}
+}
+
+//StorefrontURL returns the configured base URL of the storefront without the trailing slash
+func StorefrontURL() string {
+	return strings.TrimRight(os.Getenv(StorefrontURLEnv), "/")
+}
+
+//CheckFeed to check the feed can be written before anything is written
+func (service *Service) CheckFeed(format string) error {
+	if _, ok := contentTypes[format]; !ok {
+		return errors.New(utils.InvalidFeedFormatError)
+	}
+	if StorefrontURL() == utils.EmptyString {
+		return errors.New(utils.StorefrontURLError)
+	}
+	return nil
+}
+
+//WriteFeed to write the feed of the format as the variants are read from the database, each variant
+//is an item of the item group of its product
+func (service *Service) WriteFeed(format string, w io.Writer) error {
+	err := service.CheckFeed(format)
+	if err != nil {
+		return err
+	}
+	storefront := StorefrontURL()
+	if format == FormatGoogle {
+		return service.writeGoogle(storefront, w)
+	}
+	return service.writeFacebook(storefront, w)
+}
+
+//itemElement element of a Google feed item
+var itemElement = xml.StartElement{Name: xml.Name{Local: "item"}}
+
+//writeGoogle to write the RSS 2.0 feed with the product attributes in the g namespace
+func (service *Service) writeGoogle(storefront string, w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header+`<rss version="2.0" xmlns:g="`+GoogleNamespace+`"><channel>`)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	channel := [][2]string{{"title", FeedTitle}, {"link", storefront}, {"description", FeedTitle}}
+	for _, element := range channel {
+		err = encoder.EncodeElement(element[1], xml.StartElement{Name: xml.Name{Local: element[0]}})
+		if err != nil {
+			return err
+		}
+	}
+	err = service.streamEntries(storefront, func(entry Entry) error {
+		return encoder.EncodeElement(GoogleItem(entry), itemElement)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `</channel></rss>`)
+	return err
+}
+
+//writeFacebook to write the catalog CSV
+func (service *Service) writeFacebook(storefront string, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(facebookColumns)
+	if err != nil {
+		return err
+	}
+	err = service.streamEntries(storefront, func(entry Entry) error {
+		return writer.Write([]string{
+			entry.ID, entry.ItemGroupID, entry.Title, entry.Description, entry.Availability, entry.Condition,
+			entry.Price, entry.SalePrice, entry.SalePriceEffectiveDate, entry.Link, entry.ImageLink, entry.Brand,
+			entry.ProductType, entry.GTIN, entry.MPN, entry.Size, entry.Color,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+//streamEntries to call the function with the entry of each item as the items are read from the database.
+//The items get the category path of their product and their prices in effect now for all channels in
+//their own currency, resolved through the price lists and scheduled changes a batch at a time
+func (service *Service) streamEntries(storefront string, fn func(Entry) error) error {
+	categoryPaths, err := service.categories.GetCategoryPaths(category.DefaultCategory)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var batch []*Item
+	flush := func() error {
+		err := service.resolvePrices(batch, now)
+		if err != nil {
+			return err
+		}
+		for _, item := range batch {
+			err = fn(newEntry(item, storefront, now))
+			if err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	err = service.repo.StreamItems(func(item *Item) error {
+		item.CategoryPath = category.JoinPath(categoryPaths[item.CategoryID])
+		batch = append(batch, item)
+		if len(batch) < PriceBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+//resolvePrices to replace the item prices with their prices in effect at the given time
+func (service *Service) resolvePrices(items []*Item, asOf time.Time) error {
+	basePrices := make([]pricing.BasePrice, len(items))
+	for i, item := range items {
+		basePrices[i] = pricing.BasePrice{
+			VariantID:          item.VariantID,
+			MRP:                item.MRP,
+			DiscountPrice:      item.DiscountPrice,
+			DiscountValidFrom:  item.DiscountValidFrom,
+			DiscountValidUntil: item.DiscountValidUntil,
+		}
+	}
+	resolved, err := service.prices.ResolvePrices(basePrices, &pricing.ResolveRequest{
+		Channel: pricing.DefaultChannel,
+		AsOf:    asOf,
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		price := resolved[item.VariantID]
+		item.MRP = price.MRP
+		item.DiscountPrice = price.DiscountPrice
+		item.DiscountValidFrom = price.DiscountValidFrom
+		item.DiscountValidUntil = price.DiscountValidUntil
+	}
+	return nil
+}
+
+//newEntry to map a variant to its feed attributes. The sale price is the discount price when the
+//discount is in effect now, its effective date is given when both ends of the window are set
+func newEntry(item *Item, storefront string, now time.Time) Entry {
+	entry := Entry{
+		ID:           strconv.Itoa(item.VariantID),
+		ItemGroupID:  strconv.Itoa(item.ProductID),
+		Title:        item.ProductName,
+		Description:  item.Description,
+		Availability: OutOfStock,
+		Condition:    ConditionNew,
+		Price:        item.MRP.String(),
+		Link:         storefront + fmt.Sprintf(ProductLinkPath, item.ProductID, item.VariantID),
+		ImageLink:    item.ImageURL,
+		Brand:        item.Brand,
+		ProductType:  item.CategoryPath,
+		GTIN:         item.Barcode,
+		MPN:          item.SKU,
+		Size:         item.Size,
+		Color:        item.Color,
+	}
+	if item.VariantName != utils.EmptyString && item.VariantName != item.ProductName {
+		entry.Title = item.ProductName + " - " + item.VariantName
+	}
+	if entry.Description == utils.EmptyString {
+		entry.Description = entry.Title
+	}
+	if item.AvailableQuantity > 0 {
+		entry.Availability = InStock
+	}
+	if item.DiscountPrice != nil && utils.IsWithinWindow(now, item.DiscountValidFrom, item.DiscountValidUntil) {
+		entry.SalePrice = item.DiscountPrice.String()
+		if item.DiscountValidFrom != nil && item.DiscountValidUntil != nil {
+			entry.SalePriceEffectiveDate = item.DiscountValidFrom.Format(time.RFC3339) + "/" +
+				item.DiscountValidUntil.Format(time.RFC3339)
+		}
+	}
+	return entry
+}
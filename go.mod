@@ -4,6 +4,7 @@ go 1.15
 
 require (
 	github.com/go-chi/chi v4.1.2+incompatible
+	github.com/go-chi/cors v1.2.2
 	github.com/go-playground/universal-translator v0.17.0 // indirect
 	github.com/go-sql-driver/mysql v1.5.0 // indirect
 	github.com/leodido/go-urn v1.2.0 // indirect
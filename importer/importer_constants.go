@@ -0,0 +1,47 @@
+package importer
+
+const (
+	//ImportChunkSize number of CSV rows read and applied at a time
+	ImportChunkSize = 100
+	//MaxImportRows maximum number of data rows of an import file
+	MaxImportRows = 10000
+	//MaxImportSize maximum size in bytes of an import file
+	MaxImportSize = 32 << 20
+	//FileField multipart form field holding the import file
+	FileField = "file"
+	//CategoryPathSeparator separator of the category names of a category path, e.g. Men > Shoes > Sneakers
+	CategoryPathSeparator = ">"
+	//OptionPrefix prefix of the columns holding the value of a variant option, e.g. option.Size
+	OptionPrefix = "option."
+	//AttributePrefix prefix of the columns holding the value of a product attribute, e.g. attr.Brand
+	AttributePrefix = "attr."
+	//StatusCreated row status when the variant of the row was created
+	StatusCreated = "created"
+	//StatusUpdated row status when the variant of the row already existed and was updated
+	StatusUpdated = "updated"
+	//StatusFailed row status when the row could not be applied
+	StatusFailed = "failed"
+	//byteOrderMark prefix spreadsheet programs write at the start of a UTF-8 CSV file
+	byteOrderMark = "\ufeff"
+	//validationError message of the handlers when a request fails the struct validation
+	validationError = "Error validating request"
+)
+
+//Columns of the import file, the header names are case insensitive
+const (
+	CategoryColumn           = "category"
+	ProductNameColumn        = "product_name"
+	DescriptionColumn        = "description"
+	ImageURLColumn           = "image_url"
+	VariantNameColumn        = "variant_name"
+	SKUColumn                = "sku"
+	BarcodeColumn            = "barcode"
+	MRPColumn                = "max_retail_price"
+	DiscountPriceColumn      = "discount_price"
+	CurrencyColumn           = "currency"
+	DiscountValidFromColumn  = "discount_valid_from"
+	DiscountValidUntilColumn = "discount_valid_until"
+)
+
+//requiredColumns columns every import file must have
+var requiredColumns = []string{CategoryColumn, ProductNameColumn, MRPColumn}
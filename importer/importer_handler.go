@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"database/sql"
+	"ecommerce/search"
+	"ecommerce/utils"
+	"ecommerce/variant"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+//HandlerInterface for import management
+type HandlerInterface interface {
+	Import(http.ResponseWriter, *http.Request)
+}
+
+//Handler struct for import management
+type Handler struct {
+	cs ServiceInterface
+}
+
+//NewHTTPHandler to handle import requests
+func NewHTTPHandler(db *sql.DB, index search.SearchIndex) HandlerInterface {
+	return &Handler{
+		cs: NewService(db, index),
+	}
+}
+
+//IsRequestError to check if the error is caused by the import file
+func IsRequestError(err error) bool {
+	switch err.Error() {
+	case utils.InvalidImportFileError, utils.TooManyImportRowsError, utils.ImportFileReadError:
+		return true
+	}
+	return false
+}
+
+//Import to handle the CSV import request, the file is the request body or the file field of a
+//multipart form
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /import POST API")
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImportSize)
+	file, err := GetImportFile(r)
+	if err != nil {
+		log.Println("Error : Import file error(Import) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	defer file.Close()
+	request := ImportRequest{
+		File:      file,
+		ChangedBy: r.Header.Get(variant.ChangedByHeader),
+	}
+	response, err := h.cs.Import(&request)
+	if err != nil {
+		if IsRequestError(err) {
+			log.Println("Error : Validation error(Import) -", err.Error())
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		log.Println("Error : Import error(Import) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Import done, created =", response.Created, "updated =", response.Updated, "failed =", response.Failed)
+	utils.Send(w, 200, response)
+}
+
+//GetImportFile to get the import file from the multipart form or the request body
+func GetImportFile(r *http.Request) (io.ReadCloser, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile(FileField)
+		if err != nil {
+			return nil, err
+		}
+		return file, nil
+	}
+	return r.Body, nil
+}
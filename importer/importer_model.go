@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"ecommerce/variant"
+	"io"
+)
+
+//ImportRequest to represent the CSV import request, ChangedBy is recorded in the price history. The
+//first Skip rows are not applied, Progress is called with the reports of each applied chunk and an
+//error it returns stops the import
+type ImportRequest struct {
+	File      io.Reader
+	ChangedBy string
+	Skip      int
+	Progress  func([]RowReport) error
+}
+
+//ImportResponse to represent the report of an import with the outcome of every row
+type ImportResponse struct {
+	Total             int         `json:"total"`
+	Created           int         `json:"created"`
+	Updated           int         `json:"updated"`
+	Failed            int         `json:"failed"`
+	CategoriesCreated int         `json:"categories_created"`
+	ProductsCreated   int         `json:"products_created"`
+	Rows              []RowReport `json:"rows"`
+}
+
+//RowReport to represent the outcome of a row, rows are numbered from 1 after the header
+type RowReport struct {
+	Row       int    `json:"row"`
+	Status    string `json:"status"`
+	ProductID int    `json:"product_id,omitempty"`
+	VariantID int    `json:"variant_id,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+//ImportRow to represent a parsed row of the import file
+type ImportRow struct {
+	Row          int
+	CategoryPath []string
+	ProductName  string
+	Description  string
+	ImageURL     string
+	Attributes   map[string]string
+	Variant      variant.CreateRequest
+	Err          error //error parsing the row, the row is reported failed
+}
+
+//ProductRef to represent a product found by name
+type ProductRef struct {
+	ID         int
+	CategoryID int
+}
+
+//CategoryRef to represent a category found by name
+type CategoryRef struct {
+	ID       int
+	ParentID int
+}
+
+//importedProduct to represent the outcome of the upsert of a product, shared by its rows
+type importedProduct struct {
+	ID  int
+	Err error
+}
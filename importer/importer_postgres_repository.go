@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"database/sql"
+	"ecommerce/variant"
+
+	"github.com/lib/pq"
+)
+
+//Repo struct for postgres
+type Repo struct {
+	DB *sql.DB
+}
+
+//FindCategory to get the live category with the name, nil when there is none
+func (repo *Repo) FindCategory(name string) (*CategoryRef, error) {
+	var category CategoryRef
+	query := `
+		SELECT
+			category_id, COALESCE(parent_category_id, 0)
+		FROM
+			tbl_category
+		WHERE
+			name = $1
+		AND
+			deleted_at IS NULL
+		ORDER BY
+			category_id ASC
+		LIMIT 1
+	`
+	err := repo.DB.QueryRow(query, name).Scan(&category.ID, &category.ParentID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+//FindProduct to get the live product with the name, nil when there is none
+func (repo *Repo) FindProduct(name string) (*ProductRef, error) {
+	var product ProductRef
+	query := `
+		SELECT
+			product_id, category_id
+		FROM
+			tbl_product
+		WHERE
+			name = $1
+		AND
+			deleted_at IS NULL
+		ORDER BY
+			product_id ASC
+		LIMIT 1
+	`
+	err := repo.DB.QueryRow(query, name).Scan(&product.ID, &product.CategoryID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+//FindVariantBySKU to get the ID and product ID of the live variant with the SKU, 0 when there is none
+func (repo *Repo) FindVariantBySKU(sku string) (int, int, error) {
+	var variantID, productID int
+	query := `
+		SELECT
+			variant_id, product_id
+		FROM
+			tbl_variant
+		WHERE
+			sku = $1
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, sku).Scan(&variantID, &productID)
+	if err == sql.ErrNoRows {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return variantID, productID, nil
+}
+
+//FindVariantByOptions to get the live variant of the product having exactly the option values, 0 when
+//there is none. Option names are case insensitive
+func (repo *Repo) FindVariantByOptions(productID int, options []variant.OptionValue) (int, error) {
+	var names, values []string
+	for _, option := range options {
+		names = append(names, option.Name)
+		values = append(values, option.Value)
+	}
+	var variantID int
+	query := `
+		SELECT
+			v.variant_id
+		FROM
+			tbl_variant v
+		WHERE
+			v.product_id = $1
+		AND
+			v.deleted_at IS NULL
+		AND
+			(SELECT count(*) FROM tbl_variant_option_value vo WHERE vo.variant_id = v.variant_id) = $4
+		AND
+			(
+				SELECT
+					count(*)
+				FROM
+					tbl_variant_option_value vo
+				JOIN
+					tbl_product_option o ON o.option_id = vo.option_id
+				JOIN
+					tbl_product_option_value ov ON ov.value_id = vo.value_id
+				JOIN
+					unnest($2::TEXT[], $3::TEXT[]) AS w(name, value)
+				ON
+					lower(o.name) = lower(w.name) AND ov.value = w.value
+				WHERE
+					vo.variant_id = v.variant_id
+			) = $4
+		ORDER BY
+			v.variant_id ASC
+		LIMIT 1
+	`
+	err := repo.DB.QueryRow(query, productID, pq.Array(names), pq.Array(values), len(options)).Scan(&variantID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return variantID, nil
+}
+
+//FindVariantByName to get the live variant of the product with the name and no options, 0 when there
+//is none
+func (repo *Repo) FindVariantByName(productID int, name string) (int, error) {
+	var variantID int
+	query := `
+		SELECT
+			variant_id
+		FROM
+			tbl_variant
+		WHERE
+			product_id = $1
+		AND
+			name = $2
+		AND
+			option_signature IS NULL
+		AND
+			deleted_at IS NULL
+		ORDER BY
+			variant_id ASC
+		LIMIT 1
+	`
+	err := repo.DB.QueryRow(query, productID, name).Scan(&variantID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return variantID, nil
+}
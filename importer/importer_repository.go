@@ -0,0 +1,22 @@
+package importer
+
+import (
+	"database/sql"
+	"ecommerce/variant"
+)
+
+//RepoInterface for DB operations
+type RepoInterface interface {
+	FindCategory(string) (*CategoryRef, error)
+	FindProduct(string) (*ProductRef, error)
+	FindVariantBySKU(string) (int, int, error)
+	FindVariantByOptions(int, []variant.OptionValue) (int, error)
+	FindVariantByName(int, string) (int, error)
+}
+
+//NewRepo returns repository interface
+func NewRepo(db *sql.DB) RepoInterface {
+	return &Repo{
+		DB: db,
+	}
+}
@@ -0,0 +1,491 @@
+package importer
+
+import (
+	"database/sql"
+	"ecommerce/category"
+	"ecommerce/product"
+	"ecommerce/search"
+	"ecommerce/utils"
+	"ecommerce/variant"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/go-playground/validator.v9"
+)
+
+//ServiceInterface is import service interface
+type ServiceInterface interface {
+	Import(*ImportRequest) (*ImportResponse, error)
+	CountRows(io.Reader) (int, error)
+}
+
+//Service struct for service functionalities, the rows are applied through the category, product
+//and variant services so they fail with the messages of the API
+type Service struct {
+	repo       RepoInterface
+	categories category.ServiceInterface
+	products   product.ServiceInterface
+	variants   variant.ServiceInterface
+}
+
+//NewService :
+func NewService(db *sql.DB, index search.SearchIndex) ServiceInterface {
+	return &Service{
+		repo:       NewRepo(db),
+		categories: category.NewService(db, index),
+		products:   product.NewService(db, index),
+		variants:   variant.NewService(db, index),
+	}
+}
+
+//importState to hold what the rows of an import share, a product is upserted by its first row
+type importState struct {
+	categories map[string]int
+	products   map[string]importedProduct
+	schemas    map[int][]category.Attribute
+	changedBy  string
+	response   *ImportResponse
+}
+
+//Import to apply the rows of the CSV file, one row per variant. Missing categories of the category
+//path are created, products are upserted by name and variants by SKU, then by option values, then by
+//name. The file is checked as a whole before any row is applied, the rows are then applied in chunks,
+//each row on its own so a failing row doesn't undo the others. The report has the applied rows only
+func (service *Service) Import(request *ImportRequest) (*ImportResponse, error) {
+	rows, err := readRows(request.File)
+	if err != nil {
+		return nil, err
+	}
+	state := importState{
+		categories: map[string]int{},
+		products:   map[string]importedProduct{},
+		schemas:    map[int][]category.Attribute{},
+		changedBy:  request.ChangedBy,
+		response: &ImportResponse{
+			Total: len(rows),
+			Rows:  []RowReport{},
+		},
+	}
+	for start := request.Skip; start < len(rows); start += ImportChunkSize {
+		end := start + ImportChunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		var reports []RowReport
+		for i := start; i < end; i++ {
+			report := service.importRow(&rows[i], &state)
+			switch report.Status {
+			case StatusCreated:
+				state.response.Created++
+			case StatusUpdated:
+				state.response.Updated++
+			default:
+				state.response.Failed++
+			}
+			reports = append(reports, report)
+		}
+		state.response.Rows = append(state.response.Rows, reports...)
+		if request.Progress != nil {
+			err = request.Progress(reports)
+			if err != nil {
+				return nil, err
+			}
+		}
+		log.Println("App : Import rows applied, count =", end, "of", len(rows))
+	}
+	return state.response, nil
+}
+
+//CountRows to check the CSV file the way Import does, returning its number of rows
+func (service *Service) CountRows(file io.Reader) (int, error) {
+	rows, err := readRows(file)
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+//readRows to read and parse the rows of the CSV file, a row that can't be parsed is kept with its error
+func readRows(file io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New(utils.InvalidImportFileError)
+	}
+	header[0] = strings.TrimPrefix(header[0], byteOrderMark)
+	columns, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	reader.FieldsPerRecord = len(header)
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(rows) == MaxImportRows {
+			return nil, errors.New(utils.TooManyImportRowsError)
+		}
+		row := ImportRow{Row: len(rows) + 1}
+		if _, ok := err.(*csv.ParseError); ok {
+			row.Err = errors.New(utils.InvalidImportRowError)
+		} else if err != nil {
+			log.Println("Error : error reading the import file(Import) -", err.Error())
+			return nil, errors.New(utils.ImportFileReadError)
+		} else {
+			row = parseRow(columns, header, record, row.Row)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+//parseHeader to map the lower cased column names to their position, the required columns must be
+//present and a column can only be given once
+func parseHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok || name == utils.EmptyString {
+			return nil, errors.New(utils.InvalidImportFileError)
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New(utils.InvalidImportFileError)
+		}
+	}
+	return columns, nil
+}
+
+//parseRow to convert a record into the category path, product and variant of the row
+func parseRow(columns map[string]int, header []string, record []string, number int) ImportRow {
+	get := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return utils.EmptyString
+	}
+	row := ImportRow{
+		Row:         number,
+		ProductName: get(ProductNameColumn),
+		Description: get(DescriptionColumn),
+		ImageURL:    get(ImageURLColumn),
+		Attributes:  map[string]string{},
+		Variant: variant.CreateRequest{
+			Name:    get(VariantNameColumn),
+			SKU:     get(SKUColumn),
+			Barcode: get(BarcodeColumn),
+		},
+	}
+	for _, name := range strings.Split(get(CategoryColumn), CategoryPathSeparator) {
+		name = strings.TrimSpace(name)
+		if name == utils.EmptyString {
+			row.Err = errors.New(utils.CategoryPathError)
+			return row
+		}
+		row.CategoryPath = append(row.CategoryPath, name)
+	}
+	currency := get(CurrencyColumn)
+	amount, err := strconv.ParseInt(get(MRPColumn), 10, 64)
+	if err != nil {
+		row.Err = errors.New(utils.InvalidImportValueError + " " + MRPColumn)
+		return row
+	}
+	row.Variant.MRP = utils.Money{Amount: amount, Currency: currency}
+	if value := get(DiscountPriceColumn); value != utils.EmptyString {
+		amount, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			row.Err = errors.New(utils.InvalidImportValueError + " " + DiscountPriceColumn)
+			return row
+		}
+		row.Variant.DiscountPrice = &utils.Money{Amount: amount, Currency: currency}
+	}
+	for _, name := range []string{DiscountValidFromColumn, DiscountValidUntilColumn} {
+		value := get(name)
+		if value == utils.EmptyString {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			row.Err = errors.New(utils.InvalidImportValueError + " " + name)
+			return row
+		}
+		if name == DiscountValidFromColumn {
+			row.Variant.DiscountValidFrom = &at
+		} else {
+			row.Variant.DiscountValidUntil = &at
+		}
+	}
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		value := strings.TrimSpace(record[i])
+		if value == utils.EmptyString {
+			continue
+		}
+		lower := strings.ToLower(column)
+		if strings.HasPrefix(lower, OptionPrefix) {
+			row.Variant.Options = append(row.Variant.Options, variant.OptionValue{
+				Name:     strings.TrimSpace(column[len(OptionPrefix):]),
+				Value:    value,
+				Register: true,
+			})
+		} else if strings.HasPrefix(lower, AttributePrefix) {
+			row.Attributes[strings.TrimSpace(column[len(AttributePrefix):])] = value
+		}
+	}
+	return row
+}
+
+//importRow to apply a row, reporting the outcome for its variant
+func (service *Service) importRow(row *ImportRow, state *importState) RowReport {
+	report := RowReport{
+		Row:    row.Row,
+		Status: StatusFailed,
+	}
+	if row.Err != nil {
+		report.Message = row.Err.Error()
+		return report
+	}
+	categoryID, err := service.resolveCategory(row.CategoryPath, state)
+	if err != nil {
+		report.Message = err.Error()
+		return report
+	}
+	report.ProductID, err = service.upsertProduct(categoryID, row, state)
+	if err != nil {
+		report.ProductID = 0
+		report.Message = err.Error()
+		return report
+	}
+	report.VariantID, report.Status, err = service.upsertVariant(report.ProductID, row, state.changedBy)
+	if err != nil {
+		report.Status = StatusFailed
+		report.Message = err.Error()
+	}
+	return report
+}
+
+//resolveCategory to get the category at the end of the path, creating the missing categories. Category
+//names are unique, so a category of the path existing under another parent fails the row
+func (service *Service) resolveCategory(path []string, state *importState) (int, error) {
+	parentID := category.DefaultCategory
+	for i, name := range path {
+		key := strings.Join(path[:i+1], CategoryPathSeparator)
+		if categoryID, ok := state.categories[key]; ok {
+			parentID = categoryID
+			continue
+		}
+		existing, err := service.repo.FindCategory(name)
+		if err != nil {
+			return 0, err
+		}
+		if existing != nil {
+			if existing.ParentID != parentID {
+				return 0, errors.New(utils.CategoryExistsError)
+			}
+			parentID = existing.ID
+		} else {
+			request := category.CreateRequest{
+				Name:     name,
+				ParentID: parentID,
+			}
+			created, err := service.categories.CreateCategory(&request)
+			if err != nil {
+				return 0, err
+			}
+			state.response.CategoriesCreated++
+			parentID = created.ID
+		}
+		state.categories[key] = parentID
+	}
+	return parentID, nil
+}
+
+//upsertProduct to create the product of the row or update its description, image and attributes, the
+//first row of a product is applied and the later rows share its outcome
+func (service *Service) upsertProduct(categoryID int, row *ImportRow, state *importState) (int, error) {
+	if imported, ok := state.products[row.ProductName]; ok {
+		return imported.ID, imported.Err
+	}
+	productID, err := service.applyProduct(categoryID, row, state)
+	state.products[row.ProductName] = importedProduct{
+		ID:  productID,
+		Err: err,
+	}
+	return productID, err
+}
+
+//applyProduct to create or update the product of the row
+func (service *Service) applyProduct(categoryID int, row *ImportRow, state *importState) (int, error) {
+	existing, err := service.repo.FindProduct(row.ProductName)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil && existing.CategoryID != categoryID {
+		return 0, errors.New(utils.ImportCategoryMismatchError)
+	}
+	attributes, err := service.convertAttributes(categoryID, row.Attributes, state)
+	if err != nil {
+		return 0, err
+	}
+	validate := validator.New()
+	if existing == nil {
+		request := product.CreateRequest{
+			Name:        row.ProductName,
+			Description: row.Description,
+			ImageURL:    row.ImageURL,
+			CategoryID:  categoryID,
+			Attributes:  attributes,
+		}
+		err = validate.Struct(request)
+		if err != nil {
+			log.Println("Error : Validation error(Import) - row", row.Row, "-", err.Error())
+			return 0, errors.New(validationError)
+		}
+		created, err := service.products.CreateProduct(&request)
+		if err != nil {
+			return 0, err
+		}
+		state.response.ProductsCreated++
+		return created.ID, nil
+	}
+	if row.Description == utils.EmptyString && row.ImageURL == utils.EmptyString && len(attributes) == 0 {
+		return existing.ID, nil
+	}
+	request := product.UpdateRequest{
+		ProductID:   existing.ID,
+		Description: row.Description,
+		ImageURL:    row.ImageURL,
+		Attributes:  attributes,
+	}
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(Import) - row", row.Row, "-", err.Error())
+		return 0, errors.New(validationError)
+	}
+	err = service.products.UpdateProduct(&request)
+	if err != nil {
+		return 0, err
+	}
+	return existing.ID, nil
+}
+
+//convertAttributes to convert the attribute values of the row to the JSON types of the attributes of
+//the category, a value that doesn't convert is left as text for the product service to reject
+func (service *Service) convertAttributes(categoryID int, values map[string]string, state *importState) (map[string]interface{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	schema, ok := state.schemas[categoryID]
+	if !ok {
+		var err error
+		schema, err = service.categories.ListAttributes(categoryID)
+		if err != nil {
+			return nil, err
+		}
+		state.schemas[categoryID] = schema
+	}
+	types := make(map[string]string)
+	for _, attribute := range schema {
+		types[strings.ToLower(attribute.Name)] = attribute.Type
+	}
+	attributes := make(map[string]interface{})
+	for name, value := range values {
+		attributes[name] = value
+		switch types[strings.ToLower(name)] {
+		case utils.AttributeInt, utils.AttributeFloat:
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				attributes[name] = number
+			}
+		case utils.AttributeBool:
+			if flag, err := strconv.ParseBool(value); err == nil {
+				attributes[name] = flag
+			}
+		}
+	}
+	return attributes, nil
+}
+
+//upsertVariant to update the variant of the product matching the row or create it, returning the
+//variant ID and the row status
+func (service *Service) upsertVariant(productID int, row *ImportRow, changedBy string) (int, string, error) {
+	request := row.Variant
+	request.ProductID = productID
+	request.ChangedBy = changedBy
+	variantID, err := service.findVariant(&request)
+	if err != nil {
+		return 0, StatusFailed, err
+	}
+	validate := validator.New()
+	if variantID == 0 {
+		err = validate.Struct(request)
+		if err != nil {
+			log.Println("Error : Validation error(Import) - row", row.Row, "-", err.Error())
+			return 0, StatusFailed, errors.New(validationError)
+		}
+		created, err := service.variants.CreateVariant(&request)
+		if err != nil {
+			return 0, StatusFailed, err
+		}
+		return created.ID, StatusCreated, nil
+	}
+	update := variant.UpdateRequest{
+		VariantID:          variantID,
+		Name:               request.Name,
+		MRP:                &request.MRP,
+		DiscountPrice:      request.DiscountPrice,
+		DiscountValidFrom:  request.DiscountValidFrom,
+		DiscountValidUntil: request.DiscountValidUntil,
+		Options:            request.Options,
+		ChangedBy:          changedBy,
+	}
+	if request.SKU != utils.EmptyString {
+		update.SKU = &request.SKU
+	}
+	if request.Barcode != utils.EmptyString {
+		update.Barcode = &request.Barcode
+	}
+	err = validate.Struct(update)
+	if err != nil {
+		log.Println("Error : Validation error(Import) - row", row.Row, "-", err.Error())
+		return 0, StatusFailed, errors.New(validationError)
+	}
+	err = service.variants.UpdateVariant(&update)
+	if err != nil {
+		return 0, StatusFailed, err
+	}
+	return variantID, StatusUpdated, nil
+}
+
+//findVariant to get the variant of the product matching the request by SKU, then by option values,
+//then by name for a variant without options, 0 when the variant is new
+func (service *Service) findVariant(request *variant.CreateRequest) (int, error) {
+	if request.SKU != utils.EmptyString {
+		variantID, productID, err := service.repo.FindVariantBySKU(request.SKU)
+		if err != nil {
+			return 0, err
+		}
+		if variantID != 0 && productID != request.ProductID {
+			return 0, errors.New(utils.SKUExistsError)
+		}
+		if variantID != 0 {
+			return variantID, nil
+		}
+	}
+	if len(request.Options) > 0 {
+		return service.repo.FindVariantByOptions(request.ProductID, request.Options)
+	}
+	if request.Name != utils.EmptyString {
+		return service.repo.FindVariantByName(request.ProductID, request.Name)
+	}
+	return 0, nil
+}
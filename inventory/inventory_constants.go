@@ -0,0 +1,31 @@
+package inventory
+
+import "time"
+
+const (
+	//DefaultLocationID location used when a stock adjustment doesn't specify one
+	DefaultLocationID = 1
+	//ListMovementLimit default limit for stock movement listing
+	ListMovementLimit = 50
+	//MaxMovementLimit maximum limit for stock movement listing
+	MaxMovementLimit = 500
+	//Offset default offset value for stock movement listing
+	Offset = 0
+	//DefaultReservationMinutes default lifetime of a stock reservation
+	DefaultReservationMinutes = 15
+	//MaxReservationMinutes maximum lifetime of a stock reservation
+	MaxReservationMinutes = 1440
+	//ReservationActive status of a reservation holding stock
+	ReservationActive = "active"
+	//ReservationCommitted status of a reservation whose stock is sold
+	ReservationCommitted = "committed"
+	//ReservationCancelled status of a reservation released by the client
+	ReservationCancelled = "cancelled"
+	//ReservationExpired status of a reservation released by the sweeper
+	ReservationExpired = "expired"
+	//ReasonSold stock movement reason for sold stock
+	ReasonSold = "sold"
+)
+
+//SweepInterval interval at which the expired stock reservations are released
+const SweepInterval = time.Minute
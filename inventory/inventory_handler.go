@@ -0,0 +1,304 @@
+package inventory
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+//HandlerInterface for inventory management
+type HandlerInterface interface {
+	CreateLocation(http.ResponseWriter, *http.Request)
+	ListLocation(http.ResponseWriter, *http.Request)
+	AdjustStock(http.ResponseWriter, *http.Request)
+	GetStock(http.ResponseWriter, *http.Request)
+	ListMovement(http.ResponseWriter, *http.Request)
+	CreateReservation(http.ResponseWriter, *http.Request)
+	GetReservation(http.ResponseWriter, *http.Request)
+	CommitReservation(http.ResponseWriter, *http.Request)
+	CancelReservation(http.ResponseWriter, *http.Request)
+}
+
+//Handler struct for inventory management
+type Handler struct {
+	cs ServiceInterface
+}
+
+//NewHTTPHandler to handle inventory requests
+func NewHTTPHandler(db *sql.DB) HandlerInterface {
+	return &Handler{
+		cs: NewService(db),
+	}
+}
+
+//CreateLocation to handle the location post request
+func (h *Handler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /location POST API")
+	var request LocationRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(CreateLocation) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(CreateLocation) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	location, err := h.cs.CreateLocation(&request)
+	if err != nil {
+		log.Println("Error : Location creation error(CreateLocation) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Location created successfully, location id =", location.ID)
+	utils.Send(w, 200, location)
+}
+
+//ListLocation to handle the location get request
+func (h *Handler) ListLocation(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /location GET API")
+	locations, err := h.cs.ListLocation()
+	if err != nil {
+		log.Println("Error : Location listing error(ListLocation) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Locations listed successfully")
+	utils.Send(w, 200, locations)
+}
+
+//AdjustStock to handle the stock adjustment request
+func (h *Handler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/stock POST API")
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (AdjustStock)")
+		utils.Fail(w, 400, utils.InvalidVariantID)
+		return
+	}
+	var request AdjustRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(AdjustStock) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(AdjustStock) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.VariantID = variantID
+	stock, err := h.cs.AdjustStock(&request)
+	if err != nil {
+		log.Println("Error : Stock adjustment error(AdjustStock) -", err.Error())
+		if err.Error() == utils.VariantIDNotExist || err.Error() == utils.LocationNotExist ||
+			err.Error() == utils.InsufficientStockError {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Stock adjusted successfully, variant id =", variantID)
+	utils.Send(w, 200, stock)
+}
+
+//GetStock to handle the stock get request
+func (h *Handler) GetStock(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/stock GET API")
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (GetStock)")
+		utils.Fail(w, 400, utils.InvalidVariantID)
+		return
+	}
+	stock, err := h.cs.GetStock(variantID)
+	if err != nil {
+		log.Println("Error : Stock fetching error(GetStock) -", err.Error())
+		if err.Error() == utils.VariantIDNotExist {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Stock fetched successfully, variant id =", variantID)
+	utils.Send(w, 200, stock)
+}
+
+//ListMovement to handle the stock movement ledger request
+func (h *Handler) ListMovement(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/stock/movements GET API")
+	request, err := parseMovementRequest(r)
+	if err != nil {
+		log.Println("Error : request validation error(ListMovement) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	movements, err := h.cs.ListMovement(request)
+	if err != nil {
+		log.Println("Error : Stock movement listing error(ListMovement) -", err.Error())
+		if err.Error() == utils.VariantIDNotExist {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Stock movements listed successfully, variant id =", request.VariantID)
+	utils.Send(w, 200, movements)
+}
+
+//CreateReservation to handle the stock reservation request
+func (h *Handler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/reservations POST API")
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (CreateReservation)")
+		utils.Fail(w, 400, utils.InvalidVariantID)
+		return
+	}
+	var request ReservationRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(CreateReservation) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(CreateReservation) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.VariantID = variantID
+	reservation, err := h.cs.CreateReservation(&request)
+	if err != nil {
+		log.Println("Error : Reservation error(CreateReservation) -", err.Error())
+		if err.Error() == utils.VariantIDNotExist {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		if err.Error() == utils.InsufficientStockError {
+			utils.Fail(w, 409, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Stock reserved successfully, reservation id =", reservation.ID)
+	utils.Send(w, 200, reservation)
+}
+
+//GetReservation to handle the stock reservation get request
+func (h *Handler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/reservations/{reservation_id} GET API")
+	request, err := parseReservationAction(r)
+	if err != nil {
+		log.Println("Error : request validation error(GetReservation) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	reservation, err := h.cs.GetReservation(request)
+	h.sendReservation(w, reservation, err, "GetReservation")
+}
+
+//CommitReservation to handle the stock reservation commit request
+func (h *Handler) CommitReservation(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/reservations/{reservation_id}/commit POST API")
+	request, err := parseReservationAction(r)
+	if err != nil {
+		log.Println("Error : request validation error(CommitReservation) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	reservation, err := h.cs.CommitReservation(request)
+	h.sendReservation(w, reservation, err, "CommitReservation")
+}
+
+//CancelReservation to handle the stock reservation cancel request
+func (h *Handler) CancelReservation(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/reservations/{reservation_id}/cancel POST API")
+	request, err := parseReservationAction(r)
+	if err != nil {
+		log.Println("Error : request validation error(CancelReservation) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	reservation, err := h.cs.CancelReservation(request)
+	h.sendReservation(w, reservation, err, "CancelReservation")
+}
+
+func (h *Handler) sendReservation(w http.ResponseWriter, reservation *Reservation, err error, caller string) {
+	if err != nil {
+		log.Println("Error : Reservation error("+caller+") -", err.Error())
+		if err.Error() == utils.VariantIDNotExist || err.Error() == utils.ReservationNotExist {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		if err.Error() == utils.ReservationNotActive || err.Error() == utils.InsufficientStockError {
+			utils.Fail(w, 409, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Reservation processed successfully, reservation id =", reservation.ID)
+	utils.Send(w, 200, reservation)
+}
+
+func parseReservationAction(r *http.Request) (*ReservationAction, error) {
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		return nil, errors.New(utils.InvalidVariantID)
+	}
+	reservationID, err := strconv.Atoi(chi.URLParam(r, "reservation_id"))
+	if err != nil {
+		return nil, errors.New(utils.ReservationNotExist)
+	}
+	return &ReservationAction{
+		VariantID:     variantID,
+		ReservationID: reservationID,
+	}, nil
+}
+
+func parseMovementRequest(r *http.Request) (*MovementRequest, error) {
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		return nil, errors.New(utils.InvalidVariantID)
+	}
+	request := MovementRequest{
+		VariantID: variantID,
+		Limit:     ListMovementLimit,
+		Offset:    Offset,
+	}
+	query := r.URL.Query()
+	if value := query.Get("limit"); value != utils.EmptyString {
+		request.Limit, err = strconv.Atoi(value)
+		if err != nil || request.Limit <= 0 || request.Limit > MaxMovementLimit {
+			return nil, errors.New(utils.InvalidParameterError + " limit")
+		}
+	}
+	if value := query.Get("offset"); value != utils.EmptyString {
+		request.Offset, err = strconv.Atoi(value)
+		if err != nil || request.Offset < 0 {
+			return nil, errors.New(utils.InvalidParameterError + " offset")
+		}
+	}
+	return &request, nil
+}
@@ -0,0 +1,84 @@
+package inventory
+
+import "time"
+
+//LocationRequest to represent the warehouse location create request
+type LocationRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+//Location to represent a warehouse location
+type Location struct {
+	ID   int    `json:"location_id"`
+	Name string `json:"name"`
+}
+
+//AdjustRequest to represent the stock adjustment request, Quantity is added to the
+//stock on hand and can be negative
+type AdjustRequest struct {
+	VariantID  int    `json:"-"`
+	LocationID int    `json:"location_id" validate:"omitempty,gt=0"`
+	Quantity   int    `json:"quantity" validate:"required"`
+	Reason     string `json:"reason" validate:"required,oneof=received sold returned damaged lost correction transfer"`
+	Note       string `json:"note" validate:"max=200"`
+}
+
+//StockLevel to represent the stock of a variant at a location
+type StockLevel struct {
+	LocationID   int    `json:"location_id"`
+	LocationName string `json:"location_name"`
+	OnHand       int    `json:"on_hand"`
+}
+
+//Stock to represent the stock of a variant across locations
+type Stock struct {
+	VariantID         int          `json:"variant_id"`
+	OnHand            int          `json:"on_hand"`
+	AvailableQuantity int          `json:"available_quantity"`
+	InStock           bool         `json:"in_stock"`
+	Locations         []StockLevel `json:"locations"`
+}
+
+//MovementRequest to represent the stock movement listing request
+type MovementRequest struct {
+	VariantID int
+	Limit     int
+	Offset    int
+}
+
+//Movement to represent an entry of the stock movement ledger
+type Movement struct {
+	ID         int       `json:"movement_id"`
+	VariantID  int       `json:"variant_id"`
+	LocationID int       `json:"location_id"`
+	Quantity   int       `json:"quantity"`
+	Balance    int       `json:"balance"`
+	Reason     string    `json:"reason"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+//ReservationRequest to represent the stock reservation request
+type ReservationRequest struct {
+	VariantID  int    `json:"-"`
+	CartID     string `json:"cart_id" validate:"required,max=64"`
+	Quantity   int    `json:"quantity" validate:"required,gt=0"`
+	TTLMinutes int    `json:"ttl_minutes" validate:"omitempty,gt=0,lte=1440"`
+}
+
+//ReservationAction to represent the commit or cancel request of a reservation
+type ReservationAction struct {
+	VariantID     int
+	ReservationID int
+}
+
+//Reservation to represent a stock reservation of a variant for a cart
+type Reservation struct {
+	ID        int       `json:"reservation_id"`
+	VariantID int       `json:"variant_id"`
+	CartID    string    `json:"cart_id"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,533 @@
+package inventory
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"errors"
+	"fmt"
+)
+
+//Repo is the DB repository struct
+type Repo struct {
+	DB *sql.DB
+}
+
+//CreateLocation to create a warehouse location in DB
+func (repo *Repo) CreateLocation(request *LocationRequest) (*Location, error) {
+	var location Location
+	query := `
+		INSERT INTO
+			tbl_location (name, created_at, updated_at)
+		VALUES
+			($1, NOW(), NOW())
+		RETURNING
+			location_id, name
+	`
+	err := repo.DB.QueryRow(query, request.Name).Scan(&location.ID, &location.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+//ListLocation to list the warehouse locations
+func (repo *Repo) ListLocation() ([]Location, error) {
+	query := `
+		SELECT
+			location_id, name
+		FROM
+			tbl_location
+		WHERE
+			deleted_at IS NULL
+		ORDER BY
+			location_id ASC
+	`
+	rows, err := repo.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	locations := []Location{}
+	for rows.Next() {
+		var location Location
+		err := rows.Scan(&location.ID, &location.Name)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+	return locations, rows.Err()
+}
+
+//IsLocationIDExists function to check if the location ID exists
+func (repo *Repo) IsLocationIDExists(id int) (bool, error) {
+	var count int
+	query := `
+		SELECT
+			count(*)
+		FROM
+			tbl_location
+		WHERE
+			location_id = $1
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//IsVariantIDExists function to check if the variant ID exists
+func (repo *Repo) IsVariantIDExists(id int) (bool, error) {
+	var count int
+	query := `
+		SELECT
+			count(*)
+		FROM
+			tbl_variant
+		WHERE
+			variant_id = $1
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//lockVariant to lock the variant row so that stock operations on a variant are serialized
+func lockVariant(tx *sql.Tx, variantID int) error {
+	var id int
+	query := `
+		SELECT
+			variant_id
+		FROM
+			tbl_variant
+		WHERE
+			variant_id = $1
+		AND
+			deleted_at IS NULL
+		FOR UPDATE
+	`
+	err := tx.QueryRow(query, variantID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors.New(utils.VariantIDNotExist)
+	}
+	return err
+}
+
+//changeStock to add the quantity to the stock on hand of a location and record it in the ledger
+func changeStock(tx *sql.Tx, variantID int, locationID int, quantity int, reason string, note string) error {
+	query := `
+		INSERT INTO
+			tbl_stock (variant_id, location_id, on_hand, updated_at)
+		VALUES
+			($1, $2, 0, NOW())
+		ON CONFLICT (variant_id, location_id) DO NOTHING
+	`
+	_, err := tx.Exec(query, variantID, locationID)
+	if err != nil {
+		return err
+	}
+	var balance int
+	query = `
+		UPDATE
+			tbl_stock
+		SET
+			on_hand = on_hand + $3,
+			updated_at = NOW()
+		WHERE
+			variant_id = $1
+		AND
+			location_id = $2
+		AND
+			on_hand + $3 >= 0
+		RETURNING
+			on_hand
+	`
+	err = tx.QueryRow(query, variantID, locationID, quantity).Scan(&balance)
+	if err == sql.ErrNoRows {
+		return errors.New(utils.InsufficientStockError)
+	}
+	if err != nil {
+		return err
+	}
+	query = `
+		INSERT INTO
+			tbl_stock_movement (variant_id, location_id, quantity, balance, reason, note, created_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, NOW())
+	`
+	_, err = tx.Exec(query, variantID, locationID, quantity, balance, reason, sql.NullString{
+		String: note,
+		Valid:  note != utils.EmptyString,
+	})
+	return err
+}
+
+//availableQuantity to get the stock of a variant not held by active reservations, the variant
+//must be locked by the transaction
+func availableQuantity(tx *sql.Tx, variantID int) (int, error) {
+	var available int
+	query := `
+		SELECT
+			COALESCE(SUM(available_quantity), 0)
+		FROM
+			vw_variant_stock
+		WHERE
+			variant_id = $1
+	`
+	err := tx.QueryRow(query, variantID).Scan(&available)
+	return available, err
+}
+
+//AdjustStock to adjust the stock of a variant at a location and record the movement
+func (repo *Repo) AdjustStock(request *AdjustRequest) error {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	err = lockVariant(tx, request.VariantID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if request.Quantity < 0 {
+		//stock held by active reservations can't be taken out by an adjustment
+		available, err := availableQuantity(tx, request.VariantID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if available < -request.Quantity {
+			tx.Rollback()
+			return errors.New(utils.InsufficientStockError)
+		}
+	}
+	err = changeStock(tx, request.VariantID, request.LocationID, request.Quantity, request.Reason, request.Note)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+//GetStock to get the stock of a variant across locations
+func (repo *Repo) GetStock(variantID int) (*Stock, error) {
+	stock := Stock{
+		VariantID: variantID,
+		Locations: []StockLevel{},
+	}
+	query := `
+		SELECT
+			s.location_id, l.name, s.on_hand
+		FROM
+			tbl_stock s
+		JOIN
+			tbl_location l
+		ON
+			s.location_id = l.location_id
+		WHERE
+			s.variant_id = $1
+		ORDER BY
+			s.location_id ASC
+	`
+	rows, err := repo.DB.Query(query, variantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var level StockLevel
+		err := rows.Scan(&level.LocationID, &level.LocationName, &level.OnHand)
+		if err != nil {
+			return nil, err
+		}
+		stock.Locations = append(stock.Locations, level)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	query = `
+		SELECT
+			COALESCE(SUM(on_hand), 0), COALESCE(SUM(available_quantity), 0)
+		FROM
+			vw_variant_stock
+		WHERE
+			variant_id = $1
+	`
+	err = repo.DB.QueryRow(query, variantID).Scan(&stock.OnHand, &stock.AvailableQuantity)
+	if err != nil {
+		return nil, err
+	}
+	stock.InStock = stock.AvailableQuantity > 0
+	return &stock, nil
+}
+
+//ListMovement to list the stock movement ledger of a variant, latest first
+func (repo *Repo) ListMovement(request *MovementRequest) ([]Movement, error) {
+	var note sql.NullString
+	query := `
+		SELECT
+			movement_id, variant_id, location_id, quantity, balance, reason, note, created_at
+		FROM
+			tbl_stock_movement
+		WHERE
+			variant_id = $1
+		ORDER BY
+			movement_id DESC
+		LIMIT $2
+		OFFSET $3
+	`
+	rows, err := repo.DB.Query(query, request.VariantID, request.Limit, request.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	movements := []Movement{}
+	for rows.Next() {
+		var movement Movement
+		err := rows.Scan(&movement.ID, &movement.VariantID, &movement.LocationID, &movement.Quantity,
+			&movement.Balance, &movement.Reason, &note, &movement.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if note.Valid {
+			movement.Note = note.String
+		}
+		movements = append(movements, movement)
+	}
+	return movements, rows.Err()
+}
+
+//CreateReservation to reserve the stock of a variant, the variant row lock serializes concurrent
+//reservations so that the available quantity can't be oversold
+func (repo *Repo) CreateReservation(request *ReservationRequest) (*Reservation, error) {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	err = lockVariant(tx, request.VariantID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	available, err := availableQuantity(tx, request.VariantID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if available < request.Quantity {
+		tx.Rollback()
+		return nil, errors.New(utils.InsufficientStockError)
+	}
+	var reservation Reservation
+	query := `
+		INSERT INTO
+			tbl_stock_reservation (variant_id, cart_id, quantity, status, expires_at, created_at, updated_at)
+		VALUES
+			($1, $2, $3, $4, NOW() + make_interval(mins => $5), NOW(), NOW())
+		RETURNING
+			reservation_id, variant_id, cart_id, quantity, status, expires_at, created_at
+	`
+	err = tx.QueryRow(query, request.VariantID, request.CartID, request.Quantity, ReservationActive, request.TTLMinutes).Scan(
+		&reservation.ID, &reservation.VariantID, &reservation.CartID, &reservation.Quantity,
+		&reservation.Status, &reservation.ExpiresAt, &reservation.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+//GetReservation to get a stock reservation of a variant
+func (repo *Repo) GetReservation(request *ReservationAction) (*Reservation, error) {
+	var reservation Reservation
+	query := `
+		SELECT
+			reservation_id, variant_id, cart_id, quantity, status, expires_at, created_at
+		FROM
+			tbl_stock_reservation
+		WHERE
+			reservation_id = $1
+		AND
+			variant_id = $2
+	`
+	err := repo.DB.QueryRow(query, request.ReservationID, request.VariantID).Scan(
+		&reservation.ID, &reservation.VariantID, &reservation.CartID, &reservation.Quantity,
+		&reservation.Status, &reservation.ExpiresAt, &reservation.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New(utils.ReservationNotExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+//lockActiveReservation to lock an active, unexpired reservation and get its quantity
+func lockActiveReservation(tx *sql.Tx, request *ReservationAction) (int, error) {
+	var quantity int
+	var isActive bool
+	query := `
+		SELECT
+			quantity, status = $3 AND expires_at > NOW()
+		FROM
+			tbl_stock_reservation
+		WHERE
+			reservation_id = $1
+		AND
+			variant_id = $2
+		FOR UPDATE
+	`
+	err := tx.QueryRow(query, request.ReservationID, request.VariantID, ReservationActive).Scan(&quantity, &isActive)
+	if err == sql.ErrNoRows {
+		return 0, errors.New(utils.ReservationNotExist)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !isActive {
+		return 0, errors.New(utils.ReservationNotActive)
+	}
+	return quantity, nil
+}
+
+func setReservationStatus(tx *sql.Tx, reservationID int, status string) error {
+	query := `
+		UPDATE
+			tbl_stock_reservation
+		SET
+			status = $2,
+			updated_at = NOW()
+		WHERE
+			reservation_id = $1
+	`
+	_, err := tx.Exec(query, reservationID, status)
+	return err
+}
+
+//CommitReservation to mark the reserved stock as sold, taking it out of the locations with the most stock first
+func (repo *Repo) CommitReservation(request *ReservationAction) error {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	err = lockVariant(tx, request.VariantID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	quantity, err := lockActiveReservation(tx, request)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	query := `
+		SELECT
+			location_id, on_hand
+		FROM
+			tbl_stock
+		WHERE
+			variant_id = $1
+		AND
+			on_hand > 0
+		ORDER BY
+			on_hand DESC,
+			location_id ASC
+	`
+	rows, err := tx.Query(query, request.VariantID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	var levels []StockLevel
+	for rows.Next() {
+		var level StockLevel
+		err := rows.Scan(&level.LocationID, &level.OnHand)
+		if err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		levels = append(levels, level)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		tx.Rollback()
+		return err
+	}
+	note := fmt.Sprintf("reservation %d", request.ReservationID)
+	for _, level := range levels {
+		if quantity == 0 {
+			break
+		}
+		take := level.OnHand
+		if take > quantity {
+			take = quantity
+		}
+		err = changeStock(tx, request.VariantID, level.LocationID, -take, ReasonSold, note)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		quantity -= take
+	}
+	if quantity > 0 {
+		tx.Rollback()
+		return errors.New(utils.InsufficientStockError)
+	}
+	err = setReservationStatus(tx, request.ReservationID, ReservationCommitted)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+//CancelReservation to release the reserved stock
+func (repo *Repo) CancelReservation(request *ReservationAction) error {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = lockActiveReservation(tx, request)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = setReservationStatus(tx, request.ReservationID, ReservationCancelled)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+//ExpireReservations to mark the active reservations past their expiry as expired
+func (repo *Repo) ExpireReservations() (int64, error) {
+	query := `
+		UPDATE
+			tbl_stock_reservation
+		SET
+			status = $1,
+			updated_at = NOW()
+		WHERE
+			status = $2
+		AND
+			expires_at <= NOW()
+	`
+	result, err := repo.DB.Exec(query, ReservationExpired, ReservationActive)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
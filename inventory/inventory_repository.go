@@ -0,0 +1,26 @@
+package inventory
+
+import "database/sql"
+
+//RepoInterface for DB operations
+type RepoInterface interface {
+	CreateLocation(*LocationRequest) (*Location, error)
+	ListLocation() ([]Location, error)
+	IsLocationIDExists(int) (bool, error)
+	IsVariantIDExists(int) (bool, error)
+	AdjustStock(*AdjustRequest) error
+	GetStock(int) (*Stock, error)
+	ListMovement(*MovementRequest) ([]Movement, error)
+	CreateReservation(*ReservationRequest) (*Reservation, error)
+	GetReservation(*ReservationAction) (*Reservation, error)
+	CommitReservation(*ReservationAction) error
+	CancelReservation(*ReservationAction) error
+	ExpireReservations() (int64, error)
+}
+
+//NewRepo returns repository interface
+func NewRepo(db *sql.DB) RepoInterface {
+	return &Repo{
+		DB: db,
+	}
+}
@@ -0,0 +1,129 @@
+package inventory
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+//TestDBEnv environment variable holding the connection string of a migrated Postgres database the
+//database tests run against, the tests are skipped when it is not set
+const TestDBEnv = "TestDBConString"
+
+//openTestDB to connect to the test database, skipping the test when none is configured
+func openTestDB(t *testing.T) *sql.DB {
+	conString, ok := os.LookupEnv(TestDBEnv)
+	if !ok {
+		t.Skip(TestDBEnv + " is not set")
+	}
+	db, err := sql.Open("postgres", conString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Ping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+//createTestVariant to create a category, a product and a variant, the product and variant are deleted
+//after the test as the app deletes them since the stock ledger keeps their movements
+func createTestVariant(t *testing.T, db *sql.DB) int {
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	var categoryID, productID, variantID int
+	err := db.QueryRow(`INSERT INTO tbl_category (name, created_at, updated_at) VALUES ($1, NOW(), NOW())
+		RETURNING category_id`, "reservation test "+suffix).Scan(&categoryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.QueryRow(`INSERT INTO tbl_product (name, category_id, created_at, updated_at) VALUES ($1, $2, NOW(), NOW())
+		RETURNING product_id`, "reservation test "+suffix, categoryID).Scan(&productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.QueryRow(`INSERT INTO tbl_variant (name, max_retail_price, currency, product_id, created_at, updated_at)
+		VALUES ($1, 1000, $2, $3, NOW(), NOW()) RETURNING variant_id`, suffix, utils.DefaultCurrency, productID).Scan(&variantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Exec("UPDATE tbl_variant SET deleted_at = NOW() WHERE variant_id = $1", variantID)
+		db.Exec("UPDATE tbl_product SET deleted_at = NOW() WHERE product_id = $1", productID)
+		db.Exec("UPDATE tbl_category SET deleted_at = NOW() WHERE category_id = $1", categoryID)
+	})
+	return variantID
+}
+
+//TestCreateReservationConcurrent reserves more than the stock on hand from concurrent requests, the
+//reservations that succeed must not oversell and the others must fail for insufficient stock
+func TestCreateReservationConcurrent(t *testing.T) {
+	db := openTestDB(t)
+	service := NewService(db)
+	variantID := createTestVariant(t, db)
+	const (
+		onHand   = 15
+		quantity = 2
+		requests = 25
+	)
+	_, err := service.AdjustStock(&AdjustRequest{
+		VariantID: variantID,
+		Quantity:  onHand,
+		Reason:    "received",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	reserved, failed := 0, 0
+	start := make(chan struct{})
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, err := service.CreateReservation(&ReservationRequest{
+				VariantID: variantID,
+				CartID:    fmt.Sprintf("cart-%d", i),
+				Quantity:  quantity,
+			})
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				if err.Error() != utils.InsufficientStockError {
+					t.Errorf("CreateReservation error = %v, want %s", err, utils.InsufficientStockError)
+				}
+				failed++
+				return
+			}
+			reserved += quantity
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	if reserved > onHand {
+		t.Errorf("reserved %d, more than the %d on hand", reserved, onHand)
+	}
+	if reserved != onHand/quantity*quantity {
+		t.Errorf("reserved %d, want %d", reserved, onHand/quantity*quantity)
+	}
+	if reserved/quantity+failed != requests {
+		t.Errorf("%d reservations and %d failures, want %d requests", reserved/quantity, failed, requests)
+	}
+	stock, err := service.GetStock(variantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock.OnHand != onHand || stock.AvailableQuantity != onHand-reserved {
+		t.Errorf("stock on hand %d available %d, want %d and %d", stock.OnHand, stock.AvailableQuantity, onHand,
+			onHand-reserved)
+	}
+}
@@ -0,0 +1,116 @@
+package inventory
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"errors"
+)
+
+//ServiceInterface is inventory service interface
+type ServiceInterface interface {
+	CreateLocation(*LocationRequest) (*Location, error)
+	ListLocation() ([]Location, error)
+	AdjustStock(*AdjustRequest) (*Stock, error)
+	GetStock(int) (*Stock, error)
+	ListMovement(*MovementRequest) ([]Movement, error)
+	CreateReservation(*ReservationRequest) (*Reservation, error)
+	GetReservation(*ReservationAction) (*Reservation, error)
+	CommitReservation(*ReservationAction) (*Reservation, error)
+	CancelReservation(*ReservationAction) (*Reservation, error)
+}
+
+//Service struct for service functionalities
+type Service struct {
+	repo RepoInterface
+}
+
+//NewService :
+func NewService(db *sql.DB) ServiceInterface {
+	return &Service{
+		repo: NewRepo(db),
+	}
+}
+
+//CreateLocation to create a warehouse location
+func (service *Service) CreateLocation(request *LocationRequest) (*Location, error) {
+	return service.repo.CreateLocation(request)
+}
+
+//ListLocation to list the warehouse locations
+func (service *Service) ListLocation() ([]Location, error) {
+	return service.repo.ListLocation()
+}
+
+//AdjustStock to adjust the stock of a variant and return its updated stock
+func (service *Service) AdjustStock(request *AdjustRequest) (*Stock, error) {
+	if request.LocationID == 0 {
+		request.LocationID = DefaultLocationID
+	}
+	isLocationExist, err := service.repo.IsLocationIDExists(request.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	if !isLocationExist {
+		return nil, errors.New(utils.LocationNotExist)
+	}
+	err = service.repo.AdjustStock(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.GetStock(request.VariantID)
+}
+
+//GetStock to get the stock of a variant
+func (service *Service) GetStock(variantID int) (*Stock, error) {
+	isVariantExist, err := service.repo.IsVariantIDExists(variantID)
+	if err != nil {
+		return nil, err
+	}
+	if !isVariantExist {
+		return nil, errors.New(utils.VariantIDNotExist)
+	}
+	return service.repo.GetStock(variantID)
+}
+
+//ListMovement to list the stock movements of a variant
+func (service *Service) ListMovement(request *MovementRequest) ([]Movement, error) {
+	isVariantExist, err := service.repo.IsVariantIDExists(request.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	if !isVariantExist {
+		return nil, errors.New(utils.VariantIDNotExist)
+	}
+	return service.repo.ListMovement(request)
+}
+
+//CreateReservation to reserve the stock of a variant for a cart
+func (service *Service) CreateReservation(request *ReservationRequest) (*Reservation, error) {
+	if request.TTLMinutes == 0 {
+		request.TTLMinutes = DefaultReservationMinutes
+	}
+	return service.repo.CreateReservation(request)
+}
+
+//GetReservation to get a stock reservation
+func (service *Service) GetReservation(request *ReservationAction) (*Reservation, error) {
+	return service.repo.GetReservation(request)
+}
+
+//CommitReservation to sell the reserved stock
+func (service *Service) CommitReservation(request *ReservationAction) (*Reservation, error) {
+	err := service.repo.CommitReservation(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.GetReservation(request)
+}
+
+//CancelReservation to release the reserved stock
+func (service *Service) CancelReservation(request *ReservationAction) (*Reservation, error) {
+	err := service.repo.CancelReservation(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.GetReservation(request)
+}
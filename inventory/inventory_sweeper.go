@@ -0,0 +1,59 @@
+package inventory
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+//Sweeper expires the stale stock reservations in the background
+type Sweeper struct {
+	repo     RepoInterface
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+//NewSweeper returns a sweeper which runs at the given interval
+func NewSweeper(db *sql.DB, interval time.Duration) *Sweeper {
+	return &Sweeper{
+		repo:     NewRepo(db),
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+//Start runs the sweeper in a new goroutine until Stop is called
+func (s *Sweeper) Start() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.sweep()
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+//Stop stops the sweeper and waits for the running sweep to finish
+func (s *Sweeper) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+func (s *Sweeper) sweep() {
+	expired, err := s.repo.ExpireReservations()
+	if err != nil {
+		log.Println("Error : error expiring stock reservations(Sweeper) -", err.Error())
+		return
+	}
+	if expired > 0 {
+		log.Println("App : Stock reservations expired, count =", expired)
+	}
+}
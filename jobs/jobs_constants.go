@@ -0,0 +1,30 @@
+package jobs
+
+import "time"
+
+const (
+	//ImportJob type of the jobs importing a catalogue CSV file
+	ImportJob = "import"
+	//StatusPending status of a job waiting for a worker
+	StatusPending = "pending"
+	//StatusRunning status of a job a worker is processing
+	StatusRunning = "running"
+	//StatusCompleted status of a job whose rows were all processed
+	StatusCompleted = "completed"
+	//StatusFailed status of a job that stopped on an error of the whole file
+	StatusFailed = "failed"
+	//WorkerCount number of workers processing the jobs
+	WorkerCount = 2
+	//ErrorReportPath path of the error report of a job, relative to the job
+	ErrorReportPath = "/errors"
+)
+
+//PollInterval interval at which the idle workers look for pending jobs
+const PollInterval = 2 * time.Second
+
+//LeaseDuration time a running job stays leased to its worker, another worker claims the job once the
+//lease expires without being renewed
+const LeaseDuration = time.Minute
+
+//HeartbeatInterval interval at which a worker renews the lease of its running job
+const HeartbeatInterval = 20 * time.Second
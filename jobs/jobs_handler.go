@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"database/sql"
+	"ecommerce/importer"
+	"ecommerce/search"
+	"ecommerce/utils"
+	"ecommerce/variant"
+	"encoding/csv"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+)
+
+//HandlerInterface for job management
+type HandlerInterface interface {
+	CreateImportJob(http.ResponseWriter, *http.Request)
+	GetJob(http.ResponseWriter, *http.Request)
+	GetJobErrors(http.ResponseWriter, *http.Request)
+}
+
+//Handler struct for job management
+type Handler struct {
+	cs ServiceInterface
+}
+
+//NewHTTPHandler to handle job requests
+func NewHTTPHandler(db *sql.DB, index search.SearchIndex) HandlerInterface {
+	return &Handler{
+		cs: NewService(db, index),
+	}
+}
+
+//CreateImportJob to handle the request to import a CSV file in the background, the file is the request
+//body or the file field of a multipart form
+func (h *Handler) CreateImportJob(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /jobs/import POST API")
+	r.Body = http.MaxBytesReader(w, r.Body, importer.MaxImportSize)
+	file, err := importer.GetImportFile(r)
+	if err != nil {
+		log.Println("Error : Import file error(CreateImportJob) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	defer file.Close()
+	payload, err := ioutil.ReadAll(file)
+	if err != nil {
+		log.Println("Error : Import file error(CreateImportJob) -", err.Error())
+		utils.Fail(w, 400, utils.ImportFileReadError)
+		return
+	}
+	request := CreateRequest{
+		Payload:   payload,
+		ChangedBy: r.Header.Get(variant.ChangedByHeader),
+	}
+	job, err := h.cs.CreateImportJob(&request)
+	if err != nil {
+		if importer.IsRequestError(err) {
+			log.Println("Error : Validation error(CreateImportJob) -", err.Error())
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		log.Println("Error : Job creation error(CreateImportJob) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Import job created successfully, Job ID =", job.ID)
+	utils.Send(w, 200, job)
+}
+
+//GetJob to handle the request to get the status and progress of a job
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /jobs/{job_id} GET API")
+	jobID, err := strconv.Atoi(chi.URLParam(r, "job_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (GetJob)")
+		utils.Fail(w, 400, utils.InvalidJobID)
+		return
+	}
+	job, err := h.cs.GetJob(jobID)
+	if err != nil {
+		if err.Error() == utils.JobNotExist {
+			log.Println("Error : Job doesn't exist error(GetJob) -", err.Error())
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		log.Println("Error : Get job error(GetJob) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	utils.Send(w, 200, job)
+}
+
+//GetJobErrors to handle the request to download the failed rows of a job as a CSV file
+func (h *Handler) GetJobErrors(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /jobs/{job_id}/errors GET API")
+	jobID, err := strconv.Atoi(chi.URLParam(r, "job_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (GetJobErrors)")
+		utils.Fail(w, 400, utils.InvalidJobID)
+		return
+	}
+	jobErrors, err := h.cs.ListJobErrors(jobID)
+	if err != nil {
+		if err.Error() == utils.JobNotExist {
+			log.Println("Error : Job doesn't exist error(GetJobErrors) -", err.Error())
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		log.Println("Error : List job errors error(GetJobErrors) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=job-"+strconv.Itoa(jobID)+"-errors.csv")
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"row", "message"})
+	for _, jobError := range jobErrors {
+		writer.Write([]string{strconv.Itoa(jobError.Row), jobError.Message})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Println("Error : Write error(GetJobErrors) -", err.Error())
+	}
+}
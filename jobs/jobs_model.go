@@ -0,0 +1,35 @@
+package jobs
+
+import "time"
+
+//CreateRequest to represent the request to queue a job
+type CreateRequest struct {
+	Type      string
+	Payload   []byte
+	TotalRows int
+	ChangedBy string
+}
+
+//Job to represent a job with its progress, the error report lists the failed rows
+type Job struct {
+	ID            int        `json:"job_id"`
+	Type          string     `json:"type"`
+	Status        string     `json:"status"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	CreatedRows   int        `json:"created_rows"`
+	UpdatedRows   int        `json:"updated_rows"`
+	FailedRows    int        `json:"failed_rows"`
+	Error         string     `json:"error,omitempty"`
+	ErrorReport   string     `json:"error_report,omitempty"`
+	ChangedBy     string     `json:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+//JobError to represent a failed row of a job
+type JobError struct {
+	Row     int
+	Message string
+}
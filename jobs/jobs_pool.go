@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"bytes"
+	"database/sql"
+	"ecommerce/importer"
+	"ecommerce/search"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+//errStopped stops a running import when the pool is stopped, the job is resumed by the next start
+var errStopped = errors.New("Job stopped by the shutdown of the worker pool")
+
+//errLeaseLost stops a running import when its worker no longer holds the lease on the job, another worker
+//claimed it after the lease expired
+var errLeaseLost = errors.New("Job lease lost by the worker")
+
+//Pool runs the pending jobs on a number of worker goroutines, the jobs are claimed from the database
+//so they survive a restart of the process
+type Pool struct {
+	repo     RepoInterface
+	importer importer.ServiceInterface
+	name     string
+	workers  int
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+//NewPool returns a pool of workers which look for pending jobs at the given interval, the workers are
+//named after the host and the process holding the leases
+func NewPool(db *sql.DB, index search.SearchIndex, workers int, interval time.Duration) *Pool {
+	host, _ := os.Hostname()
+	return &Pool{
+		repo:     NewRepo(db),
+		importer: importer.NewService(db, index),
+		name:     fmt.Sprintf("%s:%d", host, os.Getpid()),
+		workers:  workers,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+//Start runs the workers in new goroutines until Stop is called. The jobs a stopped process left running
+//are claimed again once their lease expires
+func (p *Pool) Start() {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go func(worker string) {
+			defer p.wg.Done()
+			ticker := time.NewTicker(p.interval)
+			defer ticker.Stop()
+			for {
+				for p.work(worker) {
+				}
+				select {
+				case <-ticker.C:
+				case <-p.stop:
+					return
+				}
+			}
+		}(fmt.Sprintf("%s:%d", p.name, i))
+	}
+}
+
+//Stop stops the workers, the running jobs stop after their current chunk and go back to pending
+func (p *Pool) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+//work to claim and run a pending job, false when there is none or the pool is stopping
+func (p *Pool) work(worker string) bool {
+	select {
+	case <-p.stop:
+		return false
+	default:
+	}
+	job, err := p.repo.ClaimJob(worker)
+	if err != nil {
+		log.Println("Error : error claiming a job(Pool) -", err.Error())
+		return false
+	}
+	if job == nil {
+		return false
+	}
+	log.Println("App : Job started, Job ID =", job.ID, "from row", job.ProcessedRows)
+	done := make(chan struct{})
+	lost := make(chan struct{})
+	go p.heartbeat(job.ID, worker, done, lost)
+	err = p.run(job, worker, lost)
+	close(done)
+	switch {
+	case err == errLeaseLost:
+		log.Println("Error : Job lease lost(Pool) - Job ID", job.ID)
+		return true
+	case err == errStopped:
+		err = p.repo.ReleaseJob(job.ID, worker)
+		log.Println("App : Job stopped, Job ID =", job.ID)
+	case err != nil:
+		log.Println("Error : Job failed(Pool) - Job ID", job.ID, "-", err.Error())
+		err = p.repo.FailJob(job.ID, worker, err.Error())
+	default:
+		err = p.repo.CompleteJob(job.ID, worker)
+		log.Println("App : Job completed, Job ID =", job.ID)
+	}
+	if err != nil {
+		log.Println("Error : error saving the job status(Pool) - Job ID", job.ID, "-", err.Error())
+	}
+	return true
+}
+
+//heartbeat to renew the lease of the worker on the job until done is closed, lost is closed when the
+//worker no longer holds the lease. A failed renewal is retried at the next beat
+func (p *Pool) heartbeat(jobID int, worker string, done <-chan struct{}, lost chan<- struct{}) {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+		err := p.repo.RenewLease(jobID, worker)
+		if err == errLeaseLost {
+			close(lost)
+			return
+		}
+		if err != nil {
+			log.Println("Error : error renewing the job lease(Pool) - Job ID", jobID, "-", err.Error())
+		}
+	}
+}
+
+//run to apply the rows of the job not yet processed, recording the progress after each chunk
+func (p *Pool) run(job *Job, worker string, lost <-chan struct{}) error {
+	payload, err := p.repo.GetPayload(job.ID)
+	if err != nil {
+		return err
+	}
+	request := importer.ImportRequest{
+		File:      bytes.NewReader(payload),
+		ChangedBy: job.ChangedBy,
+		Skip:      job.ProcessedRows,
+		Progress: func(reports []importer.RowReport) error {
+			select {
+			case <-lost:
+				return errLeaseLost
+			default:
+			}
+			err := p.repo.RecordProgress(job.ID, worker, reports)
+			if err != nil {
+				return err
+			}
+			select {
+			case <-p.stop:
+				return errStopped
+			default:
+				return nil
+			}
+		},
+	}
+	_, err = p.importer.Import(&request)
+	return err
+}
@@ -0,0 +1,317 @@
+package jobs
+
+import (
+	"database/sql"
+	"ecommerce/importer"
+	"ecommerce/utils"
+	"errors"
+)
+
+//Repo struct for postgres
+type Repo struct {
+	DB *sql.DB
+}
+
+//jobColumns columns of a job scanned by scanJob
+const jobColumns = `
+	job_id, type, status, COALESCE(changed_by, ''), total_rows, processed_rows, created_rows, updated_rows,
+	failed_rows, COALESCE(error, ''), created_at, started_at, finished_at
+`
+
+//scanJob to scan the jobColumns of a job
+func scanJob(row *sql.Row) (*Job, error) {
+	var job Job
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.Type, &job.Status, &job.ChangedBy, &job.TotalRows, &job.ProcessedRows,
+		&job.CreatedRows, &job.UpdatedRows, &job.FailedRows, &job.Error, &job.CreatedAt, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+//CreateJob to queue a pending job
+func (repo *Repo) CreateJob(request *CreateRequest) (*Job, error) {
+	query := `
+		INSERT INTO
+			tbl_job (type, status, payload, changed_by, total_rows, created_at, updated_at)
+		VALUES
+			($1, $2, $3, NULLIF($4, ''), $5, NOW(), NOW())
+		RETURNING
+	` + jobColumns
+	row := repo.DB.QueryRow(query, request.Type, StatusPending, request.Payload, request.ChangedBy, request.TotalRows)
+	return scanJob(row)
+}
+
+//GetJob to get the job with its progress
+func (repo *Repo) GetJob(jobID int) (*Job, error) {
+	query := `
+		SELECT
+	` + jobColumns + `
+		FROM
+			tbl_job
+		WHERE
+			job_id = $1
+	`
+	job, err := scanJob(repo.DB.QueryRow(query, jobID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New(utils.JobNotExist)
+	}
+	return job, err
+}
+
+//ListJobErrors to list the failed rows of the job in row order
+func (repo *Repo) ListJobErrors(jobID int) ([]JobError, error) {
+	query := `
+		SELECT
+			row_number, message
+		FROM
+			tbl_job_error
+		WHERE
+			job_id = $1
+		ORDER BY
+			row_number ASC
+	`
+	rows, err := repo.DB.Query(query, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	jobErrors := []JobError{}
+	for rows.Next() {
+		var jobError JobError
+		err := rows.Scan(&jobError.Row, &jobError.Message)
+		if err != nil {
+			return nil, err
+		}
+		jobErrors = append(jobErrors, jobError)
+	}
+	return jobErrors, rows.Err()
+}
+
+//ClaimJob to mark the oldest pending job, or running job whose lease has expired, running and lease it to
+//the worker, nil when there is none. The job row is locked with SKIP LOCKED so concurrent workers claim
+//different jobs
+func (repo *Repo) ClaimJob(worker string) (*Job, error) {
+	query := `
+		UPDATE
+			tbl_job
+		SET
+			status = $1,
+			locked_by = $3,
+			locked_until = NOW() + $4 * INTERVAL '1 second',
+			started_at = COALESCE(started_at, NOW()),
+			updated_at = NOW()
+		WHERE
+			job_id = (
+				SELECT
+					job_id
+				FROM
+					tbl_job
+				WHERE
+					status = $2
+				OR
+					(status = $1 AND locked_until < NOW())
+				ORDER BY
+					job_id ASC
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+		RETURNING
+	` + jobColumns
+	row := repo.DB.QueryRow(query, StatusRunning, StatusPending, worker, LeaseDuration.Seconds())
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+//RenewLease to extend the lease of the worker on the running job, errLeaseLost when the worker no longer
+//holds it
+func (repo *Repo) RenewLease(jobID int, worker string) error {
+	query := `
+		UPDATE
+			tbl_job
+		SET
+			locked_until = NOW() + $4 * INTERVAL '1 second'
+		WHERE
+			job_id = $1
+		AND
+			status = $2
+		AND
+			locked_by = $3
+	`
+	result, err := repo.DB.Exec(query, jobID, StatusRunning, worker, LeaseDuration.Seconds())
+	return checkLease(result, err)
+}
+
+//checkLease to turn an update of a leased job which changed no row into errLeaseLost
+func checkLease(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+//GetPayload to get the uploaded file of the job
+func (repo *Repo) GetPayload(jobID int) ([]byte, error) {
+	var payload []byte
+	query := `
+		SELECT
+			payload
+		FROM
+			tbl_job
+		WHERE
+			job_id = $1
+	`
+	err := repo.DB.QueryRow(query, jobID).Scan(&payload)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+//RecordProgress to add the reports of a processed chunk to the counts and error report of the job in
+//one transaction, so the job resumes after the last recorded chunk. The lease of the worker is renewed,
+//errLeaseLost when the worker no longer holds it
+func (repo *Repo) RecordProgress(jobID int, worker string, reports []importer.RowReport) error {
+	var created, updated, failed int
+	for _, report := range reports {
+		switch report.Status {
+		case importer.StatusCreated:
+			created++
+		case importer.StatusUpdated:
+			updated++
+		default:
+			failed++
+		}
+	}
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	query := `
+		UPDATE
+			tbl_job
+		SET
+			processed_rows = processed_rows + $2,
+			created_rows = created_rows + $3,
+			updated_rows = updated_rows + $4,
+			failed_rows = failed_rows + $5,
+			locked_until = NOW() + $8 * INTERVAL '1 second',
+			updated_at = NOW()
+		WHERE
+			job_id = $1
+		AND
+			status = $6
+		AND
+			locked_by = $7
+	`
+	result, err := tx.Exec(query, jobID, len(reports), created, updated, failed, StatusRunning, worker,
+		LeaseDuration.Seconds())
+	err = checkLease(result, err)
+	if err != nil {
+		return err
+	}
+	errorQuery := `
+		INSERT INTO
+			tbl_job_error (job_id, row_number, message)
+		VALUES
+			($1, $2, $3)
+		ON CONFLICT (job_id, row_number) DO UPDATE SET
+			message = EXCLUDED.message
+	`
+	for _, report := range reports {
+		if report.Status != importer.StatusFailed {
+			continue
+		}
+		_, err = tx.Exec(errorQuery, jobID, report.Row, report.Message)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+//CompleteJob to mark the job of the worker completed, the payload is no longer needed
+func (repo *Repo) CompleteJob(jobID int, worker string) error {
+	query := `
+		UPDATE
+			tbl_job
+		SET
+			status = $2,
+			payload = '',
+			locked_by = NULL,
+			locked_until = NULL,
+			finished_at = NOW(),
+			updated_at = NOW()
+		WHERE
+			job_id = $1
+		AND
+			status = $3
+		AND
+			locked_by = $4
+	`
+	result, err := repo.DB.Exec(query, jobID, StatusCompleted, StatusRunning, worker)
+	return checkLease(result, err)
+}
+
+//FailJob to mark the job of the worker failed with the error
+func (repo *Repo) FailJob(jobID int, worker string, message string) error {
+	query := `
+		UPDATE
+			tbl_job
+		SET
+			status = $2,
+			error = $5,
+			locked_by = NULL,
+			locked_until = NULL,
+			finished_at = NOW(),
+			updated_at = NOW()
+		WHERE
+			job_id = $1
+		AND
+			status = $3
+		AND
+			locked_by = $4
+	`
+	result, err := repo.DB.Exec(query, jobID, StatusFailed, StatusRunning, worker, message)
+	return checkLease(result, err)
+}
+
+//ReleaseJob to put the running job of the worker back to pending, a worker resumes it from its processed
+//rows
+func (repo *Repo) ReleaseJob(jobID int, worker string) error {
+	query := `
+		UPDATE
+			tbl_job
+		SET
+			status = $2,
+			locked_by = NULL,
+			locked_until = NULL,
+			updated_at = NOW()
+		WHERE
+			job_id = $1
+		AND
+			status = $3
+		AND
+			locked_by = $4
+	`
+	result, err := repo.DB.Exec(query, jobID, StatusPending, StatusRunning, worker)
+	return checkLease(result, err)
+}
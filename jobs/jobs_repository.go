@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"database/sql"
+	"ecommerce/importer"
+)
+
+//RepoInterface for DB operations
+type RepoInterface interface {
+	CreateJob(*CreateRequest) (*Job, error)
+	GetJob(int) (*Job, error)
+	ListJobErrors(int) ([]JobError, error)
+	ClaimJob(string) (*Job, error)
+	RenewLease(int, string) error
+	GetPayload(int) ([]byte, error)
+	RecordProgress(int, string, []importer.RowReport) error
+	CompleteJob(int, string) error
+	FailJob(int, string, string) error
+	ReleaseJob(int, string) error
+}
+
+//NewRepo returns repository interface
+func NewRepo(db *sql.DB) RepoInterface {
+	return &Repo{
+		DB: db,
+	}
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"bytes"
+	"database/sql"
+	"ecommerce/importer"
+	"ecommerce/search"
+	"strconv"
+)
+
+//ServiceInterface is job service interface
+type ServiceInterface interface {
+	CreateImportJob(*CreateRequest) (*Job, error)
+	GetJob(int) (*Job, error)
+	ListJobErrors(int) ([]JobError, error)
+}
+
+//Service struct for service functionalities
+type Service struct {
+	repo     RepoInterface
+	importer importer.ServiceInterface
+}
+
+//NewService :
+func NewService(db *sql.DB, index search.SearchIndex) ServiceInterface {
+	return &Service{
+		repo:     NewRepo(db),
+		importer: importer.NewService(db, index),
+	}
+}
+
+//CreateImportJob to queue the import of the CSV file, the file is checked before it is queued so a
+//job only fails on errors of its rows
+func (service *Service) CreateImportJob(request *CreateRequest) (*Job, error) {
+	totalRows, err := service.importer.CountRows(bytes.NewReader(request.Payload))
+	if err != nil {
+		return nil, err
+	}
+	request.Type = ImportJob
+	request.TotalRows = totalRows
+	job, err := service.repo.CreateJob(request)
+	if err != nil {
+		return nil, err
+	}
+	setErrorReport(job)
+	return job, nil
+}
+
+//GetJob to get the job with its progress
+func (service *Service) GetJob(jobID int) (*Job, error) {
+	job, err := service.repo.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	setErrorReport(job)
+	return job, nil
+}
+
+//ListJobErrors to list the failed rows of the job
+func (service *Service) ListJobErrors(jobID int) ([]JobError, error) {
+	_, err := service.repo.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.ListJobErrors(jobID)
+}
+
+//setErrorReport to set the path of the error report of a job having failed rows
+func setErrorReport(job *Job) {
+	if job.FailedRows > 0 {
+		job.ErrorReport = "/jobs/" + strconv.Itoa(job.ID) + ErrorReportPath
+	}
+}
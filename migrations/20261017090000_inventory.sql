@@ -0,0 +1,70 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE IF NOT EXISTS tbl_location (
+    location_id SERIAL,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    PRIMARY KEY (location_id)
+);
+
+INSERT INTO tbl_location (name, created_at, updated_at) VALUES ('Default', NOW(), NOW());
+
+CREATE TABLE IF NOT EXISTS tbl_stock (
+    variant_id INT NOT NULL,
+    location_id INT NOT NULL,
+    on_hand INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (variant_id, location_id),
+    FOREIGN KEY (variant_id) REFERENCES tbl_variant(variant_id) ON DELETE CASCADE,
+    FOREIGN KEY (location_id) REFERENCES tbl_location(location_id),
+    CHECK (on_hand >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS tbl_stock_movement (
+    movement_id SERIAL,
+    variant_id INT NOT NULL,
+    location_id INT NOT NULL,
+    quantity INT NOT NULL,
+    balance INT NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    note VARCHAR(200),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (movement_id),
+    FOREIGN KEY (variant_id) REFERENCES tbl_variant(variant_id),
+    FOREIGN KEY (location_id) REFERENCES tbl_location(location_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movement_variant ON tbl_stock_movement (variant_id, created_at);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_stock_movement_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'tbl_stock_movement is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_stock_movement_append_only
+    BEFORE UPDATE OR DELETE ON tbl_stock_movement
+    FOR EACH ROW EXECUTE PROCEDURE fn_stock_movement_append_only();
+
+CREATE OR REPLACE VIEW vw_variant_stock AS
+    SELECT
+        variant_id,
+        SUM(on_hand) AS on_hand,
+        SUM(on_hand) AS available_quantity
+    FROM
+        tbl_stock
+    GROUP BY
+        variant_id;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP VIEW IF EXISTS vw_variant_stock;
+DROP TRIGGER IF EXISTS trg_stock_movement_append_only ON tbl_stock_movement;
+DROP FUNCTION IF EXISTS fn_stock_movement_append_only();
+DROP TABLE IF EXISTS tbl_stock_movement;
+DROP TABLE IF EXISTS tbl_stock;
+DROP TABLE IF EXISTS tbl_location;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE IF NOT EXISTS tbl_stock_reservation (
+    reservation_id SERIAL,
+    variant_id INT NOT NULL,
+    cart_id VARCHAR(64) NOT NULL,
+    quantity INT NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (reservation_id),
+    FOREIGN KEY (variant_id) REFERENCES tbl_variant(variant_id) ON DELETE CASCADE,
+    CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservation_active ON tbl_stock_reservation (variant_id, expires_at) WHERE status = 'active';
+
+CREATE OR REPLACE VIEW vw_variant_stock AS
+    SELECT
+        s.variant_id,
+        SUM(s.on_hand) AS on_hand,
+        SUM(s.on_hand) - COALESCE((
+            SELECT
+                SUM(r.quantity)
+            FROM
+                tbl_stock_reservation r
+            WHERE
+                r.variant_id = s.variant_id
+            AND
+                r.status = 'active'
+            AND
+                r.expires_at > NOW()
+        ), 0) AS available_quantity
+    FROM
+        tbl_stock s
+    GROUP BY
+        s.variant_id;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+CREATE OR REPLACE VIEW vw_variant_stock AS
+    SELECT
+        variant_id,
+        SUM(on_hand) AS on_hand,
+        SUM(on_hand) AS available_quantity
+    FROM
+        tbl_stock
+    GROUP BY
+        variant_id;
+
+DROP TABLE IF EXISTS tbl_stock_reservation;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- Prices are stored as integer minor units (paise, cents) of the variant currency
+ALTER TABLE tbl_variant ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'INR';
+ALTER TABLE tbl_variant ALTER COLUMN max_retail_price TYPE BIGINT USING ROUND(max_retail_price * 100);
+ALTER TABLE tbl_variant ALTER COLUMN discount_price TYPE BIGINT USING ROUND(discount_price * 100);
+ALTER TABLE tbl_variant ADD CONSTRAINT chk_variant_discount_price
+    CHECK (discount_price IS NULL OR discount_price <= max_retail_price) NOT VALID;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE tbl_variant DROP CONSTRAINT IF EXISTS chk_variant_discount_price;
+ALTER TABLE tbl_variant ALTER COLUMN discount_price TYPE FLOAT USING discount_price / 100.0;
+ALTER TABLE tbl_variant ALTER COLUMN max_retail_price TYPE FLOAT USING max_retail_price / 100.0;
+ALTER TABLE tbl_variant DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE IF NOT EXISTS tbl_variant_price (
+    variant_price_id SERIAL,
+    variant_id INT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    channel VARCHAR(20) NOT NULL DEFAULT '',
+    max_retail_price BIGINT NOT NULL,
+    discount_price BIGINT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (variant_price_id),
+    FOREIGN KEY (variant_id) REFERENCES tbl_variant(variant_id) ON DELETE CASCADE,
+    UNIQUE (variant_id, currency, channel),
+    CHECK (discount_price IS NULL OR discount_price <= max_retail_price)
+);
+
+-- rate is the number of units of the currency for one unit of the base currency
+CREATE TABLE IF NOT EXISTS tbl_currency_rate (
+    currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (currency),
+    CHECK (rate > 0)
+);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS tbl_currency_rate;
+DROP TABLE IF EXISTS tbl_variant_price;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE tbl_variant ADD COLUMN discount_valid_from TIMESTAMPTZ;
+ALTER TABLE tbl_variant ADD COLUMN discount_valid_until TIMESTAMPTZ;
+ALTER TABLE tbl_variant_price ADD COLUMN discount_valid_from TIMESTAMPTZ;
+ALTER TABLE tbl_variant_price ADD COLUMN discount_valid_until TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS tbl_price_schedule (
+    schedule_id SERIAL,
+    variant_id INT NOT NULL,
+    max_retail_price BIGINT NOT NULL,
+    discount_price BIGINT,
+    currency CHAR(3) NOT NULL,
+    discount_valid_from TIMESTAMPTZ,
+    discount_valid_until TIMESTAMPTZ,
+    effective_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    applied_at TIMESTAMPTZ,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (schedule_id),
+    FOREIGN KEY (variant_id) REFERENCES tbl_variant(variant_id) ON DELETE CASCADE,
+    CHECK (discount_price IS NULL OR discount_price <= max_retail_price)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_schedule_pending ON tbl_price_schedule (effective_at) WHERE status = 'pending';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS tbl_price_schedule;
+ALTER TABLE tbl_variant_price DROP COLUMN IF EXISTS discount_valid_until;
+ALTER TABLE tbl_variant_price DROP COLUMN IF EXISTS discount_valid_from;
+ALTER TABLE tbl_variant DROP COLUMN IF EXISTS discount_valid_until;
+ALTER TABLE tbl_variant DROP COLUMN IF EXISTS discount_valid_from;
@@ -0,0 +1,112 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE IF NOT EXISTS tbl_price_history (
+    history_id SERIAL,
+    variant_id INT NOT NULL,
+    old_currency CHAR(3),
+    old_max_retail_price BIGINT,
+    old_discount_price BIGINT,
+    new_currency CHAR(3) NOT NULL,
+    new_max_retail_price BIGINT NOT NULL,
+    new_discount_price BIGINT,
+    source VARCHAR(20) NOT NULL,
+    changed_by VARCHAR(100),
+    changed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (history_id),
+    FOREIGN KEY (variant_id) REFERENCES tbl_variant(variant_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_variant ON tbl_price_history (variant_id, changed_at);
+
+-- the current prices are the starting point of the history
+INSERT INTO tbl_price_history (variant_id, new_currency, new_max_retail_price, new_discount_price, source, changed_at)
+    SELECT
+        variant_id, currency, max_retail_price, discount_price, 'migration', NOW()
+    FROM
+        tbl_variant
+    WHERE
+        deleted_at IS NULL;
+
+-- the price changes of tbl_variant are recorded by the trigger, the application sets the source
+-- and the user of the change with set_config in the transaction making it
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_variant_price_history() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.max_retail_price IS NOT DISTINCT FROM OLD.max_retail_price
+        AND NEW.discount_price IS NOT DISTINCT FROM OLD.discount_price
+        AND NEW.currency IS NOT DISTINCT FROM OLD.currency THEN
+        RETURN NEW;
+    END IF;
+    INSERT INTO tbl_price_history (
+        variant_id, old_currency, old_max_retail_price, old_discount_price,
+        new_currency, new_max_retail_price, new_discount_price, source, changed_by, changed_at
+    ) VALUES (
+        NEW.variant_id,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.currency END,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.max_retail_price END,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.discount_price END,
+        NEW.currency,
+        NEW.max_retail_price,
+        NEW.discount_price,
+        COALESCE(NULLIF(current_setting('ecommerce.price_source', true), ''), 'database'),
+        NULLIF(current_setting('ecommerce.changed_by', true), ''),
+        NOW()
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_variant_price_history
+    AFTER INSERT OR UPDATE OF max_retail_price, discount_price, currency ON tbl_variant
+    FOR EACH ROW EXECUTE PROCEDURE fn_variant_price_history();
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_price_history_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'tbl_price_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_price_history_append_only
+    BEFORE UPDATE ON tbl_price_history
+    FOR EACH ROW EXECUTE PROCEDURE fn_price_history_append_only();
+
+-- lowest price of each variant in the last 30 days, including the price in effect when the period started
+CREATE OR REPLACE VIEW vw_variant_lowest_price_30d AS
+    SELECT
+        h.variant_id,
+        h.new_currency AS currency,
+        MIN(COALESCE(h.new_discount_price, h.new_max_retail_price)) AS lowest_price
+    FROM
+        tbl_price_history h
+    WHERE
+        h.changed_at >= NOW() - INTERVAL '30 days'
+    OR
+        h.history_id = (
+            SELECT
+                p.history_id
+            FROM
+                tbl_price_history p
+            WHERE
+                p.variant_id = h.variant_id
+            AND
+                p.changed_at < NOW() - INTERVAL '30 days'
+            ORDER BY
+                p.changed_at DESC,
+                p.history_id DESC
+            LIMIT 1
+        )
+    GROUP BY
+        h.variant_id,
+        h.new_currency;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP VIEW IF EXISTS vw_variant_lowest_price_30d;
+DROP TRIGGER IF EXISTS trg_variant_price_history ON tbl_variant;
+DROP FUNCTION IF EXISTS fn_variant_price_history();
+DROP TABLE IF EXISTS tbl_price_history;
+DROP FUNCTION IF EXISTS fn_price_history_append_only();
@@ -0,0 +1,32 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE IF NOT EXISTS tbl_promotion (
+    promotion_id SERIAL,
+    name VARCHAR(100) NOT NULL,
+    rule_type VARCHAR(20) NOT NULL,
+    scope VARCHAR(10) NOT NULL,
+    scope_id INT,
+    percentage INT,
+    amount BIGINT,
+    currency CHAR(3),
+    buy_quantity INT,
+    get_quantity INT,
+    coupon_code VARCHAR(50),
+    valid_from TIMESTAMPTZ,
+    valid_until TIMESTAMPTZ,
+    priority INT NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    PRIMARY KEY (promotion_id),
+    CHECK (rule_type IN ('percentage_off', 'fixed_amount', 'buy_x_get_y')),
+    CHECK (scope IN ('all', 'category', 'product', 'variant')),
+    CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_from < valid_until)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotion_coupon_code ON tbl_promotion (coupon_code) WHERE deleted_at IS NULL;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS tbl_promotion;
@@ -0,0 +1,196 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE IF NOT EXISTS tbl_product_option (
+    option_id SERIAL,
+    product_id INT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    position INT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (option_id),
+    FOREIGN KEY (product_id) REFERENCES tbl_product(product_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_option_name ON tbl_product_option (product_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS tbl_product_option_value (
+    value_id SERIAL,
+    option_id INT NOT NULL,
+    value VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (value_id),
+    FOREIGN KEY (option_id) REFERENCES tbl_product_option(option_id) ON DELETE CASCADE,
+    UNIQUE (option_id, value)
+);
+
+CREATE TABLE IF NOT EXISTS tbl_variant_option_value (
+    variant_id INT NOT NULL,
+    option_id INT NOT NULL,
+    value_id INT NOT NULL,
+    PRIMARY KEY (variant_id, option_id),
+    FOREIGN KEY (variant_id) REFERENCES tbl_variant(variant_id) ON DELETE CASCADE,
+    FOREIGN KEY (option_id) REFERENCES tbl_product_option(option_id) ON DELETE CASCADE,
+    FOREIGN KEY (value_id) REFERENCES tbl_product_option_value(value_id) ON DELETE CASCADE
+);
+
+-- sorted option_id:value_id pairs of the variant, NULL when the variant has no options
+ALTER TABLE tbl_variant ADD COLUMN option_signature TEXT;
+
+-- the size and color of the existing variants become the Size and Color options of their products
+INSERT INTO tbl_product_option (product_id, name, position, created_at, updated_at)
+    SELECT DISTINCT product_id, 'Size', 1, NOW(), NOW() FROM tbl_variant WHERE COALESCE(size, '') <> '';
+INSERT INTO tbl_product_option (product_id, name, position, created_at, updated_at)
+    SELECT DISTINCT product_id, 'Color', 2, NOW(), NOW() FROM tbl_variant WHERE COALESCE(color, '') <> '';
+
+INSERT INTO tbl_product_option_value (option_id, value, created_at)
+    SELECT DISTINCT
+        o.option_id, v.size, NOW()
+    FROM
+        tbl_variant v
+    JOIN
+        tbl_product_option o
+    ON
+        o.product_id = v.product_id
+    AND
+        o.name = 'Size'
+    WHERE
+        COALESCE(v.size, '') <> '';
+INSERT INTO tbl_product_option_value (option_id, value, created_at)
+    SELECT DISTINCT
+        o.option_id, v.color, NOW()
+    FROM
+        tbl_variant v
+    JOIN
+        tbl_product_option o
+    ON
+        o.product_id = v.product_id
+    AND
+        o.name = 'Color'
+    WHERE
+        COALESCE(v.color, '') <> '';
+
+INSERT INTO tbl_variant_option_value (variant_id, option_id, value_id)
+    SELECT
+        v.variant_id, o.option_id, ov.value_id
+    FROM
+        tbl_variant v
+    JOIN
+        tbl_product_option o
+    ON
+        o.product_id = v.product_id
+    AND
+        o.name = 'Size'
+    JOIN
+        tbl_product_option_value ov
+    ON
+        ov.option_id = o.option_id
+    AND
+        ov.value = v.size;
+INSERT INTO tbl_variant_option_value (variant_id, option_id, value_id)
+    SELECT
+        v.variant_id, o.option_id, ov.value_id
+    FROM
+        tbl_variant v
+    JOIN
+        tbl_product_option o
+    ON
+        o.product_id = v.product_id
+    AND
+        o.name = 'Color'
+    JOIN
+        tbl_product_option_value ov
+    ON
+        ov.option_id = o.option_id
+    AND
+        ov.value = v.color;
+
+UPDATE tbl_variant v
+SET
+    option_signature = s.signature
+FROM (
+    SELECT
+        variant_id, string_agg(option_id || ':' || value_id, ',' ORDER BY option_id) AS signature
+    FROM
+        tbl_variant_option_value
+    GROUP BY
+        variant_id
+) s
+WHERE
+    v.variant_id = s.variant_id;
+
+-- existing duplicate combinations can't be told apart, only the oldest variant keeps its signature
+UPDATE tbl_variant v
+SET
+    option_signature = NULL
+WHERE
+    v.deleted_at IS NULL
+AND
+    EXISTS (
+        SELECT
+            1
+        FROM
+            tbl_variant d
+        WHERE
+            d.product_id = v.product_id
+        AND
+            d.option_signature = v.option_signature
+        AND
+            d.deleted_at IS NULL
+        AND
+            d.variant_id < v.variant_id
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_variant_option_signature ON tbl_variant (product_id, option_signature) WHERE deleted_at IS NULL;
+
+CREATE OR REPLACE VIEW vw_variant_option AS
+    SELECT
+        vo.variant_id,
+        o.option_id,
+        o.name AS option_name,
+        o.position,
+        ov.value_id,
+        ov.value
+    FROM
+        tbl_variant_option_value vo
+    JOIN
+        tbl_product_option o
+    ON
+        o.option_id = vo.option_id
+    JOIN
+        tbl_product_option_value ov
+    ON
+        ov.value_id = vo.value_id;
+
+-- size and color of the variants for the responses which still carry them
+CREATE OR REPLACE VIEW vw_variant_legacy_option AS
+    SELECT
+        variant_id,
+        MAX(value) FILTER (WHERE lower(option_name) = 'size') AS size,
+        MAX(value) FILTER (WHERE lower(option_name) = 'color') AS color
+    FROM
+        vw_variant_option
+    GROUP BY
+        variant_id;
+
+ALTER TABLE tbl_variant DROP COLUMN size;
+ALTER TABLE tbl_variant DROP COLUMN color;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE tbl_variant ADD COLUMN size VARCHAR(10);
+ALTER TABLE tbl_variant ADD COLUMN color VARCHAR(15);
+UPDATE tbl_variant v
+SET
+    size = LEFT(lo.size, 10),
+    color = LEFT(lo.color, 15)
+FROM
+    vw_variant_legacy_option lo
+WHERE
+    lo.variant_id = v.variant_id;
+DROP VIEW IF EXISTS vw_variant_legacy_option;
+DROP VIEW IF EXISTS vw_variant_option;
+DROP INDEX IF EXISTS idx_variant_option_signature;
+ALTER TABLE tbl_variant DROP COLUMN IF EXISTS option_signature;
+DROP TABLE IF EXISTS tbl_variant_option_value;
+DROP TABLE IF EXISTS tbl_product_option_value;
+DROP TABLE IF EXISTS tbl_product_option;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE tbl_variant ADD COLUMN sku VARCHAR(64);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+ALTER TABLE tbl_variant DROP COLUMN IF EXISTS sku;
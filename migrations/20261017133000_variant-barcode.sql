@@ -0,0 +1,37 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- GTIN-8, UPC-A, EAN-13 or GTIN-14 barcode of the variant
+ALTER TABLE tbl_variant ADD COLUMN barcode VARCHAR(14);
+
+-- only the oldest of the live variants sharing a SKU keeps it
+UPDATE
+    tbl_variant v
+SET
+    sku = NULL
+WHERE
+    v.deleted_at IS NULL
+AND
+    EXISTS (
+        SELECT
+            1
+        FROM
+            tbl_variant o
+        WHERE
+            o.sku = v.sku
+        AND
+            o.deleted_at IS NULL
+        AND
+            o.variant_id < v.variant_id
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_variant_sku ON tbl_variant (sku) WHERE deleted_at IS NULL AND sku IS NOT NULL;
+
+-- a UPC-A code and the same code as EAN-13 or GTIN-14 with leading zeros identify the same item
+CREATE UNIQUE INDEX IF NOT EXISTS idx_variant_barcode ON tbl_variant (lpad(barcode, 14, '0'))
+    WHERE deleted_at IS NULL AND barcode IS NOT NULL;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX IF EXISTS idx_variant_barcode;
+DROP INDEX IF EXISTS idx_variant_sku;
+ALTER TABLE tbl_variant DROP COLUMN IF EXISTS barcode;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE TABLE IF NOT EXISTS tbl_category_attribute (
+    attribute_id SERIAL,
+    category_id INT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('string', 'int', 'float', 'bool', 'enum')),
+    unit VARCHAR(20),
+    enum_values TEXT[],
+    is_required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (attribute_id),
+    FOREIGN KEY (category_id) REFERENCES tbl_category(category_id) ON DELETE CASCADE,
+    CHECK ((type = 'enum') = (COALESCE(array_length(enum_values, 1), 0) > 0))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_attribute_name ON tbl_category_attribute (category_id, lower(name));
+
+-- value_text holds the canonical text of every value, value_number the int and float values for range filters
+CREATE TABLE IF NOT EXISTS tbl_product_attribute (
+    product_id INT NOT NULL,
+    attribute_id INT NOT NULL,
+    value_text TEXT NOT NULL,
+    value_number DOUBLE PRECISION,
+    PRIMARY KEY (product_id, attribute_id),
+    FOREIGN KEY (product_id) REFERENCES tbl_product(product_id) ON DELETE CASCADE,
+    FOREIGN KEY (attribute_id) REFERENCES tbl_category_attribute(attribute_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_attribute_text ON tbl_product_attribute (attribute_id, lower(value_text));
+CREATE INDEX IF NOT EXISTS idx_product_attribute_number ON tbl_product_attribute (attribute_id, value_number);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS tbl_product_attribute;
+DROP TABLE IF EXISTS tbl_category_attribute;
@@ -0,0 +1,109 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- weighted lexemes of the product name (A), the names of its category and the ancestors (B), the names
+-- of its live variants (C) and its description (D)
+ALTER TABLE tbl_product ADD COLUMN search_vector TSVECTOR;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_refresh_product_search(p_product_id INT) RETURNS VOID AS $$
+BEGIN
+    UPDATE
+        tbl_product p
+    SET
+        search_vector =
+            setweight(to_tsvector('english', COALESCE(p.name, '')), 'A') ||
+            setweight(to_tsvector('english', COALESCE((
+                WITH RECURSIVE ancestry AS (
+                    SELECT category_id, name, parent_category_id, ARRAY[category_id] AS path
+                    FROM tbl_category WHERE category_id = p.category_id
+                    UNION ALL
+                    SELECT c.category_id, c.name, c.parent_category_id, a.path || c.category_id
+                    FROM tbl_category c JOIN ancestry a ON c.category_id = a.parent_category_id
+                    WHERE NOT c.category_id = ANY(a.path)
+                )
+                SELECT string_agg(name, ' ') FROM ancestry
+            ), '')), 'B') ||
+            setweight(to_tsvector('english', COALESCE((
+                SELECT string_agg(v.name, ' ') FROM tbl_variant v
+                WHERE v.product_id = p.product_id AND v.deleted_at IS NULL
+            ), '')), 'C') ||
+            setweight(to_tsvector('english', COALESCE(p.description, '')), 'D')
+    WHERE
+        p.product_id = p_product_id;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_product_search() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM fn_refresh_product_search(NEW.product_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_variant_search() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.product_id <> NEW.product_id THEN
+        PERFORM fn_refresh_product_search(OLD.product_id);
+    END IF;
+    PERFORM fn_refresh_product_search(NEW.product_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_category_search() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM
+        fn_refresh_product_search(p.product_id)
+    FROM
+        tbl_product p
+    WHERE
+        p.category_id IN (
+            WITH RECURSIVE subtree AS (
+                SELECT NEW.category_id AS category_id
+                UNION
+                SELECT c.category_id FROM tbl_category c JOIN subtree s ON c.parent_category_id = s.category_id
+            )
+            SELECT category_id FROM subtree
+        );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_product_search
+    AFTER INSERT OR UPDATE OF name, description, category_id ON tbl_product
+    FOR EACH ROW EXECUTE PROCEDURE fn_product_search();
+
+CREATE TRIGGER trg_variant_search
+    AFTER INSERT OR UPDATE OF name, product_id, deleted_at ON tbl_variant
+    FOR EACH ROW EXECUTE PROCEDURE fn_variant_search();
+
+CREATE TRIGGER trg_category_search
+    AFTER UPDATE OF name, parent_category_id ON tbl_category
+    FOR EACH ROW EXECUTE PROCEDURE fn_category_search();
+
+SELECT fn_refresh_product_search(product_id) FROM tbl_product;
+
+CREATE INDEX IF NOT EXISTS idx_product_search_vector ON tbl_product USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_product_name_trgm ON tbl_product USING GIN (lower(name) gin_trgm_ops);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX IF EXISTS idx_product_name_trgm;
+DROP INDEX IF EXISTS idx_product_search_vector;
+DROP TRIGGER IF EXISTS trg_category_search ON tbl_category;
+DROP TRIGGER IF EXISTS trg_variant_search ON tbl_variant;
+DROP TRIGGER IF EXISTS trg_product_search ON tbl_product;
+DROP FUNCTION IF EXISTS fn_category_search();
+DROP FUNCTION IF EXISTS fn_variant_search();
+DROP FUNCTION IF EXISTS fn_product_search();
+DROP FUNCTION IF EXISTS fn_refresh_product_search(INT);
+ALTER TABLE tbl_product DROP COLUMN IF EXISTS search_vector;
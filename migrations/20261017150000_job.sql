@@ -0,0 +1,37 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- payload holds the uploaded file, processed_rows the rows applied so far so a job resumes after a restart
+CREATE TABLE IF NOT EXISTS tbl_job (
+    job_id SERIAL,
+    type VARCHAR(20) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    payload BYTEA NOT NULL,
+    changed_by VARCHAR(100),
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    created_rows INT NOT NULL DEFAULT 0,
+    updated_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (job_id),
+    CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_pending ON tbl_job (job_id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS tbl_job_error (
+    job_id INT NOT NULL,
+    row_number INT NOT NULL,
+    message TEXT NOT NULL,
+    PRIMARY KEY (job_id, row_number),
+    FOREIGN KEY (job_id) REFERENCES tbl_job(job_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS tbl_job_error;
+DROP TABLE IF EXISTS tbl_job;
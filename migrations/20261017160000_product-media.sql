@@ -0,0 +1,48 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- position orders the gallery of the product, image_url of tbl_product holds the url of the primary media
+CREATE TABLE IF NOT EXISTS tbl_product_media (
+    media_id SERIAL,
+    product_id INT NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    alt_text VARCHAR(160),
+    position INT NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (media_id),
+    FOREIGN KEY (product_id) REFERENCES tbl_product(product_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_media_product ON tbl_product_media (product_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_media_primary ON tbl_product_media (product_id) WHERE is_primary;
+
+-- the media shown for a variant, e.g. the images of a color given to each variant of the color
+CREATE TABLE IF NOT EXISTS tbl_variant_media (
+    media_id INT NOT NULL,
+    variant_id INT NOT NULL,
+    PRIMARY KEY (media_id, variant_id),
+    FOREIGN KEY (media_id) REFERENCES tbl_product_media(media_id) ON DELETE CASCADE,
+    FOREIGN KEY (variant_id) REFERENCES tbl_variant(variant_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_variant_media_variant ON tbl_variant_media (variant_id);
+
+ALTER TABLE tbl_product ALTER COLUMN image_url TYPE VARCHAR(500);
+
+INSERT INTO
+    tbl_product_media (product_id, url, position, is_primary, created_at, updated_at)
+SELECT
+    product_id, image_url, 1, TRUE, NOW(), NOW()
+FROM
+    tbl_product
+WHERE
+    image_url IS NOT NULL
+AND
+    image_url <> '';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP TABLE IF EXISTS tbl_variant_media;
+DROP TABLE IF EXISTS tbl_product_media;
+ALTER TABLE tbl_product ALTER COLUMN image_url TYPE VARCHAR(160) USING left(image_url, 160);
@@ -0,0 +1,230 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+ALTER TABLE tbl_price_history ADD COLUMN old_discount_valid_from TIMESTAMPTZ;
+ALTER TABLE tbl_price_history ADD COLUMN old_discount_valid_until TIMESTAMPTZ;
+ALTER TABLE tbl_price_history ADD COLUMN new_discount_valid_from TIMESTAMPTZ;
+ALTER TABLE tbl_price_history ADD COLUMN new_discount_valid_until TIMESTAMPTZ;
+
+-- the append-only trigger is recreated below, the latest change of each variant takes the current
+-- validity window of its discount
+DROP TRIGGER IF EXISTS trg_price_history_append_only ON tbl_price_history;
+
+UPDATE tbl_price_history h SET
+    new_discount_valid_from = v.discount_valid_from,
+    new_discount_valid_until = v.discount_valid_until
+FROM
+    tbl_variant v
+WHERE
+    v.variant_id = h.variant_id
+AND
+    h.history_id = (
+        SELECT
+            p.history_id
+        FROM
+            tbl_price_history p
+        WHERE
+            p.variant_id = h.variant_id
+        ORDER BY
+            p.changed_at DESC,
+            p.history_id DESC
+        LIMIT 1
+    );
+
+-- history rows can neither be changed nor removed, variants are soft deleted so the cascade of
+-- their foreign key is never expected to fire
+CREATE TRIGGER trg_price_history_append_only
+    BEFORE UPDATE OR DELETE ON tbl_price_history
+    FOR EACH ROW EXECUTE PROCEDURE fn_price_history_append_only();
+
+CREATE TRIGGER trg_price_history_no_truncate
+    BEFORE TRUNCATE ON tbl_price_history
+    FOR EACH STATEMENT EXECUTE PROCEDURE fn_price_history_append_only();
+
+-- a change of the validity window changes the price to pay as much as a change of the prices
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_variant_price_history() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.max_retail_price IS NOT DISTINCT FROM OLD.max_retail_price
+        AND NEW.discount_price IS NOT DISTINCT FROM OLD.discount_price
+        AND NEW.discount_valid_from IS NOT DISTINCT FROM OLD.discount_valid_from
+        AND NEW.discount_valid_until IS NOT DISTINCT FROM OLD.discount_valid_until
+        AND NEW.currency IS NOT DISTINCT FROM OLD.currency THEN
+        RETURN NEW;
+    END IF;
+    INSERT INTO tbl_price_history (
+        variant_id, old_currency, old_max_retail_price, old_discount_price, old_discount_valid_from,
+        old_discount_valid_until, new_currency, new_max_retail_price, new_discount_price,
+        new_discount_valid_from, new_discount_valid_until, source, changed_by, changed_at
+    ) VALUES (
+        NEW.variant_id,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.currency END,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.max_retail_price END,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.discount_price END,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.discount_valid_from END,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.discount_valid_until END,
+        NEW.currency,
+        NEW.max_retail_price,
+        NEW.discount_price,
+        NEW.discount_valid_from,
+        NEW.discount_valid_until,
+        COALESCE(NULLIF(current_setting('ecommerce.price_source', true), ''), 'database'),
+        NULLIF(current_setting('ecommerce.changed_by', true), ''),
+        NOW()
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS trg_variant_price_history ON tbl_variant;
+
+CREATE TRIGGER trg_variant_price_history
+    AFTER INSERT OR UPDATE OF max_retail_price, discount_price, discount_valid_from, discount_valid_until, currency
+    ON tbl_variant
+    FOR EACH ROW EXECUTE PROCEDURE fn_variant_price_history();
+
+-- lowest price to pay of each variant in the last 30 days. Every change is in effect until the next
+-- one, within that span the discount price counts for the part its validity window covers and the
+-- max retail price for the rest, the change in effect now counts even when it was made just now
+CREATE OR REPLACE VIEW vw_variant_lowest_price_30d AS
+    WITH span AS (
+        SELECT
+            h.variant_id,
+            h.new_currency AS currency,
+            h.new_max_retail_price AS max_retail_price,
+            h.new_discount_price AS discount_price,
+            h.new_discount_valid_from AS valid_from,
+            h.new_discount_valid_until AS valid_until,
+            GREATEST(h.changed_at, NOW() - INTERVAL '30 days') AS starts_at,
+            LEAD(h.changed_at) OVER (PARTITION BY h.variant_id ORDER BY h.changed_at, h.history_id) AS next_change_at
+        FROM
+            tbl_price_history h
+    ),
+    effective AS (
+        SELECT
+            s.variant_id,
+            s.currency,
+            s.max_retail_price,
+            s.discount_price,
+            s.valid_from,
+            s.valid_until,
+            s.starts_at,
+            COALESCE(s.next_change_at, NOW()) AS ends_at,
+            s.next_change_at IS NULL AS is_current
+        FROM
+            span s
+    )
+    SELECT
+        e.variant_id,
+        e.currency,
+        MIN(p.price) AS lowest_price
+    FROM
+        effective e
+    CROSS JOIN LATERAL (
+        -- the discount price when its window overlaps the span
+        SELECT
+            e.discount_price
+        WHERE
+            e.discount_price IS NOT NULL
+        AND
+            (e.valid_from IS NULL OR e.valid_from <= e.starts_at OR e.valid_from < e.ends_at)
+        AND
+            (e.valid_until IS NULL OR e.valid_until > e.starts_at)
+        UNION ALL
+        -- the max retail price unless the window covers the whole span
+        SELECT
+            e.max_retail_price
+        WHERE
+            NOT (
+                e.discount_price IS NOT NULL
+            AND
+                (e.valid_from IS NULL OR e.valid_from <= e.starts_at)
+            AND
+                (e.valid_until IS NULL OR (e.valid_until > e.starts_at AND e.valid_until >= e.ends_at))
+            )
+    ) p(price)
+    WHERE
+        e.ends_at > e.starts_at
+    OR
+        (e.is_current AND e.ends_at >= e.starts_at)
+    GROUP BY
+        e.variant_id,
+        e.currency;
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+CREATE OR REPLACE VIEW vw_variant_lowest_price_30d AS
+    SELECT
+        h.variant_id,
+        h.new_currency AS currency,
+        MIN(COALESCE(h.new_discount_price, h.new_max_retail_price)) AS lowest_price
+    FROM
+        tbl_price_history h
+    WHERE
+        h.changed_at >= NOW() - INTERVAL '30 days'
+    OR
+        h.history_id = (
+            SELECT
+                p.history_id
+            FROM
+                tbl_price_history p
+            WHERE
+                p.variant_id = h.variant_id
+            AND
+                p.changed_at < NOW() - INTERVAL '30 days'
+            ORDER BY
+                p.changed_at DESC,
+                p.history_id DESC
+            LIMIT 1
+        )
+    GROUP BY
+        h.variant_id,
+        h.new_currency;
+
+DROP TRIGGER IF EXISTS trg_variant_price_history ON tbl_variant;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_variant_price_history() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.max_retail_price IS NOT DISTINCT FROM OLD.max_retail_price
+        AND NEW.discount_price IS NOT DISTINCT FROM OLD.discount_price
+        AND NEW.currency IS NOT DISTINCT FROM OLD.currency THEN
+        RETURN NEW;
+    END IF;
+    INSERT INTO tbl_price_history (
+        variant_id, old_currency, old_max_retail_price, old_discount_price,
+        new_currency, new_max_retail_price, new_discount_price, source, changed_by, changed_at
+    ) VALUES (
+        NEW.variant_id,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.currency END,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.max_retail_price END,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.discount_price END,
+        NEW.currency,
+        NEW.max_retail_price,
+        NEW.discount_price,
+        COALESCE(NULLIF(current_setting('ecommerce.price_source', true), ''), 'database'),
+        NULLIF(current_setting('ecommerce.changed_by', true), ''),
+        NOW()
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_variant_price_history
+    AFTER INSERT OR UPDATE OF max_retail_price, discount_price, currency ON tbl_variant
+    FOR EACH ROW EXECUTE PROCEDURE fn_variant_price_history();
+
+DROP TRIGGER IF EXISTS trg_price_history_no_truncate ON tbl_price_history;
+DROP TRIGGER IF EXISTS trg_price_history_append_only ON tbl_price_history;
+
+CREATE TRIGGER trg_price_history_append_only
+    BEFORE UPDATE ON tbl_price_history
+    FOR EACH ROW EXECUTE PROCEDURE fn_price_history_append_only();
+
+ALTER TABLE tbl_price_history DROP COLUMN IF EXISTS new_discount_valid_until;
+ALTER TABLE tbl_price_history DROP COLUMN IF EXISTS new_discount_valid_from;
+ALTER TABLE tbl_price_history DROP COLUMN IF EXISTS old_discount_valid_until;
+ALTER TABLE tbl_price_history DROP COLUMN IF EXISTS old_discount_valid_from;
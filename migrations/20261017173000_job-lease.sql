@@ -0,0 +1,17 @@
+-- +goose Up
+-- SQL in this section is executed when the migration is applied.
+-- a running job is leased to the worker in locked_by until locked_until, the worker renews the lease
+-- while it runs the job and another worker claims the job once the lease has expired
+ALTER TABLE tbl_job ADD COLUMN locked_by VARCHAR(100);
+ALTER TABLE tbl_job ADD COLUMN locked_until TIMESTAMPTZ;
+
+-- the jobs left running before the lease existed can be claimed right away
+UPDATE tbl_job SET locked_until = NOW() WHERE status = 'running';
+
+CREATE INDEX IF NOT EXISTS idx_job_lease ON tbl_job (locked_until) WHERE status = 'running';
+
+-- +goose Down
+-- SQL in this section is executed when the migration is rolled back.
+DROP INDEX IF EXISTS idx_job_lease;
+ALTER TABLE tbl_job DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE tbl_job DROP COLUMN IF EXISTS locked_by;
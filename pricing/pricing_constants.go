@@ -0,0 +1,21 @@
+package pricing
+
+import "time"
+
+const (
+	//DefaultChannel channel of the prices which apply to every sales channel
+	DefaultChannel = ""
+	//BaseCurrencyEnv environment variable to configure the base currency
+	BaseCurrencyEnv = "BaseCurrency"
+	//SchedulePending status of a scheduled price change waiting for its effective time
+	SchedulePending = "pending"
+	//ScheduleApplied status of a scheduled price change written to the variant
+	ScheduleApplied = "applied"
+	//ScheduleCancelled status of a cancelled scheduled price change
+	ScheduleCancelled = "cancelled"
+	//PriceSourceSchedule price history source of the price changes applied by the scheduler
+	PriceSourceSchedule = "schedule"
+)
+
+//ApplyInterval interval at which the due scheduled price changes are applied
+const ApplyInterval = time.Minute
@@ -0,0 +1,282 @@
+package pricing
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+//HandlerInterface for price list management
+type HandlerInterface interface {
+	UpsertPrice(http.ResponseWriter, *http.Request)
+	DeletePrice(http.ResponseWriter, *http.Request)
+	ListPrice(http.ResponseWriter, *http.Request)
+	UpsertRate(http.ResponseWriter, *http.Request)
+	ListRate(http.ResponseWriter, *http.Request)
+	CreateSchedule(http.ResponseWriter, *http.Request)
+	ListSchedule(http.ResponseWriter, *http.Request)
+	CancelSchedule(http.ResponseWriter, *http.Request)
+}
+
+//Handler struct for price list management
+type Handler struct {
+	cs ServiceInterface
+}
+
+//NewHTTPHandler to handle price list requests
+func NewHTTPHandler(db *sql.DB) HandlerInterface {
+	return &Handler{
+		cs: NewService(db),
+	}
+}
+
+//isRequestError to check if the error is caused by the request
+func isRequestError(err error) bool {
+	switch err.Error() {
+	case utils.VariantIDNotExist, utils.PriceNotExist, utils.InvalidRateError, utils.RateNotExist,
+		utils.InvalidCurrencyError, utils.InvalidPriceError, utils.CurrencyMismatchError, utils.DiscountExceedsMRPError,
+		utils.InvalidDiscountWindowError, utils.InvalidEffectiveAtError, utils.ScheduleNotExist, utils.ScheduleNotPending:
+		return true
+	}
+	return false
+}
+
+//UpsertPrice to handle the variant price put request
+func (h *Handler) UpsertPrice(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/prices PUT API")
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (UpsertPrice)")
+		utils.Fail(w, 400, utils.InvalidVariantID)
+		return
+	}
+	var request PriceRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(UpsertPrice) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(UpsertPrice) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.VariantID = variantID
+	price, err := h.cs.UpsertPrice(&request)
+	if err != nil {
+		log.Println("Error : Price update error(UpsertPrice) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Variant price updated successfully, variant id =", variantID)
+	utils.Send(w, 200, price)
+}
+
+//DeletePrice to handle the variant price delete request
+func (h *Handler) DeletePrice(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/prices/{currency} DELETE API")
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (DeletePrice)")
+		utils.Fail(w, 400, utils.InvalidVariantID)
+		return
+	}
+	request := DeletePriceRequest{
+		VariantID: variantID,
+		Currency:  chi.URLParam(r, "currency"),
+		Channel:   r.URL.Query().Get("channel"),
+	}
+	err = h.cs.DeletePrice(&request)
+	if err != nil {
+		log.Println("Error : Price delete error(DeletePrice) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	message := utils.Message{
+		Message: fmt.Sprintf("Variant price deleted successfully, variant id = %d, currency = %s", variantID, request.Currency),
+	}
+	log.Println(message.Message)
+	utils.Send(w, 200, &message)
+}
+
+//ListPrice to handle the variant price list request
+func (h *Handler) ListPrice(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/prices GET API")
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (ListPrice)")
+		utils.Fail(w, 400, utils.InvalidVariantID)
+		return
+	}
+	prices, err := h.cs.ListPrice(variantID)
+	if err != nil {
+		log.Println("Error : Price listing error(ListPrice) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Variant prices listed successfully, variant id =", variantID)
+	utils.Send(w, 200, prices)
+}
+
+//UpsertRate to handle the conversion rate put request
+func (h *Handler) UpsertRate(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /currency-rates/{currency} PUT API")
+	var request RateRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(UpsertRate) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(UpsertRate) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.Currency = chi.URLParam(r, "currency")
+	rate, err := h.cs.UpsertRate(&request)
+	if err != nil {
+		log.Println("Error : Rate update error(UpsertRate) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Conversion rate updated successfully, currency =", rate.Currency)
+	utils.Send(w, 200, rate)
+}
+
+//ListRate to handle the conversion rate list request
+func (h *Handler) ListRate(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /currency-rates GET API")
+	rates, err := h.cs.ListRate()
+	if err != nil {
+		log.Println("Error : Rate listing error(ListRate) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Conversion rates listed successfully")
+	utils.Send(w, 200, rates)
+}
+
+//CreateSchedule to handle the scheduled price change post request
+func (h *Handler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/price-schedules POST API")
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (CreateSchedule)")
+		utils.Fail(w, 400, utils.InvalidVariantID)
+		return
+	}
+	var request ScheduleRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(CreateSchedule) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(CreateSchedule) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.VariantID = variantID
+	schedule, err := h.cs.CreateSchedule(&request)
+	if err != nil {
+		log.Println("Error : Price schedule error(CreateSchedule) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Price change scheduled successfully, schedule id =", schedule.ID)
+	utils.Send(w, 200, schedule)
+}
+
+//ListSchedule to handle the scheduled price change list request
+func (h *Handler) ListSchedule(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/price-schedules GET API")
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (ListSchedule)")
+		utils.Fail(w, 400, utils.InvalidVariantID)
+		return
+	}
+	schedules, err := h.cs.ListSchedule(variantID)
+	if err != nil {
+		log.Println("Error : Price schedule listing error(ListSchedule) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Scheduled price changes listed successfully, variant id =", variantID)
+	utils.Send(w, 200, schedules)
+}
+
+//CancelSchedule to handle the scheduled price change delete request
+func (h *Handler) CancelSchedule(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/price-schedules/{schedule_id} DELETE API")
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (CancelSchedule)")
+		utils.Fail(w, 400, utils.InvalidVariantID)
+		return
+	}
+	scheduleID, err := strconv.Atoi(chi.URLParam(r, "schedule_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (CancelSchedule)")
+		utils.Fail(w, 400, utils.ScheduleNotExist)
+		return
+	}
+	err = h.cs.CancelSchedule(&ScheduleAction{
+		VariantID:  variantID,
+		ScheduleID: scheduleID,
+	})
+	if err != nil {
+		log.Println("Error : Price schedule cancel error(CancelSchedule) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	message := utils.Message{
+		Message: fmt.Sprintf("Scheduled price change cancelled successfully, schedule id = %d", scheduleID),
+	}
+	log.Println(message.Message)
+	utils.Send(w, 200, &message)
+}
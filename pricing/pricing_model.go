@@ -0,0 +1,104 @@
+package pricing
+
+import (
+	"ecommerce/utils"
+	"encoding/json"
+	"time"
+)
+
+//PriceRequest to represent the price list entry upsert request of a variant
+type PriceRequest struct {
+	VariantID          int          `json:"-"`
+	Channel            string       `json:"channel" validate:"max=20"`
+	MRP                utils.Money  `json:"max_retail_price"`
+	DiscountPrice      *utils.Money `json:"discount_price"`
+	DiscountValidFrom  *time.Time   `json:"discount_valid_from"`
+	DiscountValidUntil *time.Time   `json:"discount_valid_until"`
+}
+
+//DeletePriceRequest to represent the price list entry delete request
+type DeletePriceRequest struct {
+	VariantID int
+	Currency  string
+	Channel   string
+}
+
+//VariantPrice to represent a price list entry of a variant
+type VariantPrice struct {
+	VariantID          int          `json:"variant_id"`
+	Channel            string       `json:"channel,omitempty"`
+	MRP                utils.Money  `json:"max_retail_price"`
+	DiscountPrice      *utils.Money `json:"discount_price,omitempty"`
+	DiscountValidFrom  *time.Time   `json:"discount_valid_from,omitempty"`
+	DiscountValidUntil *time.Time   `json:"discount_valid_until,omitempty"`
+}
+
+//RateRequest to represent the conversion rate update request of a currency
+type RateRequest struct {
+	Currency string      `json:"-"`
+	Rate     json.Number `json:"rate" validate:"required"`
+}
+
+//Rate to represent the conversion rate of a currency, the number of units of the
+//currency for one unit of the base currency
+type Rate struct {
+	Currency  string    `json:"currency"`
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+//BasePrice to represent the own price of a variant
+type BasePrice struct {
+	VariantID          int
+	MRP                utils.Money
+	DiscountPrice      *utils.Money
+	DiscountValidFrom  *time.Time
+	DiscountValidUntil *time.Time
+}
+
+//ResolveRequest to represent the currency, channel and time the prices are resolved for,
+//an empty currency keeps the own currency of each variant
+type ResolveRequest struct {
+	Currency string
+	Channel  string
+	AsOf     time.Time
+}
+
+//ResolvedPrice to represent the price of a variant in effect for a resolve request
+type ResolvedPrice struct {
+	MRP                utils.Money
+	DiscountPrice      *utils.Money //nil when no discount is in effect
+	DiscountValidFrom  *time.Time
+	DiscountValidUntil *time.Time
+	EffectivePrice     utils.Money
+	Converted          bool //true when the price is converted from another currency
+}
+
+//ScheduleRequest to represent the scheduled price change request of a variant
+type ScheduleRequest struct {
+	VariantID          int          `json:"-"`
+	EffectiveAt        time.Time    `json:"effective_at" validate:"required"`
+	MRP                utils.Money  `json:"max_retail_price"`
+	DiscountPrice      *utils.Money `json:"discount_price"`
+	DiscountValidFrom  *time.Time   `json:"discount_valid_from"`
+	DiscountValidUntil *time.Time   `json:"discount_valid_until"`
+}
+
+//ScheduleAction to represent the cancel request of a scheduled price change
+type ScheduleAction struct {
+	VariantID  int
+	ScheduleID int
+}
+
+//Schedule to represent a scheduled price change of a variant
+type Schedule struct {
+	ID                 int          `json:"schedule_id"`
+	VariantID          int          `json:"variant_id"`
+	EffectiveAt        time.Time    `json:"effective_at"`
+	MRP                utils.Money  `json:"max_retail_price"`
+	DiscountPrice      *utils.Money `json:"discount_price,omitempty"`
+	DiscountValidFrom  *time.Time   `json:"discount_valid_from,omitempty"`
+	DiscountValidUntil *time.Time   `json:"discount_valid_until,omitempty"`
+	Status             string       `json:"status"`
+	AppliedAt          *time.Time   `json:"applied_at,omitempty"`
+}
@@ -0,0 +1,471 @@
+package pricing
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+//Repo is the DB repository struct
+type Repo struct {
+	DB *sql.DB
+}
+
+//EffectivePriceSQL returns the SQL expression of the price to pay of a price row with the given alias, a
+//tbl_variant or tbl_variant_price row, at the time of asOf: the discount price within its validity window
+//and otherwise the max retail price. It is the SQL counterpart of the resolved effective price
+func EffectivePriceSQL(alias string, asOf string) string {
+	return fmt.Sprintf(`CASE
+		WHEN %[1]s.discount_price IS NOT NULL
+			AND (%[1]s.discount_valid_from IS NULL OR %[1]s.discount_valid_from <= %[2]s)
+			AND (%[1]s.discount_valid_until IS NULL OR %[1]s.discount_valid_until > %[2]s)
+		THEN %[1]s.discount_price
+		ELSE %[1]s.max_retail_price
+	END`, alias, asOf)
+}
+
+func getNullAmount(value *utils.Money) sql.NullInt64 {
+	if value == nil || value.Amount == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{
+		Int64: value.Amount,
+		Valid: true,
+	}
+}
+
+func getMoney(amount sql.NullInt64, currency string) *utils.Money {
+	if !amount.Valid {
+		return nil
+	}
+	return &utils.Money{
+		Amount:   amount.Int64,
+		Currency: currency,
+	}
+}
+
+func getNullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{
+		Time:  *value,
+		Valid: true,
+	}
+}
+
+func getTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+//IsVariantIDExists function to check if the variant ID exists
+func (repo *Repo) IsVariantIDExists(id int) (bool, error) {
+	var count int
+	query := `
+		SELECT
+			count(*)
+		FROM
+			tbl_variant
+		WHERE
+			variant_id = $1
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//UpsertPrice to create or replace the price list entry of a variant for a currency and channel
+func (repo *Repo) UpsertPrice(request *PriceRequest) (*VariantPrice, error) {
+	var price VariantPrice
+	var discountPrice sql.NullInt64
+	var validFrom, validUntil sql.NullTime
+	query := `
+		INSERT INTO
+			tbl_variant_price (variant_id, currency, channel, max_retail_price, discount_price,
+				discount_valid_from, discount_valid_until, created_at, updated_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (variant_id, currency, channel) DO UPDATE
+		SET
+			max_retail_price = EXCLUDED.max_retail_price,
+			discount_price = EXCLUDED.discount_price,
+			discount_valid_from = EXCLUDED.discount_valid_from,
+			discount_valid_until = EXCLUDED.discount_valid_until,
+			updated_at = NOW()
+		RETURNING
+			variant_id, currency, channel, max_retail_price, discount_price, discount_valid_from, discount_valid_until
+	`
+	err := repo.DB.QueryRow(query, request.VariantID, request.MRP.Currency, request.Channel,
+		request.MRP.Amount, getNullAmount(request.DiscountPrice), getNullTime(request.DiscountValidFrom),
+		getNullTime(request.DiscountValidUntil)).Scan(&price.VariantID, &price.MRP.Currency, &price.Channel,
+		&price.MRP.Amount, &discountPrice, &validFrom, &validUntil)
+	if err != nil {
+		return nil, err
+	}
+	price.DiscountPrice = getMoney(discountPrice, price.MRP.Currency)
+	price.DiscountValidFrom = getTime(validFrom)
+	price.DiscountValidUntil = getTime(validUntil)
+	return &price, nil
+}
+
+//DeletePrice to delete the price list entry of a variant
+func (repo *Repo) DeletePrice(request *DeletePriceRequest) error {
+	query := `
+		DELETE FROM
+			tbl_variant_price
+		WHERE
+			variant_id = $1
+		AND
+			currency = $2
+		AND
+			channel = $3
+	`
+	result, err := repo.DB.Exec(query, request.VariantID, request.Currency, request.Channel)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New(utils.PriceNotExist)
+	}
+	return nil
+}
+
+//ListPrice to list the price list entries of the given variants
+func (repo *Repo) ListPrice(variantIDs []int) ([]VariantPrice, error) {
+	var discountPrice sql.NullInt64
+	var validFrom, validUntil sql.NullTime
+	query := `
+		SELECT
+			variant_id, currency, channel, max_retail_price, discount_price, discount_valid_from, discount_valid_until
+		FROM
+			tbl_variant_price
+		WHERE
+			variant_id = ANY($1)
+		ORDER BY
+			variant_id ASC,
+			currency ASC,
+			channel ASC
+	`
+	rows, err := repo.DB.Query(query, pq.Array(variantIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	prices := []VariantPrice{}
+	for rows.Next() {
+		var price VariantPrice
+		err := rows.Scan(&price.VariantID, &price.MRP.Currency, &price.Channel, &price.MRP.Amount, &discountPrice,
+			&validFrom, &validUntil)
+		if err != nil {
+			return nil, err
+		}
+		price.DiscountPrice = getMoney(discountPrice, price.MRP.Currency)
+		price.DiscountValidFrom = getTime(validFrom)
+		price.DiscountValidUntil = getTime(validUntil)
+		prices = append(prices, price)
+	}
+	return prices, rows.Err()
+}
+
+//UpsertRate to create or replace the conversion rate of a currency
+func (repo *Repo) UpsertRate(request *RateRequest) (*Rate, error) {
+	var rate Rate
+	query := `
+		INSERT INTO
+			tbl_currency_rate (currency, rate, updated_at)
+		VALUES
+			($1, $2, NOW())
+		ON CONFLICT (currency) DO UPDATE
+		SET
+			rate = EXCLUDED.rate,
+			updated_at = NOW()
+		RETURNING
+			currency, rate::TEXT, updated_at
+	`
+	err := repo.DB.QueryRow(query, request.Currency, request.Rate.String()).Scan(&rate.Currency, &rate.Rate, &rate.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+//ListRate to list the conversion rates
+func (repo *Repo) ListRate() ([]Rate, error) {
+	query := `
+		SELECT
+			currency, rate::TEXT, updated_at
+		FROM
+			tbl_currency_rate
+		ORDER BY
+			currency ASC
+	`
+	rows, err := repo.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	rates := []Rate{}
+	for rows.Next() {
+		var rate Rate
+		err := rows.Scan(&rate.Currency, &rate.Rate, &rate.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+//rowScanner is implemented by both sql.Row and sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+//scanSchedule to scan a scheduled price change row
+func scanSchedule(row rowScanner) (*Schedule, error) {
+	var schedule Schedule
+	var discountPrice sql.NullInt64
+	var validFrom, validUntil, appliedAt sql.NullTime
+	err := row.Scan(&schedule.ID, &schedule.VariantID, &schedule.EffectiveAt, &schedule.MRP.Amount, &discountPrice,
+		&schedule.MRP.Currency, &validFrom, &validUntil, &schedule.Status, &appliedAt)
+	if err != nil {
+		return nil, err
+	}
+	schedule.DiscountPrice = getMoney(discountPrice, schedule.MRP.Currency)
+	schedule.DiscountValidFrom = getTime(validFrom)
+	schedule.DiscountValidUntil = getTime(validUntil)
+	schedule.AppliedAt = getTime(appliedAt)
+	return &schedule, nil
+}
+
+//CreateSchedule to queue a price change of a variant
+func (repo *Repo) CreateSchedule(request *ScheduleRequest) (*Schedule, error) {
+	query := `
+		INSERT INTO
+			tbl_price_schedule (variant_id, effective_at, max_retail_price, discount_price, currency,
+				discount_valid_from, discount_valid_until, status, created_at, updated_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING
+			schedule_id, variant_id, effective_at, max_retail_price, discount_price, currency,
+			discount_valid_from, discount_valid_until, status, applied_at
+	`
+	row := repo.DB.QueryRow(query, request.VariantID, request.EffectiveAt, request.MRP.Amount,
+		getNullAmount(request.DiscountPrice), request.MRP.Currency, getNullTime(request.DiscountValidFrom),
+		getNullTime(request.DiscountValidUntil), SchedulePending)
+	return scanSchedule(row)
+}
+
+//ListSchedule to list the scheduled price changes of a variant
+func (repo *Repo) ListSchedule(variantID int) ([]Schedule, error) {
+	query := `
+		SELECT
+			schedule_id, variant_id, effective_at, max_retail_price, discount_price, currency,
+			discount_valid_from, discount_valid_until, status, applied_at
+		FROM
+			tbl_price_schedule
+		WHERE
+			variant_id = $1
+		ORDER BY
+			effective_at ASC,
+			schedule_id ASC
+	`
+	rows, err := repo.DB.Query(query, variantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	schedules := []Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, rows.Err()
+}
+
+//CancelSchedule to cancel a pending price change of a variant
+func (repo *Repo) CancelSchedule(request *ScheduleAction) error {
+	var status string
+	query := `
+		SELECT
+			status
+		FROM
+			tbl_price_schedule
+		WHERE
+			schedule_id = $1
+		AND
+			variant_id = $2
+	`
+	err := repo.DB.QueryRow(query, request.ScheduleID, request.VariantID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return errors.New(utils.ScheduleNotExist)
+	}
+	if err != nil {
+		return err
+	}
+	query = `
+		UPDATE
+			tbl_price_schedule
+		SET
+			status = $3,
+			updated_at = NOW()
+		WHERE
+			schedule_id = $1
+		AND
+			status = $2
+	`
+	result, err := repo.DB.Exec(query, request.ScheduleID, SchedulePending, ScheduleCancelled)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		//applied or cancelled, possibly by the scheduler since the status was read
+		return errors.New(utils.ScheduleNotPending)
+	}
+	return nil
+}
+
+//ListDueSchedule to get the latest pending price change of each variant which is due at the given time
+func (repo *Repo) ListDueSchedule(variantIDs []int, asOf time.Time) ([]Schedule, error) {
+	query := `
+		SELECT DISTINCT ON (variant_id)
+			schedule_id, variant_id, effective_at, max_retail_price, discount_price, currency,
+			discount_valid_from, discount_valid_until, status, applied_at
+		FROM
+			tbl_price_schedule
+		WHERE
+			variant_id = ANY($1)
+		AND
+			status = $2
+		AND
+			effective_at <= $3
+		ORDER BY
+			variant_id ASC,
+			effective_at DESC,
+			schedule_id DESC
+	`
+	rows, err := repo.DB.Query(query, pq.Array(variantIDs), SchedulePending, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	schedules := []Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, rows.Err()
+}
+
+//ApplyDueSchedules to write the due pending price changes to their variants in the order they take
+//effect and mark them as applied, returns the number of applied price changes. The changes are
+//recorded in the price history with the schedule source
+func (repo *Repo) ApplyDueSchedules() (int, error) {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	query := `
+		SELECT
+			set_config('ecommerce.price_source', $1, true)
+	`
+	_, err = tx.Exec(query, PriceSourceSchedule)
+	if err != nil {
+		return 0, err
+	}
+	query = `
+		SELECT
+			schedule_id, variant_id, effective_at, max_retail_price, discount_price, currency,
+			discount_valid_from, discount_valid_until, status, applied_at
+		FROM
+			tbl_price_schedule
+		WHERE
+			status = $1
+		AND
+			effective_at <= NOW()
+		ORDER BY
+			effective_at ASC,
+			schedule_id ASC
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.Query(query, SchedulePending)
+	if err != nil {
+		return 0, err
+	}
+	var schedules []Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, v := range schedules {
+		query = `
+			UPDATE
+				tbl_variant
+			SET
+				max_retail_price = $2,
+				discount_price = $3,
+				currency = $4,
+				discount_valid_from = $5,
+				discount_valid_until = $6,
+				updated_at = NOW()
+			WHERE
+				variant_id = $1
+			AND
+				deleted_at IS NULL
+		`
+		_, err = tx.Exec(query, v.VariantID, v.MRP.Amount, getNullAmount(v.DiscountPrice), v.MRP.Currency,
+			getNullTime(v.DiscountValidFrom), getNullTime(v.DiscountValidUntil))
+		if err != nil {
+			return 0, err
+		}
+		query = `
+			UPDATE
+				tbl_price_schedule
+			SET
+				status = $2,
+				applied_at = NOW(),
+				updated_at = NOW()
+			WHERE
+				schedule_id = $1
+		`
+		_, err = tx.Exec(query, v.ID, ScheduleApplied)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(schedules), tx.Commit()
+}
@@ -0,0 +1,28 @@
+package pricing
+
+import (
+	"database/sql"
+	"time"
+)
+
+//RepoInterface for DB operations
+type RepoInterface interface {
+	IsVariantIDExists(int) (bool, error)
+	UpsertPrice(*PriceRequest) (*VariantPrice, error)
+	DeletePrice(*DeletePriceRequest) error
+	ListPrice([]int) ([]VariantPrice, error)
+	UpsertRate(*RateRequest) (*Rate, error)
+	ListRate() ([]Rate, error)
+	CreateSchedule(*ScheduleRequest) (*Schedule, error)
+	ListSchedule(int) ([]Schedule, error)
+	CancelSchedule(*ScheduleAction) error
+	ListDueSchedule([]int, time.Time) ([]Schedule, error)
+	ApplyDueSchedules() (int, error)
+}
+
+//NewRepo returns repository interface
+func NewRepo(db *sql.DB) RepoInterface {
+	return &Repo{
+		DB: db,
+	}
+}
@@ -0,0 +1,59 @@
+package pricing
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+//Scheduler applies the due scheduled price changes in the background
+type Scheduler struct {
+	repo     RepoInterface
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+//NewScheduler returns a scheduler which runs at the given interval
+func NewScheduler(db *sql.DB, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		repo:     NewRepo(db),
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+//Start runs the scheduler in a new goroutine until Stop is called
+func (s *Scheduler) Start() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.apply()
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+//Stop stops the scheduler and waits for the running apply to finish
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+func (s *Scheduler) apply() {
+	applied, err := s.repo.ApplyDueSchedules()
+	if err != nil {
+		log.Println("Error : error applying scheduled price changes(Scheduler) -", err.Error())
+		return
+	}
+	if applied > 0 {
+		log.Println("App : Scheduled price changes applied, count =", applied)
+	}
+}
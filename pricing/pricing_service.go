@@ -0,0 +1,372 @@
+package pricing
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+//ServiceInterface is pricing service interface
+type ServiceInterface interface {
+	UpsertPrice(*PriceRequest) (*VariantPrice, error)
+	DeletePrice(*DeletePriceRequest) error
+	ListPrice(int) ([]VariantPrice, error)
+	UpsertRate(*RateRequest) (*Rate, error)
+	ListRate() ([]Rate, error)
+	ResolvePrices([]BasePrice, *ResolveRequest) (map[int]ResolvedPrice, error)
+	CreateSchedule(*ScheduleRequest) (*Schedule, error)
+	ListSchedule(int) ([]Schedule, error)
+	CancelSchedule(*ScheduleAction) error
+}
+
+//Service struct for service functionalities
+type Service struct {
+	repo RepoInterface
+}
+
+//NewService :
+func NewService(db *sql.DB) ServiceInterface {
+	return &Service{
+		repo: NewRepo(db),
+	}
+}
+
+//BaseCurrency returns the configured base currency of the conversion rates
+func BaseCurrency() string {
+	currency, ok := os.LookupEnv(BaseCurrencyEnv)
+	if !ok || currency == utils.EmptyString {
+		return utils.DefaultCurrency
+	}
+	return strings.ToUpper(currency)
+}
+
+func (service *Service) checkVariant(variantID int) error {
+	isExist, err := service.repo.IsVariantIDExists(variantID)
+	if err != nil {
+		return err
+	}
+	if !isExist {
+		return errors.New(utils.VariantIDNotExist)
+	}
+	return nil
+}
+
+//normalizePrice to normalize the price and validate it with the validity window of its discount,
+//returns the discount price which is nil when there is no discount
+func normalizePrice(mrp *utils.Money, discountPrice *utils.Money, validFrom *time.Time, validUntil *time.Time) (*utils.Money, error) {
+	mrp.Normalize()
+	if discountPrice != nil {
+		if discountPrice.Currency == utils.EmptyString {
+			discountPrice.Currency = mrp.Currency
+		}
+		discountPrice.Normalize()
+		if discountPrice.Amount == 0 {
+			discountPrice = nil
+		}
+	}
+	err := utils.ValidatePrice(*mrp, discountPrice)
+	if err != nil {
+		return nil, err
+	}
+	return discountPrice, utils.ValidateDiscountWindow(validFrom, validUntil)
+}
+
+//UpsertPrice to set the price of a variant for a currency and channel
+func (service *Service) UpsertPrice(request *PriceRequest) (*VariantPrice, error) {
+	var err error
+	request.Channel = strings.ToLower(strings.TrimSpace(request.Channel))
+	request.DiscountPrice, err = normalizePrice(&request.MRP, request.DiscountPrice,
+		request.DiscountValidFrom, request.DiscountValidUntil)
+	if err != nil {
+		return nil, err
+	}
+	if request.DiscountPrice == nil {
+		request.DiscountValidFrom, request.DiscountValidUntil = nil, nil
+	}
+	err = service.checkVariant(request.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.UpsertPrice(request)
+}
+
+//DeletePrice to delete the price of a variant for a currency and channel
+func (service *Service) DeletePrice(request *DeletePriceRequest) error {
+	request.Currency = strings.ToUpper(request.Currency)
+	request.Channel = strings.ToLower(strings.TrimSpace(request.Channel))
+	err := service.checkVariant(request.VariantID)
+	if err != nil {
+		return err
+	}
+	return service.repo.DeletePrice(request)
+}
+
+//ListPrice to list the price list entries of a variant
+func (service *Service) ListPrice(variantID int) ([]VariantPrice, error) {
+	err := service.checkVariant(variantID)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.ListPrice([]int{variantID})
+}
+
+//UpsertRate to set the conversion rate of a currency
+func (service *Service) UpsertRate(request *RateRequest) (*Rate, error) {
+	request.Currency = strings.ToUpper(request.Currency)
+	if !utils.IsValidCurrency(request.Currency) {
+		return nil, errors.New(utils.InvalidCurrencyError)
+	}
+	rate, ok := new(big.Rat).SetString(request.Rate.String())
+	if !ok || rate.Sign() <= 0 {
+		return nil, errors.New(utils.InvalidRateError)
+	}
+	if request.Currency == BaseCurrency() && rate.Cmp(big.NewRat(1, 1)) != 0 {
+		//the base currency converts to itself
+		return nil, errors.New(utils.InvalidRateError)
+	}
+	return service.repo.UpsertRate(request)
+}
+
+//ListRate to list the conversion rates
+func (service *Service) ListRate() ([]Rate, error) {
+	return service.repo.ListRate()
+}
+
+//ResolvePrices to get the prices of the variants in effect for the requested currency, channel and time.
+//The own price of a variant is replaced by its latest pending price change which is due at that time.
+//A variant uses its price list entry for the channel, then its entry for all channels, and otherwise its
+//price in the base currency (or its own price) converted with the conversion rates. A discount is only
+//in effect within its validity window
+func (service *Service) ResolvePrices(basePrices []BasePrice, request *ResolveRequest) (map[int]ResolvedPrice, error) {
+	resolved := make(map[int]ResolvedPrice)
+	if len(basePrices) == 0 {
+		return resolved, nil
+	}
+	asOf := request.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+	variantIDs := make([]int, len(basePrices))
+	for i, v := range basePrices {
+		variantIDs[i] = v.VariantID
+	}
+	entries, err := service.repo.ListPrice(variantIDs)
+	if err != nil {
+		return nil, err
+	}
+	priceMap := make(map[int]map[string]VariantPrice) //to map variant and its entries by currency and channel
+	for _, v := range entries {
+		if priceMap[v.VariantID] == nil {
+			priceMap[v.VariantID] = make(map[string]VariantPrice)
+		}
+		priceMap[v.VariantID][priceKey(v.MRP.Currency, v.Channel)] = v
+	}
+	schedules, err := service.repo.ListDueSchedule(variantIDs, asOf)
+	if err != nil {
+		return nil, err
+	}
+	scheduleMap := make(map[int]Schedule)
+	for _, v := range schedules {
+		scheduleMap[v.VariantID] = v
+	}
+	var rates map[string]*big.Rat
+	baseCurrency := BaseCurrency()
+	for _, v := range basePrices {
+		if schedule, ok := scheduleMap[v.VariantID]; ok {
+			v.MRP = schedule.MRP
+			v.DiscountPrice = schedule.DiscountPrice
+			v.DiscountValidFrom = schedule.DiscountValidFrom
+			v.DiscountValidUntil = schedule.DiscountValidUntil
+		}
+		currency := request.Currency
+		if currency == utils.EmptyString {
+			currency = v.MRP.Currency
+		}
+		variantPrices := priceMap[v.VariantID]
+		if entry, ok := lookupPrice(variantPrices, currency, request.Channel); ok {
+			resolved[v.VariantID] = effectivePrice(ResolvedPrice{
+				MRP:                entry.MRP,
+				DiscountPrice:      entry.DiscountPrice,
+				DiscountValidFrom:  entry.DiscountValidFrom,
+				DiscountValidUntil: entry.DiscountValidUntil,
+			}, asOf)
+			continue
+		}
+		source := ResolvedPrice{
+			MRP:                v.MRP,
+			DiscountPrice:      v.DiscountPrice,
+			DiscountValidFrom:  v.DiscountValidFrom,
+			DiscountValidUntil: v.DiscountValidUntil,
+		}
+		if entry, ok := lookupPrice(variantPrices, baseCurrency, request.Channel); ok && currency != v.MRP.Currency {
+			source.MRP = entry.MRP
+			source.DiscountPrice = entry.DiscountPrice
+			source.DiscountValidFrom = entry.DiscountValidFrom
+			source.DiscountValidUntil = entry.DiscountValidUntil
+		}
+		if source.MRP.Currency == currency {
+			resolved[v.VariantID] = effectivePrice(source, asOf)
+			continue
+		}
+		if rates == nil {
+			rates, err = service.getRates(baseCurrency)
+			if err != nil {
+				return nil, err
+			}
+		}
+		price := ResolvedPrice{
+			DiscountValidFrom:  source.DiscountValidFrom,
+			DiscountValidUntil: source.DiscountValidUntil,
+			Converted:          true,
+		}
+		price.MRP, err = convert(source.MRP, currency, rates)
+		if err != nil {
+			return nil, err
+		}
+		if source.DiscountPrice != nil {
+			discountPrice, err := convert(*source.DiscountPrice, currency, rates)
+			if err != nil {
+				return nil, err
+			}
+			price.DiscountPrice = &discountPrice
+		}
+		resolved[v.VariantID] = effectivePrice(price, asOf)
+	}
+	return resolved, nil
+}
+
+//effectivePrice to drop the discount outside its validity window and set the price to pay at the given time
+func effectivePrice(price ResolvedPrice, asOf time.Time) ResolvedPrice {
+	if price.DiscountPrice != nil && !utils.IsWithinWindow(asOf, price.DiscountValidFrom, price.DiscountValidUntil) {
+		price.DiscountPrice = nil
+	}
+	if price.DiscountPrice == nil {
+		price.DiscountValidFrom, price.DiscountValidUntil = nil, nil
+		price.EffectivePrice = price.MRP
+		return price
+	}
+	price.EffectivePrice = *price.DiscountPrice
+	return price
+}
+
+//CreateSchedule to queue a price change of a variant which is applied at its effective time
+func (service *Service) CreateSchedule(request *ScheduleRequest) (*Schedule, error) {
+	var err error
+	request.DiscountPrice, err = normalizePrice(&request.MRP, request.DiscountPrice,
+		request.DiscountValidFrom, request.DiscountValidUntil)
+	if err != nil {
+		return nil, err
+	}
+	if request.DiscountPrice == nil {
+		request.DiscountValidFrom, request.DiscountValidUntil = nil, nil
+	}
+	if !request.EffectiveAt.After(time.Now()) {
+		return nil, errors.New(utils.InvalidEffectiveAtError)
+	}
+	err = service.checkVariant(request.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.CreateSchedule(request)
+}
+
+//ListSchedule to list the scheduled price changes of a variant
+func (service *Service) ListSchedule(variantID int) ([]Schedule, error) {
+	err := service.checkVariant(variantID)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.ListSchedule(variantID)
+}
+
+//CancelSchedule to cancel a pending price change of a variant
+func (service *Service) CancelSchedule(request *ScheduleAction) error {
+	err := service.checkVariant(request.VariantID)
+	if err != nil {
+		return err
+	}
+	return service.repo.CancelSchedule(request)
+}
+
+func priceKey(currency string, channel string) string {
+	return currency + "/" + channel
+}
+
+//lookupPrice to find the entry for the currency and channel, falling back to the entry for all channels
+func lookupPrice(variantPrices map[string]VariantPrice, currency string, channel string) (VariantPrice, bool) {
+	if entry, ok := variantPrices[priceKey(currency, channel)]; ok {
+		return entry, true
+	}
+	entry, ok := variantPrices[priceKey(currency, DefaultChannel)]
+	return entry, ok
+}
+
+//getRates to get the conversion rates keyed by currency
+func (service *Service) getRates(baseCurrency string) (map[string]*big.Rat, error) {
+	rateList, err := service.repo.ListRate()
+	if err != nil {
+		return nil, err
+	}
+	rates := map[string]*big.Rat{
+		baseCurrency: big.NewRat(1, 1),
+	}
+	for _, v := range rateList {
+		rate, ok := new(big.Rat).SetString(v.Rate)
+		if !ok {
+			return nil, errors.New(utils.InvalidRateError)
+		}
+		rates[v.Currency] = rate
+	}
+	return rates, nil
+}
+
+//convert to convert the money to the currency, rounding half away from zero to the minor unit
+func convert(money utils.Money, currency string, rates map[string]*big.Rat) (utils.Money, error) {
+	fromRate, ok := rates[money.Currency]
+	if !ok {
+		return utils.Money{}, errors.New(utils.RateNotExist)
+	}
+	toRate, ok := rates[currency]
+	if !ok {
+		return utils.Money{}, errors.New(utils.RateNotExist)
+	}
+	amount := new(big.Rat).SetInt64(money.Amount)
+	amount.Mul(amount, toRate)
+	amount.Quo(amount, fromRate)
+	exponent := utils.CurrencyExponent(currency) - utils.CurrencyExponent(money.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exponent))), nil))
+	if exponent >= 0 {
+		amount.Mul(amount, scale)
+	} else {
+		amount.Quo(amount, scale)
+	}
+	return utils.Money{
+		Amount:   round(amount),
+		Currency: currency,
+	}, nil
+}
+
+//round to round the rational number half away from zero
+func round(value *big.Rat) int64 {
+	numerator := new(big.Int).Abs(value.Num())
+	denominator := value.Denom()
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Mul(remainder, big.NewInt(2)).Cmp(denominator) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+	return quotient.Int64()
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
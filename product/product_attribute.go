@@ -0,0 +1,128 @@
+package product
+
+import (
+	"ecommerce/category"
+	"ecommerce/utils"
+	"errors"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//maxSafeInteger is the largest integer a JSON number holds exactly
+const maxSafeInteger = 1 << 53
+
+//resolveAttributes to validate the attribute values of a product request against the attribute schema
+//of its category. A nil value removes the attribute, on create the required attributes must be given
+func resolveAttributes(schema []category.Attribute, values map[string]interface{}, create bool) ([]ProductAttribute, error) {
+	attributeMap := make(map[string]category.Attribute)
+	for _, attribute := range schema {
+		attributeMap[strings.ToLower(attribute.Name)] = attribute
+	}
+	var resolved []ProductAttribute
+	given := make(map[int]bool)
+	for name, value := range values {
+		attribute, ok := attributeMap[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			log.Println("Error : unknown attribute -", name)
+			return nil, errors.New(utils.UnknownAttributeError)
+		}
+		if given[attribute.ID] {
+			return nil, errors.New(utils.InvalidAttributeValueError)
+		}
+		given[attribute.ID] = true
+		if value == nil {
+			if attribute.Required {
+				return nil, errors.New(utils.AttributeRequiredError)
+			}
+			resolved = append(resolved, ProductAttribute{AttributeID: attribute.ID})
+			continue
+		}
+		productAttribute, err := resolveAttribute(&attribute, value)
+		if err != nil {
+			log.Println("Error : invalid value of attribute", attribute.Name, "-", value)
+			return nil, err
+		}
+		resolved = append(resolved, *productAttribute)
+	}
+	if create {
+		for _, attribute := range schema {
+			if attribute.Required && !given[attribute.ID] {
+				log.Println("Error : missing required attribute -", attribute.Name)
+				return nil, errors.New(utils.AttributeRequiredError)
+			}
+		}
+	}
+	return resolved, nil
+}
+
+//resolveAttribute to convert the JSON value of an attribute into the value to store
+func resolveAttribute(attribute *category.Attribute, value interface{}) (*ProductAttribute, error) {
+	productAttribute := ProductAttribute{
+		AttributeID: attribute.ID,
+	}
+	invalid := errors.New(utils.InvalidAttributeValueError)
+	switch attribute.Type {
+	case utils.AttributeInt, utils.AttributeFloat:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, invalid
+		}
+		if attribute.Type == utils.AttributeInt {
+			if number != math.Trunc(number) || math.Abs(number) > maxSafeInteger {
+				return nil, invalid
+			}
+			productAttribute.Text = strconv.FormatInt(int64(number), 10)
+		} else {
+			productAttribute.Text = strconv.FormatFloat(number, 'f', -1, 64)
+		}
+		productAttribute.Number = &number
+	case utils.AttributeBool:
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, invalid
+		}
+		productAttribute.Text = strconv.FormatBool(flag)
+	case utils.AttributeEnum:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid
+		}
+		text = strings.TrimSpace(text)
+		for _, allowed := range attribute.Values {
+			if strings.EqualFold(allowed, text) {
+				productAttribute.Text = allowed
+			}
+		}
+		if productAttribute.Text == utils.EmptyString {
+			return nil, invalid
+		}
+	default:
+		text, ok := value.(string)
+		if !ok || strings.TrimSpace(text) == utils.EmptyString {
+			return nil, invalid
+		}
+		productAttribute.Text = strings.TrimSpace(text)
+	}
+	return &productAttribute, nil
+}
+
+//attributeValue to convert the stored text of an attribute value into its JSON value
+func attributeValue(attributeType string, text string) interface{} {
+	switch attributeType {
+	case utils.AttributeInt:
+		if number, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return number
+		}
+	case utils.AttributeFloat:
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return number
+		}
+	case utils.AttributeBool:
+		if flag, err := strconv.ParseBool(text); err == nil {
+			return flag
+		}
+	}
+	return text
+}
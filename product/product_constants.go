@@ -0,0 +1,24 @@
+package product
+
+const (
+	//DefaultListLimit default number of products in a listing page
+	DefaultListLimit = 20
+	//MaxListLimit maximum number of products in a listing page
+	MaxListLimit = 100
+	//SortByName to sort the product listing by product name
+	SortByName = "name"
+	//SortByCreatedAt to sort the product listing by creation time
+	SortByCreatedAt = "created_at"
+	//SortByPrice to sort the product listing by the lowest variant price
+	SortByPrice = "price"
+	//OrderAsc ascending sort order
+	OrderAsc = "asc"
+	//OrderDesc descending sort order
+	OrderDesc = "desc"
+	//AttributeFilterPrefix query parameter prefix of the product attribute filters of the listing
+	AttributeFilterPrefix = "attr."
+	//AttributeMinSuffix query parameter suffix of the lower bound of an attribute filter
+	AttributeMinSuffix = ".min"
+	//AttributeMaxSuffix query parameter suffix of the upper bound of an attribute filter
+	AttributeMaxSuffix = ".max"
+)
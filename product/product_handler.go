@@ -2,13 +2,17 @@ package product
 
 import (
 	"database/sql"
+	"ecommerce/search"
 	"ecommerce/utils"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi"
 	"gopkg.in/go-playground/validator.v9"
@@ -20,6 +24,16 @@ type HandlerInterface interface {
 	UpdateProduct(http.ResponseWriter, *http.Request)
 	DeleteProduct(http.ResponseWriter, *http.Request)
 	GetProduct(http.ResponseWriter, *http.Request)
+	ListProduct(http.ResponseWriter, *http.Request)
+	ListOptions(http.ResponseWriter, *http.Request)
+	CreateOption(http.ResponseWriter, *http.Request)
+	AddOptionValues(http.ResponseWriter, *http.Request)
+	DeleteOption(http.ResponseWriter, *http.Request)
+	ListMedia(http.ResponseWriter, *http.Request)
+	AddMedia(http.ResponseWriter, *http.Request)
+	UpdateMedia(http.ResponseWriter, *http.Request)
+	ReorderMedia(http.ResponseWriter, *http.Request)
+	DeleteMedia(http.ResponseWriter, *http.Request)
 }
 
 //Handler struct for product management
@@ -28,12 +42,40 @@ type Handler struct {
 }
 
 //NewHTTPHandler to handle product requests
-func NewHTTPHandler(db *sql.DB) HandlerInterface {
+func NewHTTPHandler(db *sql.DB, index search.SearchIndex) HandlerInterface {
 	return &Handler{
-		cs: NewService(db),
+		cs: NewService(db, index),
 	}
 }
 
+//isOptionRequestError to check if the option error is caused by the request
+func isOptionRequestError(err error) bool {
+	switch err.Error() {
+	case utils.ProductIDNotExist, utils.OptionNotExist, utils.OptionInUseError, utils.OptionNameError, utils.OptionValueError:
+		return true
+	}
+	return false
+}
+
+//isMediaRequestError to check if the media error is caused by the request
+func isMediaRequestError(err error) bool {
+	switch err.Error() {
+	case utils.ProductIDNotExist, utils.MediaNotExist, utils.MediaVariantError, utils.MediaOrderError,
+		utils.NothingToUpdateInMedia, utils.PrimaryMediaError:
+		return true
+	}
+	return false
+}
+
+//isAttributeError to check if the error is caused by the attribute values of the request
+func isAttributeError(err error) bool {
+	switch err.Error() {
+	case utils.UnknownAttributeError, utils.InvalidAttributeValueError, utils.AttributeRequiredError:
+		return true
+	}
+	return false
+}
+
 //CreateProduct function to handle product post request
 func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	log.Println("App : /product POST API")
@@ -59,6 +101,11 @@ func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 			utils.Fail(w, 200, err.Error())
 			return
 		}
+		if isAttributeError(err) {
+			log.Println("Error : Attribute error(CreateProduct) -", err.Error())
+			utils.Fail(w, 400, err.Error())
+			return
+		}
 		log.Println("Error : Product creation error(CreateProduct) -", err.Error())
 		utils.Fail(w, 500, err.Error())
 		return
@@ -96,7 +143,7 @@ func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 			utils.Fail(w, 400, err.Error())
 			return
 		}
-		if err.Error() == utils.NothingToUpdateInProduct {
+		if err.Error() == utils.NothingToUpdateInProduct || isAttributeError(err) {
 			utils.Fail(w, 400, err.Error())
 			return
 		}
@@ -145,10 +192,26 @@ func (h *Handler) GetProduct(w http.ResponseWriter, r *http.Request) {
 		utils.Fail(w, 400, errors.New(utils.InvalidProductID).Error())
 		return
 	}
-	product, err := h.cs.GetProduct(productID)
+	request := GetRequest{
+		ProductID: productID,
+		Currency:  strings.ToUpper(r.URL.Query().Get("currency")),
+		Channel:   strings.ToLower(r.URL.Query().Get("channel")),
+	}
+	if request.Currency != utils.EmptyString && !utils.IsValidCurrency(request.Currency) {
+		log.Println("Error : (GetProduct)", utils.InvalidCurrencyError)
+		utils.Fail(w, 400, utils.InvalidCurrencyError)
+		return
+	}
+	request.AsOf, err = utils.ParseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		log.Println("Error : (GetProduct)", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	product, err := h.cs.GetProduct(&request)
 	if err != nil {
 		log.Println("Error : error fetching product details(GetProduct)", err.Error())
-		if err.Error() == utils.ProductIDNotExist {
+		if err.Error() == utils.ProductIDNotExist || err.Error() == utils.RateNotExist {
 			utils.Fail(w, 400, err.Error())
 			return
 		}
@@ -158,3 +221,479 @@ func (h *Handler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	log.Println("App : Product fetched successfully, product_id : ", productID)
 	utils.Send(w, 200, product)
 }
+
+// ListProduct to handle the product listing request
+func (h *Handler) ListProduct(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product GET API")
+	request, err := parseListRequest(r)
+	if err != nil {
+		log.Println("Error : request validation error (ListProduct)", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	products, err := h.cs.ListProduct(request)
+	if err != nil {
+		log.Println("Error : error listing products(ListProduct)", err.Error())
+		if err.Error() == utils.CategoryNOTExistsError {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Products listed successfully, count =", len(products.Products))
+	utils.Send(w, 200, products)
+}
+
+func parseListRequest(r *http.Request) (*ListRequest, error) {
+	query := r.URL.Query()
+	request := ListRequest{
+		Limit:  DefaultListLimit,
+		SortBy: SortByName,
+		Order:  OrderAsc,
+		Size:   query.Get("size"),
+		Color:  query.Get("color"),
+	}
+	var err error
+	if value := query.Get("limit"); value != utils.EmptyString {
+		request.Limit, err = strconv.Atoi(value)
+		if err != nil || request.Limit <= 0 || request.Limit > MaxListLimit {
+			return nil, errors.New(utils.InvalidParameterError + " limit")
+		}
+	}
+	if value := query.Get("category_id"); value != utils.EmptyString {
+		request.CategoryID, err = strconv.Atoi(value)
+		if err != nil || request.CategoryID <= 0 {
+			return nil, errors.New(utils.InvalidCategoryID)
+		}
+	}
+	if value := query.Get("include_subcategories"); value != utils.EmptyString {
+		request.IncludeSubcategories, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New(utils.InvalidParameterError + " include_subcategories")
+		}
+	}
+	if value := query.Get("min_price"); value != utils.EmptyString {
+		request.MinPrice, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || request.MinPrice < 0 {
+			return nil, errors.New(utils.InvalidPriceRangeError)
+		}
+	}
+	if value := query.Get("max_price"); value != utils.EmptyString {
+		request.MaxPrice, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || request.MaxPrice < 0 {
+			return nil, errors.New(utils.InvalidPriceRangeError)
+		}
+	}
+	if request.MaxPrice > 0 && request.MinPrice > request.MaxPrice {
+		return nil, errors.New(utils.InvalidPriceRangeError)
+	}
+	if value := query.Get("currency"); value != utils.EmptyString {
+		request.Currency = strings.ToUpper(value)
+		if !utils.IsValidCurrency(request.Currency) {
+			return nil, errors.New(utils.InvalidCurrencyError)
+		}
+	}
+	if value := query.Get("sort"); value != utils.EmptyString {
+		if value != SortByName && value != SortByCreatedAt && value != SortByPrice {
+			return nil, errors.New(utils.InvalidSortError)
+		}
+		request.SortBy = value
+	}
+	if value := query.Get("order"); value != utils.EmptyString {
+		if value != OrderAsc && value != OrderDesc {
+			return nil, errors.New(utils.InvalidSortError)
+		}
+		request.Order = value
+	}
+	request.AsOf, err = utils.ParseAsOf(query.Get("as_of"))
+	if err != nil {
+		return nil, err
+	}
+	request.Attributes, err = parseAttributeFilters(query)
+	if err != nil {
+		return nil, err
+	}
+	if value := query.Get("cursor"); value != utils.EmptyString {
+		request.Cursor, err = decodeCursor(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &request, nil
+}
+
+//ListOptions to handle the product option listing request
+func (h *Handler) ListOptions(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/options GET API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (ListOptions)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	options, err := h.cs.ListOptions(productID)
+	if err != nil {
+		log.Println("Error : (ListOptions) -", err.Error())
+		if isOptionRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Product options listed successfully, product id -", productID)
+	utils.Send(w, 200, options)
+}
+
+//CreateOption to handle the product option post request
+func (h *Handler) CreateOption(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/options POST API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (CreateOption)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	var request OptionRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(CreateOption) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.ProductID = productID
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(CreateOption) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	option, err := h.cs.CreateOption(&request)
+	if err != nil {
+		log.Println("Error : (CreateOption) -", err.Error())
+		if err.Error() == utils.OptionExistsError {
+			utils.Fail(w, 200, err.Error())
+			return
+		}
+		if isOptionRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Product option created successfully, option id -", option.ID)
+	utils.Send(w, 200, option)
+}
+
+//AddOptionValues to handle the product option values post request
+func (h *Handler) AddOptionValues(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/options/{option_id}/values POST API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (AddOptionValues)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	optionID, err := strconv.Atoi(chi.URLParam(r, "option_id"))
+	if err != nil {
+		log.Println("Error : (AddOptionValues)", err.Error())
+		utils.Fail(w, 400, utils.OptionNotExist)
+		return
+	}
+	var request OptionValuesRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(AddOptionValues) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.ProductID = productID
+	request.OptionID = optionID
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(AddOptionValues) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	option, err := h.cs.AddOptionValues(&request)
+	if err != nil {
+		log.Println("Error : (AddOptionValues) -", err.Error())
+		if isOptionRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Product option values added successfully, option id -", optionID)
+	utils.Send(w, 200, option)
+}
+
+//DeleteOption to handle the product option delete request
+func (h *Handler) DeleteOption(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/options/{option_id} DELETE API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (DeleteOption)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	optionID, err := strconv.Atoi(chi.URLParam(r, "option_id"))
+	if err != nil {
+		log.Println("Error : (DeleteOption)", err.Error())
+		utils.Fail(w, 400, utils.OptionNotExist)
+		return
+	}
+	err = h.cs.DeleteOption(productID, optionID)
+	if err != nil {
+		log.Println("Error : (DeleteOption) -", err.Error())
+		if isOptionRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	message := utils.Message{
+		Message: fmt.Sprintf("Product option deleted successfully, option id = %d", optionID),
+	}
+	log.Println(message.Message)
+	utils.Send(w, 200, &message)
+}
+
+//ListMedia to handle the product gallery listing request
+func (h *Handler) ListMedia(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/media GET API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (ListMedia)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	gallery, err := h.cs.ListMedia(productID)
+	if err != nil {
+		log.Println("Error : (ListMedia) -", err.Error())
+		if isMediaRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Product media listed successfully, product id -", productID)
+	utils.Send(w, 200, gallery)
+}
+
+//AddMedia to handle the product media post request
+func (h *Handler) AddMedia(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/media POST API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (AddMedia)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	var request MediaRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(AddMedia) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.ProductID = productID
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(AddMedia) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	media, err := h.cs.AddMedia(&request)
+	if err != nil {
+		log.Println("Error : (AddMedia) -", err.Error())
+		if isMediaRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Product media added successfully, media id -", media.ID)
+	utils.Send(w, 200, media)
+}
+
+//UpdateMedia to handle the product media patch request
+func (h *Handler) UpdateMedia(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/media/{media_id} PATCH API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (UpdateMedia)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	mediaID, err := strconv.Atoi(chi.URLParam(r, "media_id"))
+	if err != nil {
+		log.Println("Error : (UpdateMedia)", err.Error())
+		utils.Fail(w, 400, utils.InvalidMediaID)
+		return
+	}
+	var request MediaUpdateRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(UpdateMedia) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.ProductID = productID
+	request.MediaID = mediaID
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(UpdateMedia) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	media, err := h.cs.UpdateMedia(&request)
+	if err != nil {
+		log.Println("Error : (UpdateMedia) -", err.Error())
+		if isMediaRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Product media updated successfully, media id -", mediaID)
+	utils.Send(w, 200, media)
+}
+
+//ReorderMedia to handle the product gallery reorder request
+func (h *Handler) ReorderMedia(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/media/order PUT API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (ReorderMedia)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	var request MediaOrderRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(ReorderMedia) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.ProductID = productID
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(ReorderMedia) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	gallery, err := h.cs.ReorderMedia(&request)
+	if err != nil {
+		log.Println("Error : (ReorderMedia) -", err.Error())
+		if isMediaRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Product media reordered successfully, product id -", productID)
+	utils.Send(w, 200, gallery)
+}
+
+//DeleteMedia to handle the product media delete request
+func (h *Handler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/media/{media_id} DELETE API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (DeleteMedia)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	mediaID, err := strconv.Atoi(chi.URLParam(r, "media_id"))
+	if err != nil {
+		log.Println("Error : (DeleteMedia)", err.Error())
+		utils.Fail(w, 400, utils.InvalidMediaID)
+		return
+	}
+	err = h.cs.DeleteMedia(productID, mediaID)
+	if err != nil {
+		log.Println("Error : (DeleteMedia) -", err.Error())
+		if isMediaRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	message := utils.Message{
+		Message: fmt.Sprintf("Product media deleted successfully, media id = %d", mediaID),
+	}
+	log.Println(message.Message)
+	utils.Send(w, 200, &message)
+}
+
+//parseAttributeFilters to parse the attr.<name>=<value> filters, given more than once to match any of the
+//values, and the attr.<name>.min and attr.<name>.max range filters of the listing request
+func parseAttributeFilters(query url.Values) ([]AttributeFilter, error) {
+	filterMap := make(map[string]*AttributeFilter)
+	var names []string
+	for key, values := range query {
+		if !strings.HasPrefix(key, AttributeFilterPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(key, AttributeFilterPrefix)
+		var bound *float64
+		var rangeKey string
+		for _, suffix := range []string{AttributeMinSuffix, AttributeMaxSuffix} {
+			if strings.HasSuffix(name, suffix) {
+				name, rangeKey = strings.TrimSuffix(name, suffix), suffix
+			}
+		}
+		if rangeKey != utils.EmptyString {
+			number, err := strconv.ParseFloat(values[0], 64)
+			if err != nil {
+				return nil, errors.New(utils.InvalidAttributeFilterError)
+			}
+			bound = &number
+		}
+		if name == utils.EmptyString {
+			return nil, errors.New(utils.InvalidAttributeFilterError)
+		}
+		filter, ok := filterMap[strings.ToLower(name)]
+		if !ok {
+			filter = &AttributeFilter{Name: name}
+			filterMap[strings.ToLower(name)] = filter
+			names = append(names, strings.ToLower(name))
+		}
+		switch rangeKey {
+		case AttributeMinSuffix:
+			filter.Min = bound
+		case AttributeMaxSuffix:
+			filter.Max = bound
+		default:
+			for _, value := range values {
+				if value = strings.TrimSpace(value); value != utils.EmptyString {
+					filter.Values = append(filter.Values, value)
+				}
+			}
+			if len(filter.Values) == 0 {
+				return nil, errors.New(utils.InvalidAttributeFilterError)
+			}
+		}
+	}
+	sort.Strings(names)
+	var filters []AttributeFilter
+	for _, name := range names {
+		filters = append(filters, *filterMap[name])
+	}
+	return filters, nil
+}
@@ -1,20 +1,30 @@
 package product
 
+import (
+	"ecommerce/category"
+	"ecommerce/utils"
+	"time"
+)
+
 //CreateRequest struct to manage product create request
 type CreateRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description,omitempty"`
-	ImageURL    string `json:"image_url,omitempty"`
+	ImageURL    string `json:"image_url,omitempty" validate:"omitempty,url,max=500"`
 	CategoryID  int    `json:"category_id" validate:"required,gt=0"`
+	//Attributes maps the names of the category attributes to their values
+	Attributes      map[string]interface{} `json:"attributes,omitempty"`
+	AttributeValues []ProductAttribute     `json:"-"`
 }
 
 // CreateResponse product details create response
 type CreateResponse struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	ImageURL    string `json:"image_url,omitempty"`
-	CategoryID  int    `json:"category_id"`
+	ID          int              `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	ImageURL    string           `json:"image_url,omitempty"`
+	CategoryID  int              `json:"category_id"`
+	Attributes  []AttributeValue `json:"attributes,omitempty"`
 }
 
 //UpdateRequest struct to represent the update request
@@ -22,40 +32,207 @@ type UpdateRequest struct {
 	ProductID   int    `json:"product_id" validate:"required"`
 	Name        string `json:"name,omitempty"`
 	Description string `json:"description,omitempty"`
-	ImageURL    string `json:"image_url,omitempty"`
+	ImageURL    string `json:"image_url,omitempty" validate:"omitempty,url,max=500"`
+	//Attributes maps the names of the category attributes to their values, a null value removes
+	//the attribute from the product
+	Attributes      map[string]interface{} `json:"attributes,omitempty"`
+	AttributeValues []ProductAttribute     `json:"-"`
+}
+
+//GetRequest to represent the product get request, prices are resolved in the currency (the own
+//currency of each variant when empty) and sales channel as of the given time
+type GetRequest struct {
+	ProductID int
+	Currency  string
+	Channel   string
+	AsOf      time.Time
 }
 
 // Variant to represent variant struct
 type Variant struct {
-	ID              int     `json:"variant_id"`
-	Name            string  `json:"variant_name,omitempty"`
-	MaxRetailPrice  float64 `json:"max_retail_price"`
-	DiscountPrice float64 `json:"discount_price,omitempty"`
-	Size            string  `json:"size,omitempty"`
-	Color           string  `json:"color,omitempty"`
+	ID                 int             `json:"variant_id"`
+	Name               string          `json:"variant_name,omitempty"`
+	MaxRetailPrice     utils.Money     `json:"max_retail_price"`
+	DiscountPrice      *utils.Money    `json:"discount_price,omitempty"`
+	DiscountValidFrom  *time.Time      `json:"discount_valid_from,omitempty"`
+	DiscountValidUntil *time.Time      `json:"discount_valid_until,omitempty"`
+	EffectivePrice     utils.Money     `json:"effective_price"`
+	Size               string          `json:"size,omitempty"`
+	Color              string          `json:"color,omitempty"`
+	Options            []VariantOption `json:"options,omitempty"`
+	SKU                string          `json:"sku,omitempty"`
+	Barcode            string          `json:"barcode,omitempty"`
+	AvailableQuantity  int             `json:"available_quantity"`
+	InStock            bool            `json:"in_stock"`
+	Images             []Media         `json:"images,omitempty"`
 }
 
 // ProductVariant to represent product struct with variants
 type ProductVariant struct {
-	ID          int       `json:"product_id"`
-	Name        string    `json:"product_name"`
-	Description string    `json:"description"`
-	ImageURL    string    `json:"image_url"`
-	CategoryID  int       `json:"category_id"`
-	Variants    []Variant `json:"variants"`
+	ID          int                   `json:"product_id"`
+	Name        string                `json:"product_name"`
+	Description string                `json:"description"`
+	ImageURL    string                `json:"image_url"`
+	CategoryID  int                   `json:"category_id"`
+	Ancestors   []category.Breadcrumb `json:"ancestors,omitempty"`
+	Attributes  []AttributeValue      `json:"attributes,omitempty"`
+	Media       []Media               `json:"media,omitempty"`
+	Variants    []Variant             `json:"variants"`
 }
 
 // ProductVariantRow to represent the product variant rows from DB
 type ProductVariantRow struct {
-	ProductID       int
-	ProductName     string
-	Description     string
-	ImageURL        string
-	CategoryID      int
-	VariantID       int
-	VariantName     string
-	MRP  float64
-	DiscountPrice float64
-	VariantSize     string
-	VariantColor   string
+	ProductID          int
+	ProductName        string
+	Description        string
+	ImageURL           string
+	CategoryID         int
+	VariantID          int
+	VariantName        string
+	MRP                int64
+	DiscountPrice      int64 //0 when the variant has no discount
+	Currency           string
+	DiscountValidFrom  *time.Time
+	DiscountValidUntil *time.Time
+	VariantSize        string
+	VariantColor       string
+	SKU                string
+	Barcode            string
+	AvailableQuantity  int
+}
+
+//ListRequest to represent the product listing request
+type ListRequest struct {
+	Limit                int
+	Cursor               *ListCursor
+	CategoryID           int
+	IncludeSubcategories bool
+	MinPrice             int64
+	MaxPrice             int64
+	Currency             string
+	Size                 string
+	Color                string
+	SortBy               string
+	Order                string
+	AsOf                 time.Time //time the prices are resolved for
+	Attributes           []AttributeFilter
+}
+
+//AttributeFilter to represent a listing filter on a product attribute, products match when their value
+//is one of the values and within the range
+type AttributeFilter struct {
+	Name   string
+	Values []string
+	Min    *float64
+	Max    *float64
+}
+
+//ListCursor to represent the sort keys of the last product in a listing page
+type ListCursor struct {
+	ProductID int       `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Price     int64     `json:"price,omitempty"`
+}
+
+//ListResponse to represent the product listing response
+type ListResponse struct {
+	Products   []ProductVariant `json:"products"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+//ProductListRow to represent a product row of the listing query
+type ProductListRow struct {
+	ProductID   int
+	ProductName string
+	Description string
+	ImageURL    string
+	CategoryID  int
+	CreatedAt   time.Time
+	MinPrice    int64
+}
+
+//Option to represent an option of a product with the values its variants can take
+type Option struct {
+	ID       int           `json:"option_id"`
+	Name     string        `json:"name"`
+	Position int           `json:"position"`
+	Values   []OptionValue `json:"values"`
+}
+
+//OptionValue to represent a value of a product option
+type OptionValue struct {
+	ID    int    `json:"value_id"`
+	Value string `json:"value"`
+}
+
+//OptionRequest to represent the product option create request
+type OptionRequest struct {
+	ProductID int      `json:"-"`
+	Name      string   `json:"name" validate:"required,max=50"`
+	Values    []string `json:"values,omitempty" validate:"dive,required,max=50"`
+}
+
+//OptionValuesRequest to represent the request to add values to a product option
+type OptionValuesRequest struct {
+	ProductID int      `json:"-"`
+	OptionID  int      `json:"-"`
+	Values    []string `json:"values" validate:"required,min=1,dive,required,max=50"`
+}
+
+//VariantOption to represent the value of an option for a variant
+type VariantOption struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+//ProductAttribute to represent the value of an attribute to store for a product
+type ProductAttribute struct {
+	AttributeID int
+	Text        string   //canonical text of the value, empty to remove the attribute from the product
+	Number      *float64 //value of the int and float attributes
+}
+
+//AttributeValue to represent the value of an attribute of a product
+type AttributeValue struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+	Unit  string      `json:"unit,omitempty"`
+}
+
+//Media to represent an image of the gallery of a product, shown for the given variants of the product
+type Media struct {
+	ID         int    `json:"media_id"`
+	URL        string `json:"url"`
+	AltText    string `json:"alt_text,omitempty"`
+	Position   int    `json:"position"`
+	IsPrimary  bool   `json:"is_primary"`
+	VariantIDs []int  `json:"variant_ids,omitempty"`
+}
+
+//MediaRequest to represent the request to add an image to the end of the gallery of a product, the
+//first image of the gallery is the primary image
+type MediaRequest struct {
+	ProductID  int    `json:"-"`
+	URL        string `json:"url" validate:"required,url,max=500"`
+	AltText    string `json:"alt_text,omitempty" validate:"max=160"`
+	IsPrimary  bool   `json:"is_primary,omitempty"`
+	VariantIDs []int  `json:"variant_ids,omitempty" validate:"dive,gt=0"`
+}
+
+//MediaUpdateRequest to represent the request to update an image of the gallery, only the given fields
+//are changed and an empty list of variant IDs shows the image for the product only
+type MediaUpdateRequest struct {
+	ProductID  int     `json:"-"`
+	MediaID    int     `json:"-"`
+	AltText    *string `json:"alt_text" validate:"omitempty,max=160"`
+	IsPrimary  *bool   `json:"is_primary"`
+	VariantIDs *[]int  `json:"variant_ids" validate:"omitempty,dive,gt=0"`
+}
+
+//MediaOrderRequest to represent the request to reorder the gallery of a product, listing every image
+//of the gallery in the new order
+type MediaOrderRequest struct {
+	ProductID int   `json:"-"`
+	MediaIDs  []int `json:"media_ids" validate:"required,min=1,dive,gt=0"`
 }
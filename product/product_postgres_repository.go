@@ -2,10 +2,15 @@ package product
 
 import (
 	"database/sql"
+	"ecommerce/pricing"
 	"ecommerce/utils"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 //Repo is the DB repo struct
@@ -71,8 +76,25 @@ func (repo *Repo) CreateProduct(request *CreateRequest) (*CreateResponse, error)
 		RETURNING
 			product_id, name, description, image_url, category_id
 	`
-	row := repo.DB.QueryRow(query, request.Name, request.Description, request.ImageURL, request.CategoryID)
-	err := row.Scan(&createResponse.ID, &createResponse.Name, &description, &imageURL, &createResponse.CategoryID)
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	row := tx.QueryRow(query, request.Name, request.Description, request.ImageURL, request.CategoryID)
+	err = row.Scan(&createResponse.ID, &createResponse.Name, &description, &imageURL, &createResponse.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	err = setProductAttributes(tx, createResponse.ID, request.AttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	err = setPrimaryImage(tx, createResponse.ID, request.ImageURL)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
@@ -111,14 +133,12 @@ func (repo *Repo) IsProductIDExists(id int) (bool, error) {
 //UpdateProduct to update a category
 func (repo *Repo) UpdateProduct(request *UpdateRequest) error {
 	var slice []string
+	args := []interface{}{request.ProductID}
 	if len(request.Name) > 0 && request.Name != utils.EmptyString {
-		slice = append(slice, fmt.Sprintf(" name = '%s' ", request.Name))
+		slice = append(slice, fmt.Sprintf(" name = %s ", addArg(&args, request.Name)))
 	}
 	if len(request.Description) > 0 && request.Description != utils.EmptyString {
-		slice = append(slice, fmt.Sprintf(" description = '%s' ", request.Description))
-	}
-	if len(request.ImageURL) > 0 && request.ImageURL != utils.EmptyString {
-		slice = append(slice, fmt.Sprintf(" image_url = '%s' ", request.ImageURL))
+		slice = append(slice, fmt.Sprintf(" description = %s ", addArg(&args, request.Description)))
 	}
 	slice = append(slice, fmt.Sprintf(" updated_at = NOW() "))
 	updateQuery := strings.Join(slice, ", ")
@@ -133,15 +153,31 @@ func (repo *Repo) UpdateProduct(request *UpdateRequest) error {
 			deleted_at IS NULL
 	`
 	query := fmt.Sprintf(mainQuery, updateQuery)
-	result, err := repo.DB.Exec(query, request.ProductID)
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	result, err := tx.Exec(query, args...)
 	if err != nil {
 		return err
 	}
 	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
 	if rowsAffected == 0 {
 		return errors.New(utils.InvalidProductID)
 	}
-	return err
+	err = setProductAttributes(tx, request.ProductID, request.AttributeValues)
+	if err != nil {
+		return err
+	}
+	err = setPrimaryImage(tx, request.ProductID, request.ImageURL)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // DeleteProduct function to remove a product from DB
@@ -199,33 +235,45 @@ func (repo *Repo) DeleteProduct(productID int) error {
 // GetProduct : Postgres function to get a product
 func (repo *Repo) GetProduct(productID int) ([]ProductVariantRow, error) {
 	var productVariantList []ProductVariantRow
-	var description, imageURL, variantName, size, color sql.NullString
-	var maxRetailPrice, discountPrice sql.NullFloat64
+	var description, imageURL, variantName, size, color, sku, barcode sql.NullString
+	var maxRetailPrice, discountPrice sql.NullInt64
+	var currency sql.NullString
 	var variantID sql.NullInt32
+	var validFrom, validUntil sql.NullTime
 	query := `
 		SELECT
 			p.product_id, p.name AS product_name, p.description, p.image_url, p.category_id,
-			v.variant_id, v.name AS variant_name, v.max_retail_price, v.discount_price,
-			v.size, v.color
+			v.variant_id, v.name AS variant_name, v.max_retail_price, v.discount_price, v.currency,
+			v.discount_valid_from, v.discount_valid_until, lo.size, lo.color, v.sku, v.barcode,
+			COALESCE(s.available_quantity, 0)
 		FROM
 			tbl_product p
 			LEFT JOIN
 				tbl_variant v
 			ON p.product_id = v.product_id
+			AND v.deleted_at IS NULL
+			LEFT JOIN
+				vw_variant_stock s
+			ON s.variant_id = v.variant_id
+			LEFT JOIN
+				vw_variant_legacy_option lo
+			ON lo.variant_id = v.variant_id
 		WHERE
 			p.product_id = $1
 			AND p.deleted_at IS NULL
-			AND v.deleted_at IS NULL
+		ORDER BY
+			v.variant_id ASC
 	`
 	rows, err := repo.DB.Query(query, productID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var prodVar ProductVariantRow
 	for rows.Next() {
+		var prodVar ProductVariantRow
 		err := rows.Scan(&prodVar.ProductID, &prodVar.ProductName, &description, &imageURL, &prodVar.CategoryID,
-			&variantID, &variantName, &maxRetailPrice, &discountPrice, &size, &color)
+			&variantID, &variantName, &maxRetailPrice, &discountPrice, &currency, &validFrom, &validUntil,
+			&size, &color, &sku, &barcode, &prodVar.AvailableQuantity)
 		if err != nil {
 			return nil, err
 		}
@@ -247,14 +295,994 @@ func (repo *Repo) GetProduct(productID int) ([]ProductVariantRow, error) {
 				prodVar.VariantColor = color.String
 			}
 			if maxRetailPrice.Valid {
-				prodVar.MRP = maxRetailPrice.Float64
+				prodVar.MRP = maxRetailPrice.Int64
 			}
 			if discountPrice.Valid {
-				prodVar.DiscountPrice = discountPrice.Float64
+				prodVar.DiscountPrice = discountPrice.Int64
+			}
+			if currency.Valid {
+				prodVar.Currency = currency.String
 			}
+			prodVar.DiscountValidFrom = getTime(validFrom)
+			prodVar.DiscountValidUntil = getTime(validUntil)
+			prodVar.SKU = sku.String
+			prodVar.Barcode = barcode.String
 		}
 		productVariantList = append(productVariantList, prodVar)
 	}
 	return productVariantList, nil
 }
 
+// addArg appends the value to the query arguments and returns its placeholder
+func addArg(args *[]interface{}, value interface{}) string {
+	*args = append(*args, value)
+	return fmt.Sprintf("$%d", len(*args))
+}
+
+func getTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+// effectivePrice returns the price to pay of tbl_variant v at the time of the placeholder
+func effectivePrice(asOf string) string {
+	return pricing.EffectivePriceSQL("v", asOf)
+}
+
+// variantFilter returns the conditions on tbl_variant v for the listing request
+func variantFilter(request *ListRequest, args *[]interface{}) string {
+	var slice []string
+	if request.MinPrice > 0 {
+		slice = append(slice, fmt.Sprintf(" AND %s >= %s ", effectivePrice(addArg(args, request.AsOf)), addArg(args, request.MinPrice)))
+	}
+	if request.MaxPrice > 0 {
+		slice = append(slice, fmt.Sprintf(" AND %s <= %s ", effectivePrice(addArg(args, request.AsOf)), addArg(args, request.MaxPrice)))
+	}
+	if request.Currency != utils.EmptyString {
+		slice = append(slice, fmt.Sprintf(" AND v.currency = %s ", addArg(args, request.Currency)))
+	}
+	if request.Size != utils.EmptyString {
+		slice = append(slice, optionFilter(utils.SizeOption, request.Size, args))
+	}
+	if request.Color != utils.EmptyString {
+		slice = append(slice, optionFilter(utils.ColorOption, request.Color, args))
+	}
+	return strings.Join(slice, "")
+}
+
+// optionFilter returns the condition on tbl_variant v to have the value for the option
+func optionFilter(name string, value string, args *[]interface{}) string {
+	return fmt.Sprintf(`
+		AND EXISTS (
+			SELECT 1 FROM vw_variant_option vo
+			WHERE vo.variant_id = v.variant_id AND lower(vo.option_name) = lower(%s) AND vo.value = %s
+		) `, addArg(args, name), addArg(args, value))
+}
+
+// attributeFilter returns the condition on tbl_product p to have a value for the attribute matching the filter
+func attributeFilter(filter *AttributeFilter, args *[]interface{}) string {
+	var slice []string
+	if len(filter.Values) > 0 {
+		var texts []string
+		var numbers []float64
+		for _, value := range filter.Values {
+			texts = append(texts, strings.ToLower(value))
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				numbers = append(numbers, number)
+			}
+		}
+		slice = append(slice, fmt.Sprintf(" AND (lower(pa.value_text) = ANY(%s) OR pa.value_number = ANY(%s)) ",
+			addArg(args, pq.Array(texts)), addArg(args, pq.Array(numbers))))
+	}
+	if filter.Min != nil {
+		slice = append(slice, fmt.Sprintf(" AND pa.value_number >= %s ", addArg(args, *filter.Min)))
+	}
+	if filter.Max != nil {
+		slice = append(slice, fmt.Sprintf(" AND pa.value_number <= %s ", addArg(args, *filter.Max)))
+	}
+	return fmt.Sprintf(`
+			AND EXISTS (
+				SELECT 1 FROM tbl_product_attribute pa
+				JOIN tbl_category_attribute ca ON ca.attribute_id = pa.attribute_id
+				WHERE pa.product_id = p.product_id AND lower(ca.name) = lower(%s) %s
+			) `, addArg(args, filter.Name), strings.Join(slice, ""))
+}
+
+func hasVariantFilter(request *ListRequest) bool {
+	return request.MinPrice > 0 || request.MaxPrice > 0 || request.Currency != utils.EmptyString ||
+		request.Size != utils.EmptyString || request.Color != utils.EmptyString
+}
+
+// ListProducts : Postgres function to list a page of products
+func (repo *Repo) ListProducts(request *ListRequest) ([]ProductListRow, error) {
+	var args []interface{}
+	var conditions []string
+	var description, imageURL sql.NullString
+	var minPrice sql.NullInt64
+	minPriceExpression := effectivePrice(addArg(&args, request.AsOf))
+	variantConditions := variantFilter(request, &args)
+	if hasVariantFilter(request) {
+		conditions = append(conditions, " AND pv.matched > 0 ")
+	}
+	if request.CategoryID != 0 {
+		placeholder := addArg(&args, request.CategoryID)
+		if request.IncludeSubcategories {
+			conditions = append(conditions, fmt.Sprintf(`
+			AND p.category_id IN (
+				WITH RECURSIVE subtree AS (
+					SELECT category_id FROM tbl_category WHERE category_id = %s AND deleted_at IS NULL
+					UNION
+					SELECT c.category_id FROM tbl_category c
+					JOIN subtree s ON c.parent_category_id = s.category_id
+					WHERE c.deleted_at IS NULL
+				)
+				SELECT category_id FROM subtree
+			) `, placeholder))
+		} else {
+			conditions = append(conditions, fmt.Sprintf(" AND p.category_id = %s ", placeholder))
+		}
+	}
+	for i := range request.Attributes {
+		conditions = append(conditions, attributeFilter(&request.Attributes[i], &args))
+	}
+	sortExpression := "p.name"
+	switch request.SortBy {
+	case SortByCreatedAt:
+		sortExpression = "p.created_at"
+	case SortByPrice:
+		sortExpression = "COALESCE(pv.min_price, 0)"
+	}
+	direction, comparison := "ASC", ">"
+	if request.Order == OrderDesc {
+		direction, comparison = "DESC", "<"
+	}
+	if request.Cursor != nil {
+		var sortValue interface{}
+		switch request.SortBy {
+		case SortByCreatedAt:
+			sortValue = request.Cursor.CreatedAt
+		case SortByPrice:
+			sortValue = request.Cursor.Price
+		default:
+			sortValue = request.Cursor.Name
+		}
+		conditions = append(conditions, fmt.Sprintf(" AND (%s, p.product_id) %s (%s, %s) ",
+			sortExpression, comparison, addArg(&args, sortValue), addArg(&args, request.Cursor.ProductID)))
+	}
+	query := `
+		SELECT
+			p.product_id, p.name, p.description, p.image_url, p.category_id, p.created_at, pv.min_price
+		FROM
+			tbl_product p
+			JOIN LATERAL (
+				SELECT
+					MIN(%s) AS min_price,
+					COUNT(v.variant_id) AS matched
+				FROM
+					tbl_variant v
+				WHERE
+					v.product_id = p.product_id
+					AND v.deleted_at IS NULL
+					%s
+			) pv ON TRUE
+		WHERE
+			p.deleted_at IS NULL
+			%s
+		ORDER BY
+			%s %s,
+			p.product_id %s
+		LIMIT %s
+	`
+	mainQuery := fmt.Sprintf(query, minPriceExpression, variantConditions, strings.Join(conditions, ""),
+		sortExpression, direction, direction, addArg(&args, request.Limit+1))
+	rows, err := repo.DB.Query(mainQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var productList []ProductListRow
+	for rows.Next() {
+		var product ProductListRow
+		err := rows.Scan(&product.ProductID, &product.ProductName, &description, &imageURL,
+			&product.CategoryID, &product.CreatedAt, &minPrice)
+		if err != nil {
+			return nil, err
+		}
+		if description.Valid {
+			product.Description = description.String
+		}
+		if imageURL.Valid {
+			product.ImageURL = imageURL.String
+		}
+		if minPrice.Valid {
+			product.MinPrice = minPrice.Int64
+		}
+		productList = append(productList, product)
+	}
+	return productList, rows.Err()
+}
+
+// GetVariantsForProducts : Postgres function to get the variants of the given products matching the listing filters
+func (repo *Repo) GetVariantsForProducts(productIDs []int, request *ListRequest) ([]ProductVariantRow, error) {
+	var variantList []ProductVariantRow
+	var variantName, size, color, sku, barcode sql.NullString
+	var discountPrice sql.NullInt64
+	var validFrom, validUntil sql.NullTime
+	args := []interface{}{pq.Array(productIDs)}
+	query := `
+		SELECT
+			v.product_id, v.variant_id, v.name, v.max_retail_price, v.discount_price, v.currency,
+			v.discount_valid_from, v.discount_valid_until, lo.size, lo.color, v.sku, v.barcode,
+			COALESCE(s.available_quantity, 0)
+		FROM
+			tbl_variant v
+			LEFT JOIN
+				vw_variant_stock s
+			ON s.variant_id = v.variant_id
+			LEFT JOIN
+				vw_variant_legacy_option lo
+			ON lo.variant_id = v.variant_id
+		WHERE
+			v.product_id = ANY($1)
+			AND v.deleted_at IS NULL
+			%s
+		ORDER BY
+			v.product_id ASC,
+			v.variant_id ASC
+	`
+	mainQuery := fmt.Sprintf(query, variantFilter(request, &args))
+	rows, err := repo.DB.Query(mainQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var prodVar ProductVariantRow
+		err := rows.Scan(&prodVar.ProductID, &prodVar.VariantID, &variantName, &prodVar.MRP,
+			&discountPrice, &prodVar.Currency, &validFrom, &validUntil, &size, &color, &sku, &barcode,
+			&prodVar.AvailableQuantity)
+		if err != nil {
+			return nil, err
+		}
+		if variantName.Valid {
+			prodVar.VariantName = variantName.String
+		}
+		if discountPrice.Valid {
+			prodVar.DiscountPrice = discountPrice.Int64
+		}
+		prodVar.DiscountValidFrom = getTime(validFrom)
+		prodVar.DiscountValidUntil = getTime(validUntil)
+		if size.Valid {
+			prodVar.VariantSize = size.String
+		}
+		if color.Valid {
+			prodVar.VariantColor = color.String
+		}
+		prodVar.SKU = sku.String
+		prodVar.Barcode = barcode.String
+		variantList = append(variantList, prodVar)
+	}
+	return variantList, rows.Err()
+}
+
+//GetVariantOptions to get the option values of the given variants in the order of the product options
+func (repo *Repo) GetVariantOptions(variantIDs []int) (map[int][]VariantOption, error) {
+	query := `
+		SELECT
+			variant_id, option_name, value
+		FROM
+			vw_variant_option
+		WHERE
+			variant_id = ANY($1)
+		ORDER BY
+			variant_id ASC,
+			position ASC,
+			option_id ASC
+	`
+	rows, err := repo.DB.Query(query, pq.Array(variantIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	optionMap := make(map[int][]VariantOption)
+	for rows.Next() {
+		var variantID int
+		var option VariantOption
+		err := rows.Scan(&variantID, &option.Name, &option.Value)
+		if err != nil {
+			return nil, err
+		}
+		optionMap[variantID] = append(optionMap[variantID], option)
+	}
+	return optionMap, rows.Err()
+}
+
+//ListOptions to list the options of the product with their values
+func (repo *Repo) ListOptions(productID int) ([]Option, error) {
+	query := `
+		SELECT
+			o.option_id, o.name, o.position, v.value_id, v.value
+		FROM
+			tbl_product_option o
+		LEFT JOIN
+			tbl_product_option_value v
+		ON
+			v.option_id = o.option_id
+		WHERE
+			o.product_id = $1
+		ORDER BY
+			o.position ASC,
+			o.option_id ASC,
+			v.value_id ASC
+	`
+	rows, err := repo.DB.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	options := []Option{}
+	for rows.Next() {
+		var option Option
+		var valueID sql.NullInt64
+		var value sql.NullString
+		err := rows.Scan(&option.ID, &option.Name, &option.Position, &valueID, &value)
+		if err != nil {
+			return nil, err
+		}
+		if len(options) == 0 || options[len(options)-1].ID != option.ID {
+			option.Values = []OptionValue{}
+			options = append(options, option)
+		}
+		if valueID.Valid {
+			last := &options[len(options)-1]
+			last.Values = append(last.Values, OptionValue{
+				ID:    int(valueID.Int64),
+				Value: value.String,
+			})
+		}
+	}
+	return options, rows.Err()
+}
+
+//GetOption to get an option of the product with its values
+func (repo *Repo) GetOption(productID int, optionID int) (*Option, error) {
+	options, err := repo.ListOptions(productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		if option.ID == optionID {
+			return &option, nil
+		}
+	}
+	return nil, errors.New(utils.OptionNotExist)
+}
+
+//insertOptionValues to define the values on the option, values already defined are skipped
+func insertOptionValues(tx *sql.Tx, optionID int, values []string) error {
+	query := `
+		INSERT INTO
+			tbl_product_option_value (option_id, value, created_at)
+		SELECT
+			$1, UNNEST($2::VARCHAR[]), NOW()
+		ON CONFLICT (option_id, value) DO NOTHING
+	`
+	_, err := tx.Exec(query, optionID, pq.Array(values))
+	return err
+}
+
+//CreateOption to define an option with its values on the product, the option is placed after the
+//existing options of the product
+func (repo *Repo) CreateOption(request *OptionRequest) (*Option, error) {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	query := `
+		INSERT INTO
+			tbl_product_option (product_id, name, position, created_at, updated_at)
+		SELECT
+			$1, $2, COALESCE(MAX(position), 0) + 1, NOW(), NOW()
+		FROM
+			tbl_product_option
+		WHERE
+			product_id = $1
+		ON CONFLICT (product_id, lower(name)) DO NOTHING
+		RETURNING
+			option_id
+	`
+	var optionID int
+	err = tx.QueryRow(query, request.ProductID, request.Name).Scan(&optionID)
+	if err == sql.ErrNoRows {
+		return nil, errors.New(utils.OptionExistsError)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(request.Values) > 0 {
+		err = insertOptionValues(tx, optionID, request.Values)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetOption(request.ProductID, optionID)
+}
+
+//AddOptionValues to define more values on an option of the product
+func (repo *Repo) AddOptionValues(request *OptionValuesRequest) error {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = insertOptionValues(tx, request.OptionID, request.Values)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE tbl_product_option SET updated_at = NOW() WHERE option_id = $1", request.OptionID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+//IsOptionInUse to check if any variant that is not deleted has a value for the option
+func (repo *Repo) IsOptionInUse(optionID int) (bool, error) {
+	var count int
+	query := `
+		SELECT
+			count(*)
+		FROM
+			tbl_variant_option_value vo
+		JOIN
+			tbl_variant v
+		ON
+			v.variant_id = vo.variant_id
+		WHERE
+			vo.option_id = $1
+		AND
+			v.deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, optionID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//DeleteOption to delete the option with its values
+func (repo *Repo) DeleteOption(optionID int) error {
+	_, err := repo.DB.Exec("DELETE FROM tbl_product_option WHERE option_id = $1", optionID)
+	return err
+}
+
+// GetProductCategoryID to get the category of the product
+func (repo *Repo) GetProductCategoryID(productID int) (int, error) {
+	var categoryID int
+	query := `
+		SELECT
+			category_id
+		FROM
+			tbl_product
+		WHERE
+			product_id = $1
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, productID).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		return 0, errors.New(utils.ProductIDNotExist)
+	}
+	return categoryID, err
+}
+
+// setProductAttributes to store the attribute values of the product, an empty value removes the attribute
+func setProductAttributes(tx *sql.Tx, productID int, attributes []ProductAttribute) error {
+	for _, attribute := range attributes {
+		if attribute.Text == utils.EmptyString {
+			_, err := tx.Exec("DELETE FROM tbl_product_attribute WHERE product_id = $1 AND attribute_id = $2",
+				productID, attribute.AttributeID)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		var number sql.NullFloat64
+		if attribute.Number != nil {
+			number = sql.NullFloat64{Float64: *attribute.Number, Valid: true}
+		}
+		query := `
+			INSERT INTO
+				tbl_product_attribute (product_id, attribute_id, value_text, value_number)
+			VALUES
+				($1, $2, $3, $4)
+			ON CONFLICT (product_id, attribute_id) DO UPDATE
+			SET
+				value_text = EXCLUDED.value_text,
+				value_number = EXCLUDED.value_number
+		`
+		_, err := tx.Exec(query, productID, attribute.AttributeID, attribute.Text, number)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetProductAttributes to get the attribute values of the given products
+func (repo *Repo) GetProductAttributes(productIDs []int) (map[int][]AttributeValue, error) {
+	query := `
+		SELECT
+			pa.product_id, ca.name, ca.type, ca.unit, pa.value_text
+		FROM
+			tbl_product_attribute pa
+		JOIN
+			tbl_category_attribute ca
+		ON
+			ca.attribute_id = pa.attribute_id
+		WHERE
+			pa.product_id = ANY($1)
+		ORDER BY
+			pa.product_id ASC,
+			ca.attribute_id ASC
+	`
+	rows, err := repo.DB.Query(query, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	attributeMap := make(map[int][]AttributeValue)
+	for rows.Next() {
+		var productID int
+		var name, attributeType, text string
+		var unit sql.NullString
+		err := rows.Scan(&productID, &name, &attributeType, &unit, &text)
+		if err != nil {
+			return nil, err
+		}
+		attributeMap[productID] = append(attributeMap[productID], AttributeValue{
+			Name:  name,
+			Value: attributeValue(attributeType, text),
+			Unit:  unit.String,
+		})
+	}
+	return attributeMap, rows.Err()
+}
+
+//ListMedia to get the galleries of the given products in gallery order, each image with the live
+//variants it is shown for
+func (repo *Repo) ListMedia(productIDs []int) (map[int][]Media, error) {
+	query := `
+		SELECT
+			m.product_id, m.media_id, m.url, COALESCE(m.alt_text, ''), m.position, m.is_primary,
+			ARRAY(
+				SELECT vm.variant_id FROM tbl_variant_media vm
+				JOIN tbl_variant v ON v.variant_id = vm.variant_id
+				WHERE vm.media_id = m.media_id AND v.deleted_at IS NULL
+				ORDER BY vm.variant_id
+			)
+		FROM
+			tbl_product_media m
+		WHERE
+			m.product_id = ANY($1)
+		ORDER BY
+			m.product_id ASC,
+			m.position ASC,
+			m.media_id ASC
+	`
+	rows, err := repo.DB.Query(query, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	mediaMap := make(map[int][]Media)
+	for rows.Next() {
+		var productID int
+		var media Media
+		var variantIDs pq.Int64Array
+		err := rows.Scan(&productID, &media.ID, &media.URL, &media.AltText, &media.Position, &media.IsPrimary,
+			&variantIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, variantID := range variantIDs {
+			media.VariantIDs = append(media.VariantIDs, int(variantID))
+		}
+		mediaMap[productID] = append(mediaMap[productID], media)
+	}
+	return mediaMap, rows.Err()
+}
+
+//GetMedia to get an image of the gallery of the product
+func (repo *Repo) GetMedia(productID int, mediaID int) (*Media, error) {
+	mediaMap, err := repo.ListMedia([]int{productID})
+	if err != nil {
+		return nil, err
+	}
+	for _, media := range mediaMap[productID] {
+		if media.ID == mediaID {
+			return &media, nil
+		}
+	}
+	return nil, errors.New(utils.MediaNotExist)
+}
+
+//CheckProductVariants to check if the given distinct variant IDs are live variants of the product
+func (repo *Repo) CheckProductVariants(productID int, variantIDs []int) (bool, error) {
+	var count int
+	query := `
+		SELECT
+			count(*)
+		FROM
+			tbl_variant
+		WHERE
+			product_id = $1
+		AND
+			variant_id = ANY($2)
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, productID, pq.Array(variantIDs)).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count == len(variantIDs), nil
+}
+
+//lockGallery to lock the product row so the changes to its gallery are applied one at a time
+func lockGallery(tx *sql.Tx, productID int) error {
+	var id int
+	return tx.QueryRow("SELECT product_id FROM tbl_product WHERE product_id = $1 FOR UPDATE", productID).Scan(&id)
+}
+
+//setPrimaryMedia to make the image the primary image of the product, the previous one is unset first as
+//a product has one primary image at any time
+func setPrimaryMedia(tx *sql.Tx, productID int, mediaID int) error {
+	query := `
+		UPDATE
+			tbl_product_media
+		SET
+			is_primary = FALSE,
+			updated_at = NOW()
+		WHERE
+			product_id = $1
+		AND
+			is_primary
+		AND
+			media_id <> $2
+	`
+	_, err := tx.Exec(query, productID, mediaID)
+	if err != nil {
+		return err
+	}
+	query = `
+		UPDATE
+			tbl_product_media
+		SET
+			is_primary = TRUE,
+			updated_at = NOW()
+		WHERE
+			media_id = $1
+	`
+	_, err = tx.Exec(query, mediaID)
+	return err
+}
+
+//setMediaVariants to replace the variants the image is shown for
+func setMediaVariants(tx *sql.Tx, mediaID int, variantIDs []int) error {
+	_, err := tx.Exec("DELETE FROM tbl_variant_media WHERE media_id = $1", mediaID)
+	if err != nil {
+		return err
+	}
+	if len(variantIDs) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO
+			tbl_variant_media (media_id, variant_id)
+		SELECT
+			$1, UNNEST($2::INT[])
+	`
+	_, err = tx.Exec(query, mediaID, pq.Array(variantIDs))
+	return err
+}
+
+//syncPrimaryImage to set the image URL of the product to the URL of its primary image, clearing it
+//when the gallery is empty
+func syncPrimaryImage(tx *sql.Tx, productID int) error {
+	query := `
+		UPDATE
+			tbl_product
+		SET
+			image_url = (
+				SELECT
+					url
+				FROM
+					tbl_product_media
+				WHERE
+					product_id = $1
+				AND
+					is_primary
+			),
+			updated_at = NOW()
+		WHERE
+			product_id = $1
+	`
+	_, err := tx.Exec(query, productID)
+	return err
+}
+
+//setPrimaryImage to make the image URL given with the product its primary image, keeping the gallery
+//and the image URL of the product in step. An image of the gallery with the URL is made primary, else
+//the primary image is replaced, else the image is added as the primary image
+func setPrimaryImage(tx *sql.Tx, productID int, url string) error {
+	if url == utils.EmptyString {
+		return nil
+	}
+	err := lockGallery(tx, productID)
+	if err != nil {
+		return err
+	}
+	var mediaID int
+	query := `
+		SELECT
+			media_id
+		FROM
+			tbl_product_media
+		WHERE
+			product_id = $1
+		ORDER BY
+			url = $2 DESC,
+			is_primary DESC
+		LIMIT 1
+	`
+	err = tx.QueryRow(query, productID, url).Scan(&mediaID)
+	switch {
+	case err == sql.ErrNoRows:
+		query = `
+			INSERT INTO
+				tbl_product_media (product_id, url, position, created_at, updated_at)
+			VALUES
+				($1, $2, 1, NOW(), NOW())
+			RETURNING
+				media_id
+		`
+		err = tx.QueryRow(query, productID, url).Scan(&mediaID)
+	case err == nil:
+		query = `
+			UPDATE
+				tbl_product_media
+			SET
+				url = $2,
+				alt_text = CASE WHEN url = $2 THEN alt_text END,
+				updated_at = NOW()
+			WHERE
+				media_id = $1
+		`
+		_, err = tx.Exec(query, mediaID, url)
+	}
+	if err != nil {
+		return err
+	}
+	err = setPrimaryMedia(tx, productID, mediaID)
+	if err != nil {
+		return err
+	}
+	return syncPrimaryImage(tx, productID)
+}
+
+//CreateMedia to add the image to the end of the gallery of the product, the image is made primary when
+//requested or when the gallery is empty
+func (repo *Repo) CreateMedia(request *MediaRequest) (int, error) {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	err = lockGallery(tx, request.ProductID)
+	if err != nil {
+		return 0, err
+	}
+	query := `
+		INSERT INTO
+			tbl_product_media (product_id, url, alt_text, position, created_at, updated_at)
+		SELECT
+			$1, $2, NULLIF($3, ''), COALESCE(MAX(position), 0) + 1, NOW(), NOW()
+		FROM
+			tbl_product_media
+		WHERE
+			product_id = $1
+		RETURNING
+			media_id, position
+	`
+	var mediaID, position int
+	err = tx.QueryRow(query, request.ProductID, request.URL, request.AltText).Scan(&mediaID, &position)
+	if err != nil {
+		return 0, err
+	}
+	if request.IsPrimary || position == 1 {
+		err = setPrimaryMedia(tx, request.ProductID, mediaID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = setMediaVariants(tx, mediaID, request.VariantIDs)
+	if err != nil {
+		return 0, err
+	}
+	err = syncPrimaryImage(tx, request.ProductID)
+	if err != nil {
+		return 0, err
+	}
+	return mediaID, tx.Commit()
+}
+
+//UpdateMedia to update the given fields of an image of the gallery
+func (repo *Repo) UpdateMedia(request *MediaUpdateRequest) error {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = lockGallery(tx, request.ProductID)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE
+			tbl_product_media
+		SET
+			alt_text = CASE WHEN $3 THEN NULLIF($4, '') ELSE alt_text END,
+			updated_at = NOW()
+		WHERE
+			media_id = $1
+		AND
+			product_id = $2
+	`
+	var altText string
+	if request.AltText != nil {
+		altText = *request.AltText
+	}
+	result, err := tx.Exec(query, request.MediaID, request.ProductID, request.AltText != nil, altText)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New(utils.MediaNotExist)
+	}
+	if request.IsPrimary != nil && *request.IsPrimary {
+		err = setPrimaryMedia(tx, request.ProductID, request.MediaID)
+		if err != nil {
+			return err
+		}
+		err = syncPrimaryImage(tx, request.ProductID)
+		if err != nil {
+			return err
+		}
+	}
+	if request.VariantIDs != nil {
+		err = setMediaVariants(tx, request.MediaID, *request.VariantIDs)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+//ReorderMedia to set the positions of the images of the gallery to their order in the request
+func (repo *Repo) ReorderMedia(request *MediaOrderRequest) error {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = lockGallery(tx, request.ProductID)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE
+			tbl_product_media m
+		SET
+			position = o.position,
+			updated_at = NOW()
+		FROM
+			UNNEST($2::INT[]) WITH ORDINALITY AS o(media_id, position)
+		WHERE
+			m.media_id = o.media_id
+		AND
+			m.product_id = $1
+	`
+	_, err = tx.Exec(query, request.ProductID, pq.Array(request.MediaIDs))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+//DeleteMedia to remove an image from the gallery, closing the gap in the positions. The first image
+//left becomes primary when the primary image is removed
+func (repo *Repo) DeleteMedia(productID int, mediaID int) error {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = lockGallery(tx, productID)
+	if err != nil {
+		return err
+	}
+	var isPrimary bool
+	query := `
+		DELETE FROM
+			tbl_product_media
+		WHERE
+			media_id = $1
+		AND
+			product_id = $2
+		RETURNING
+			is_primary
+	`
+	err = tx.QueryRow(query, mediaID, productID).Scan(&isPrimary)
+	if err == sql.ErrNoRows {
+		return errors.New(utils.MediaNotExist)
+	}
+	if err != nil {
+		return err
+	}
+	query = `
+		UPDATE
+			tbl_product_media m
+		SET
+			position = o.position
+		FROM (
+			SELECT
+				media_id, ROW_NUMBER() OVER (ORDER BY position, media_id) AS position
+			FROM
+				tbl_product_media
+			WHERE
+				product_id = $1
+		) o
+		WHERE
+			m.media_id = o.media_id
+		AND
+			m.position <> o.position
+	`
+	_, err = tx.Exec(query, productID)
+	if err != nil {
+		return err
+	}
+	if isPrimary {
+		query = `
+			UPDATE
+				tbl_product_media
+			SET
+				is_primary = TRUE,
+				updated_at = NOW()
+			WHERE
+				product_id = $1
+			AND
+				position = 1
+		`
+		_, err = tx.Exec(query, productID)
+		if err != nil {
+			return err
+		}
+		err = syncPrimaryImage(tx, productID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -11,6 +11,24 @@ type RepoInterface interface {
 	UpdateProduct(*UpdateRequest) error
 	DeleteProduct(int) error
 	GetProduct(int) ([]ProductVariantRow, error)
+	ListProducts(*ListRequest) ([]ProductListRow, error)
+	GetVariantsForProducts([]int, *ListRequest) ([]ProductVariantRow, error)
+	GetVariantOptions([]int) (map[int][]VariantOption, error)
+	ListOptions(int) ([]Option, error)
+	GetOption(int, int) (*Option, error)
+	CreateOption(*OptionRequest) (*Option, error)
+	AddOptionValues(*OptionValuesRequest) error
+	IsOptionInUse(int) (bool, error)
+	DeleteOption(int) error
+	GetProductCategoryID(int) (int, error)
+	GetProductAttributes([]int) (map[int][]AttributeValue, error)
+	ListMedia([]int) (map[int][]Media, error)
+	GetMedia(int, int) (*Media, error)
+	CheckProductVariants(int, []int) (bool, error)
+	CreateMedia(*MediaRequest) (int, error)
+	UpdateMedia(*MediaUpdateRequest) error
+	ReorderMedia(*MediaOrderRequest) error
+	DeleteMedia(int, int) error
 }
 
 //NewRepo returns repository interface
@@ -2,8 +2,16 @@ package product
 
 import (
 	"database/sql"
+	"ecommerce/category"
+	"ecommerce/pricing"
+	"ecommerce/search"
 	"ecommerce/utils"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"log"
+	"strings"
+	"time"
 )
 
 //ServiceInterface is product service interface
@@ -11,18 +19,34 @@ type ServiceInterface interface {
 	CreateProduct(*CreateRequest) (*CreateResponse, error)
 	UpdateProduct(*UpdateRequest) error
 	DeleteProduct(int) error
-	GetProduct(int) (*ProductVariant, error)
+	GetProduct(*GetRequest) (*ProductVariant, error)
+	ListProduct(*ListRequest) (*ListResponse, error)
+	ListOptions(int) ([]Option, error)
+	CreateOption(*OptionRequest) (*Option, error)
+	AddOptionValues(*OptionValuesRequest) (*Option, error)
+	DeleteOption(int, int) error
+	ListMedia(int) ([]Media, error)
+	AddMedia(*MediaRequest) (*Media, error)
+	UpdateMedia(*MediaUpdateRequest) (*Media, error)
+	ReorderMedia(*MediaOrderRequest) ([]Media, error)
+	DeleteMedia(int, int) error
 }
 
 //Service struct for service functionalities
 type Service struct {
-	repo RepoInterface
+	repo       RepoInterface
+	categories category.ServiceInterface
+	prices     pricing.ServiceInterface
+	index      search.SearchIndex
 }
 
 //NewService :
-func NewService(db *sql.DB) ServiceInterface {
+func NewService(db *sql.DB, index search.SearchIndex) ServiceInterface {
 	return &Service{
-		repo: NewRepo(db),
+		repo:       NewRepo(db),
+		categories: category.NewService(db, index),
+		prices:     pricing.NewService(db),
+		index:      index,
 	}
 }
 
@@ -42,10 +66,26 @@ func (service *Service) CreateProduct(request *CreateRequest) (*CreateResponse,
 	if productExists {
 		return nil, errors.New(utils.ProductExistsError)
 	}
+	schema, err := service.categories.ListAttributes(request.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	request.AttributeValues, err = resolveAttributes(schema, request.Attributes, true)
+	if err != nil {
+		return nil, err
+	}
 	product, err := service.repo.CreateProduct(request)
 	if err != nil {
 		return nil, err
 	}
+	service.reindex(product.ID)
+	if len(request.AttributeValues) > 0 {
+		attributeMap, err := service.repo.GetProductAttributes([]int{product.ID})
+		if err != nil {
+			return nil, err
+		}
+		product.Attributes = attributeMap[product.ID]
+	}
 	return product, nil
 }
 
@@ -58,9 +98,23 @@ func (service *Service) UpdateProduct(request *UpdateRequest) error {
 	if !isExist {
 		return errors.New(utils.ProductIDNotExist)
 	}
-	if len(request.Name) <= 0 && len(request.Description) <= 0 && len(request.ImageURL) <= 0 {
+	if len(request.Name) <= 0 && len(request.Description) <= 0 && len(request.ImageURL) <= 0 && len(request.Attributes) == 0 {
 		return errors.New(utils.NothingToUpdateInCategory)
 	}
+	if len(request.Attributes) > 0 {
+		categoryID, err := service.repo.GetProductCategoryID(request.ProductID)
+		if err != nil {
+			return err
+		}
+		schema, err := service.categories.ListAttributes(categoryID)
+		if err != nil {
+			return err
+		}
+		request.AttributeValues, err = resolveAttributes(schema, request.Attributes, false)
+		if err != nil {
+			return err
+		}
+	}
 	productExists, err := service.repo.CheckProductNameExists(request.Name)
 	if err != nil {
 		return err
@@ -68,7 +122,12 @@ func (service *Service) UpdateProduct(request *UpdateRequest) error {
 	if productExists {
 		return errors.New(utils.ProductExistsError)
 	}
-	return service.repo.UpdateProduct(request)
+	err = service.repo.UpdateProduct(request)
+	if err != nil {
+		return err
+	}
+	service.reindex(request.ProductID)
+	return nil
 }
 
 //DeleteProduct to delete the given product
@@ -80,11 +139,26 @@ func (service *Service) DeleteProduct(productID int) error {
 	if !isProductExist {
 		return errors.New(utils.ProductIDNotExist)
 	}
-	return service.repo.DeleteProduct(productID)
+	err = service.repo.DeleteProduct(productID)
+	if err != nil {
+		return err
+	}
+	service.reindex(productID)
+	return nil
+}
+
+//reindex to refresh the product in the search index, the change is already saved so a failure is
+//only logged and the next rebuild of the index picks it up
+func (service *Service) reindex(productID int) {
+	err := service.index.IndexProducts(productID)
+	if err != nil {
+		log.Println("Error : error refreshing the search index(reindex) - product", productID, "-", err.Error())
+	}
 }
 
 // GetProduct  to get a product
-func (service *Service) GetProduct(productID int) (*ProductVariant, error) {
+func (service *Service) GetProduct(request *GetRequest) (*ProductVariant, error) {
+	productID := request.ProductID
 	isExist, err := service.repo.IsProductIDExists(productID)
 	if err != nil {
 		return nil, err
@@ -98,18 +172,11 @@ func (service *Service) GetProduct(productID int) (*ProductVariant, error) {
 	}
 	var (
 		product  ProductVariant
-		variant  Variant
 		variants []Variant
 	)
 	for _, row := range productDetails {
 		if row.VariantID != 0 {
-			variant.ID = row.VariantID
-			variant.Name = row.VariantName
-			variant.MaxRetailPrice = row.MRP
-			variant.DiscountPrice = row.DiscountPrice
-			variant.Size = row.VariantSize
-			variant.Color = row.VariantColor
-			variants = append(variants, variant)
+			variants = append(variants, newVariant(&row))
 		}
 	}
 	product.ID = productDetails[0].ProductID
@@ -118,5 +185,456 @@ func (service *Service) GetProduct(productID int) (*ProductVariant, error) {
 	product.ImageURL = productDetails[0].ImageURL
 	product.CategoryID = productDetails[0].CategoryID
 	product.Variants = variants
+	product.Ancestors, err = service.categories.GetCategoryPath(product.CategoryID)
+	if err != nil && err.Error() != utils.CategoryNOTExistsError {
+		return nil, err
+	}
+	err = service.setVariantOptions(product.Variants)
+	if err != nil {
+		return nil, err
+	}
+	attributeMap, err := service.repo.GetProductAttributes([]int{product.ID})
+	if err != nil {
+		return nil, err
+	}
+	product.Attributes = attributeMap[product.ID]
+	mediaMap, err := service.repo.ListMedia([]int{product.ID})
+	if err != nil {
+		return nil, err
+	}
+	product.Media = mediaMap[product.ID]
+	setVariantImages(product.Variants, product.Media)
+	err = service.resolvePrices(product.Variants, &pricing.ResolveRequest{
+		Currency: request.Currency,
+		Channel:  request.Channel,
+		AsOf:     request.AsOf,
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &product, nil
 }
+
+//resolvePrices to replace the variant prices with their prices in effect for the resolve request
+func (service *Service) resolvePrices(variants []Variant, request *pricing.ResolveRequest) error {
+	basePrices := make([]pricing.BasePrice, len(variants))
+	for i, v := range variants {
+		basePrices[i] = pricing.BasePrice{
+			VariantID:          v.ID,
+			MRP:                v.MaxRetailPrice,
+			DiscountPrice:      v.DiscountPrice,
+			DiscountValidFrom:  v.DiscountValidFrom,
+			DiscountValidUntil: v.DiscountValidUntil,
+		}
+	}
+	resolved, err := service.prices.ResolvePrices(basePrices, request)
+	if err != nil {
+		return err
+	}
+	for i, v := range variants {
+		price := resolved[v.ID]
+		variants[i].MaxRetailPrice = price.MRP
+		variants[i].DiscountPrice = price.DiscountPrice
+		variants[i].DiscountValidFrom = price.DiscountValidFrom
+		variants[i].DiscountValidUntil = price.DiscountValidUntil
+		variants[i].EffectivePrice = price.EffectivePrice
+	}
+	return nil
+}
+
+//setVariantOptions to fill the option values of the variants
+func (service *Service) setVariantOptions(variants []Variant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	variantIDs := make([]int, len(variants))
+	for i, v := range variants {
+		variantIDs[i] = v.ID
+	}
+	optionMap, err := service.repo.GetVariantOptions(variantIDs)
+	if err != nil {
+		return err
+	}
+	for i, v := range variants {
+		variants[i].Options = optionMap[v.ID]
+	}
+	return nil
+}
+
+// ListProduct to list a page of products with their variants
+func (service *Service) ListProduct(request *ListRequest) (*ListResponse, error) {
+	if request.CategoryID != 0 {
+		categoryExists, err := service.repo.CheckCategoryExists(request.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if !categoryExists {
+			return nil, errors.New(utils.CategoryNOTExistsError)
+		}
+	}
+	if request.AsOf.IsZero() {
+		request.AsOf = time.Now()
+	}
+	productRows, err := service.repo.ListProducts(request)
+	if err != nil {
+		return nil, err
+	}
+	response := ListResponse{
+		Products: []ProductVariant{},
+	}
+	if len(productRows) > request.Limit {
+		productRows = productRows[:request.Limit]
+		last := productRows[len(productRows)-1]
+		response.NextCursor, err = encodeCursor(&ListCursor{
+			ProductID: last.ProductID,
+			Name:      last.ProductName,
+			CreatedAt: last.CreatedAt,
+			Price:     last.MinPrice,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(productRows) == 0 {
+		return &response, nil
+	}
+	productIDs := make([]int, len(productRows))
+	for i, row := range productRows {
+		productIDs[i] = row.ProductID
+	}
+	variantRows, err := service.repo.GetVariantsForProducts(productIDs, request)
+	if err != nil {
+		return nil, err
+	}
+	variants := make([]Variant, len(variantRows))
+	for i, row := range variantRows {
+		variants[i] = newVariant(&row)
+	}
+	err = service.setVariantOptions(variants)
+	if err != nil {
+		return nil, err
+	}
+	err = service.resolvePrices(variants, &pricing.ResolveRequest{
+		Channel: pricing.DefaultChannel,
+		AsOf:    request.AsOf,
+	})
+	if err != nil {
+		return nil, err
+	}
+	attributeMap, err := service.repo.GetProductAttributes(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productVariantMap := make(map[int][]Variant)
+	for i, row := range variantRows {
+		productVariantMap[row.ProductID] = append(productVariantMap[row.ProductID], variants[i])
+	}
+	for _, row := range productRows {
+		response.Products = append(response.Products, ProductVariant{
+			ID:          row.ProductID,
+			Name:        row.ProductName,
+			Description: row.Description,
+			ImageURL:    row.ImageURL,
+			CategoryID:  row.CategoryID,
+			Attributes:  attributeMap[row.ProductID],
+			Variants:    productVariantMap[row.ProductID],
+		})
+	}
+	return &response, nil
+}
+
+//ListOptions to list the options of the product with their values
+func (service *Service) ListOptions(productID int) ([]Option, error) {
+	isExist, err := service.repo.IsProductIDExists(productID)
+	if err != nil {
+		return nil, err
+	}
+	if !isExist {
+		return nil, errors.New(utils.ProductIDNotExist)
+	}
+	return service.repo.ListOptions(productID)
+}
+
+//CreateOption to define an option on the product
+func (service *Service) CreateOption(request *OptionRequest) (*Option, error) {
+	isExist, err := service.repo.IsProductIDExists(request.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !isExist {
+		return nil, errors.New(utils.ProductIDNotExist)
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	request.Values = trimValues(request.Values)
+	if request.Name == utils.EmptyString {
+		return nil, errors.New(utils.OptionNameError)
+	}
+	return service.repo.CreateOption(request)
+}
+
+//AddOptionValues to define more values on an option of the product
+func (service *Service) AddOptionValues(request *OptionValuesRequest) (*Option, error) {
+	isExist, err := service.repo.IsProductIDExists(request.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !isExist {
+		return nil, errors.New(utils.ProductIDNotExist)
+	}
+	_, err = service.repo.GetOption(request.ProductID, request.OptionID)
+	if err != nil {
+		return nil, err
+	}
+	request.Values = trimValues(request.Values)
+	if len(request.Values) == 0 {
+		return nil, errors.New(utils.OptionValueError)
+	}
+	err = service.repo.AddOptionValues(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.GetOption(request.ProductID, request.OptionID)
+}
+
+//DeleteOption to delete an option of the product that no variant uses
+func (service *Service) DeleteOption(productID int, optionID int) error {
+	isExist, err := service.repo.IsProductIDExists(productID)
+	if err != nil {
+		return err
+	}
+	if !isExist {
+		return errors.New(utils.ProductIDNotExist)
+	}
+	_, err = service.repo.GetOption(productID, optionID)
+	if err != nil {
+		return err
+	}
+	inUse, err := service.repo.IsOptionInUse(optionID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New(utils.OptionInUseError)
+	}
+	return service.repo.DeleteOption(optionID)
+}
+
+//ListMedia to list the gallery of the product in gallery order
+func (service *Service) ListMedia(productID int) ([]Media, error) {
+	isExist, err := service.repo.IsProductIDExists(productID)
+	if err != nil {
+		return nil, err
+	}
+	if !isExist {
+		return nil, errors.New(utils.ProductIDNotExist)
+	}
+	mediaMap, err := service.repo.ListMedia([]int{productID})
+	if err != nil {
+		return nil, err
+	}
+	gallery := mediaMap[productID]
+	if gallery == nil {
+		gallery = []Media{}
+	}
+	return gallery, nil
+}
+
+//AddMedia to add an image to the end of the gallery of the product
+func (service *Service) AddMedia(request *MediaRequest) (*Media, error) {
+	isExist, err := service.repo.IsProductIDExists(request.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !isExist {
+		return nil, errors.New(utils.ProductIDNotExist)
+	}
+	request.URL = strings.TrimSpace(request.URL)
+	request.AltText = strings.TrimSpace(request.AltText)
+	request.VariantIDs, err = service.checkMediaVariants(request.ProductID, request.VariantIDs)
+	if err != nil {
+		return nil, err
+	}
+	mediaID, err := service.repo.CreateMedia(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.GetMedia(request.ProductID, mediaID)
+}
+
+//UpdateMedia to update the alt text, primary flag or variants of an image of the gallery
+func (service *Service) UpdateMedia(request *MediaUpdateRequest) (*Media, error) {
+	if request.AltText == nil && request.IsPrimary == nil && request.VariantIDs == nil {
+		return nil, errors.New(utils.NothingToUpdateInMedia)
+	}
+	isExist, err := service.repo.IsProductIDExists(request.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !isExist {
+		return nil, errors.New(utils.ProductIDNotExist)
+	}
+	media, err := service.repo.GetMedia(request.ProductID, request.MediaID)
+	if err != nil {
+		return nil, err
+	}
+	if request.IsPrimary != nil && !*request.IsPrimary && media.IsPrimary {
+		return nil, errors.New(utils.PrimaryMediaError)
+	}
+	if request.AltText != nil {
+		altText := strings.TrimSpace(*request.AltText)
+		request.AltText = &altText
+	}
+	if request.VariantIDs != nil {
+		variantIDs, err := service.checkMediaVariants(request.ProductID, *request.VariantIDs)
+		if err != nil {
+			return nil, err
+		}
+		request.VariantIDs = &variantIDs
+	}
+	err = service.repo.UpdateMedia(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.GetMedia(request.ProductID, request.MediaID)
+}
+
+//ReorderMedia to reorder the gallery of the product, the request lists every image of the gallery once
+func (service *Service) ReorderMedia(request *MediaOrderRequest) ([]Media, error) {
+	gallery, err := service.ListMedia(request.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if len(request.MediaIDs) != len(gallery) {
+		return nil, errors.New(utils.MediaOrderError)
+	}
+	listed := make(map[int]bool)
+	for _, mediaID := range request.MediaIDs {
+		listed[mediaID] = true
+	}
+	for _, media := range gallery {
+		if !listed[media.ID] {
+			return nil, errors.New(utils.MediaOrderError)
+		}
+	}
+	err = service.repo.ReorderMedia(request)
+	if err != nil {
+		return nil, err
+	}
+	return service.ListMedia(request.ProductID)
+}
+
+//DeleteMedia to remove an image from the gallery of the product
+func (service *Service) DeleteMedia(productID int, mediaID int) error {
+	isExist, err := service.repo.IsProductIDExists(productID)
+	if err != nil {
+		return err
+	}
+	if !isExist {
+		return errors.New(utils.ProductIDNotExist)
+	}
+	return service.repo.DeleteMedia(productID, mediaID)
+}
+
+//checkMediaVariants to check the variants of an image are variants of the product, returning the
+//variant IDs without repeats
+func (service *Service) checkMediaVariants(productID int, variantIDs []int) ([]int, error) {
+	seen := make(map[int]bool)
+	unique := []int{}
+	for _, variantID := range variantIDs {
+		if !seen[variantID] {
+			seen[variantID] = true
+			unique = append(unique, variantID)
+		}
+	}
+	if len(unique) == 0 {
+		return unique, nil
+	}
+	ok, err := service.repo.CheckProductVariants(productID, unique)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New(utils.MediaVariantError)
+	}
+	return unique, nil
+}
+
+//setVariantImages to fill the images of the variants from the gallery of their product
+func setVariantImages(variants []Variant, gallery []Media) {
+	indexes := make(map[int]int)
+	for i, v := range variants {
+		indexes[v.ID] = i
+	}
+	for _, media := range gallery {
+		for _, variantID := range media.VariantIDs {
+			if i, ok := indexes[variantID]; ok {
+				variants[i].Images = append(variants[i].Images, media)
+			}
+		}
+	}
+}
+
+//trimValues to trim the option values, dropping the empty and repeated ones
+func trimValues(values []string) []string {
+	seen := make(map[string]bool)
+	var trimmed []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == utils.EmptyString || seen[value] {
+			continue
+		}
+		seen[value] = true
+		trimmed = append(trimmed, value)
+	}
+	return trimmed
+}
+
+//newVariant to build the variant response from a product variant row
+func newVariant(row *ProductVariantRow) Variant {
+	variant := Variant{
+		ID:   row.VariantID,
+		Name: row.VariantName,
+		MaxRetailPrice: utils.Money{
+			Amount:   row.MRP,
+			Currency: row.Currency,
+		},
+		DiscountValidFrom:  row.DiscountValidFrom,
+		DiscountValidUntil: row.DiscountValidUntil,
+		Size:               row.VariantSize,
+		Color:              row.VariantColor,
+		SKU:                row.SKU,
+		Barcode:            row.Barcode,
+		AvailableQuantity:  row.AvailableQuantity,
+		InStock:            row.AvailableQuantity > 0,
+	}
+	if row.DiscountPrice != 0 {
+		variant.DiscountPrice = &utils.Money{
+			Amount:   row.DiscountPrice,
+			Currency: row.Currency,
+		}
+	}
+	return variant
+}
+
+//encodeCursor to encode the listing cursor into an opaque string
+func encodeCursor(cursor *ListCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return utils.EmptyString, err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+//decodeCursor to decode the opaque listing cursor
+func decodeCursor(value string) (*ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New(utils.InvalidCursorError)
+	}
+	var cursor ListCursor
+	err = json.Unmarshal(data, &cursor)
+	if err != nil || cursor.ProductID <= 0 {
+		return nil, errors.New(utils.InvalidCursorError)
+	}
+	return &cursor, nil
+}
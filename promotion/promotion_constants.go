@@ -0,0 +1,21 @@
+package promotion
+
+const (
+	//RulePercentageOff rule type taking a percentage off the price of the matching lines
+	RulePercentageOff = "percentage_off"
+	//RuleFixedAmount rule type taking a fixed amount off the total of the matching lines
+	RuleFixedAmount = "fixed_amount"
+	//RuleBuyXGetY rule type giving the cheapest get_quantity units free for every
+	//buy_quantity + get_quantity units of the matching lines
+	RuleBuyXGetY = "buy_x_get_y"
+	//ScopeAll promotion scope matching every variant
+	ScopeAll = "all"
+	//ScopeCategory promotion scope matching the variants in a category and its sub categories
+	ScopeCategory = "category"
+	//ScopeProduct promotion scope matching the variants of a product
+	ScopeProduct = "product"
+	//ScopeVariant promotion scope matching a single variant
+	ScopeVariant = "variant"
+	//MaxPercentage maximum percentage of a percentage off promotion
+	MaxPercentage = 100
+)
@@ -0,0 +1,211 @@
+package promotion
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+//HandlerInterface for promotion management
+type HandlerInterface interface {
+	CreatePromotion(http.ResponseWriter, *http.Request)
+	UpdatePromotion(http.ResponseWriter, *http.Request)
+	DeletePromotion(http.ResponseWriter, *http.Request)
+	GetPromotion(http.ResponseWriter, *http.Request)
+	ListPromotion(http.ResponseWriter, *http.Request)
+	Quote(http.ResponseWriter, *http.Request)
+}
+
+//Handler struct for promotion management
+type Handler struct {
+	cs ServiceInterface
+}
+
+//NewHTTPHandler to handle promotion requests
+func NewHTTPHandler(db *sql.DB) HandlerInterface {
+	return &Handler{
+		cs: NewService(db),
+	}
+}
+
+//isRequestError to check if the error is caused by the request
+func isRequestError(err error) bool {
+	switch err.Error() {
+	case utils.PromotionNotExist, utils.InvalidRuleTypeError, utils.InvalidScopeError, utils.PromotionTargetNotExist,
+		utils.InvalidPromotionValueError, utils.InvalidPromotionWindowError, utils.InvalidCurrencyError,
+		utils.NothingToUpdateInPromotion, utils.InvalidCouponError, utils.QuoteCurrencyMismatchError,
+		utils.VariantIDNotExist, utils.RateNotExist:
+		return true
+	}
+	return false
+}
+
+//CreatePromotion to handle the promotion post request
+func (h *Handler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /promotion POST API")
+	var request CreateRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(CreatePromotion) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(CreatePromotion) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	promotion, err := h.cs.CreatePromotion(&request)
+	if err != nil {
+		log.Println("Error : Promotion creation error(CreatePromotion) -", err.Error())
+		if err.Error() == utils.CouponExistsError {
+			utils.Fail(w, 200, err.Error())
+			return
+		}
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Promotion created successfully, promotion id =", promotion.ID)
+	utils.Send(w, 200, promotion)
+}
+
+//UpdatePromotion to handle the promotion patch request
+func (h *Handler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /promotion PATCH API")
+	var request UpdateRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(UpdatePromotion) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(&request)
+	if err != nil {
+		log.Println("Error : Validation error(UpdatePromotion) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	promotion, err := h.cs.UpdatePromotion(&request)
+	if err != nil {
+		log.Println("Error : Promotion update error(UpdatePromotion) -", err.Error())
+		if err.Error() == utils.CouponExistsError {
+			utils.Fail(w, 200, err.Error())
+			return
+		}
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Promotion updated successfully, promotion id =", promotion.ID)
+	utils.Send(w, 200, promotion)
+}
+
+//DeletePromotion to handle the promotion delete request
+func (h *Handler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /promotion/{promotion_id} DELETE API")
+	promotionID, err := strconv.Atoi(chi.URLParam(r, "promotion_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (DeletePromotion)")
+		utils.Fail(w, 400, utils.PromotionNotExist)
+		return
+	}
+	err = h.cs.DeletePromotion(promotionID)
+	if err != nil {
+		log.Println("Error : Promotion delete error(DeletePromotion) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	message := utils.Message{
+		Message: fmt.Sprintf("Promotion deleted successfully, promotion id = %d", promotionID),
+	}
+	log.Println(message.Message)
+	utils.Send(w, 200, &message)
+}
+
+//GetPromotion to handle the promotion get request
+func (h *Handler) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /promotion/{promotion_id} GET API")
+	promotionID, err := strconv.Atoi(chi.URLParam(r, "promotion_id"))
+	if err != nil {
+		log.Println("Error :", utils.InvalidParameterError, " (GetPromotion)")
+		utils.Fail(w, 400, utils.PromotionNotExist)
+		return
+	}
+	promotion, err := h.cs.GetPromotion(promotionID)
+	if err != nil {
+		log.Println("Error : Promotion fetch error(GetPromotion) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Promotion fetched successfully, promotion id =", promotionID)
+	utils.Send(w, 200, promotion)
+}
+
+//ListPromotion to handle the promotion list request
+func (h *Handler) ListPromotion(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /promotion GET API")
+	promotions, err := h.cs.ListPromotion()
+	if err != nil {
+		log.Println("Error : Promotion listing error(ListPromotion) -", err.Error())
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Promotions listed successfully, count =", len(promotions))
+	utils.Send(w, 200, promotions)
+}
+
+//Quote to handle the cart price quote request
+func (h *Handler) Quote(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /pricing/quote POST API")
+	var request QuoteRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(Quote) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(Quote) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	quote, err := h.cs.Quote(&request)
+	if err != nil {
+		log.Println("Error : Quote error(Quote) -", err.Error())
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Quote priced successfully, total =", quote.Total.String())
+	utils.Send(w, 200, quote)
+}
@@ -0,0 +1,112 @@
+package promotion
+
+import (
+	"ecommerce/utils"
+	"time"
+)
+
+//CreateRequest to represent the promotion create request
+type CreateRequest struct {
+	Name        string       `json:"name" validate:"required,max=100"`
+	RuleType    string       `json:"rule_type" validate:"required"`
+	Scope       string       `json:"scope" validate:"required"`
+	ScopeID     int          `json:"scope_id" validate:"gte=0"`
+	Percentage  int          `json:"percentage" validate:"gte=0"`
+	Amount      *utils.Money `json:"amount"`
+	BuyQuantity int          `json:"buy_quantity" validate:"gte=0"`
+	GetQuantity int          `json:"get_quantity" validate:"gte=0"`
+	CouponCode  string       `json:"coupon_code" validate:"max=50"`
+	ValidFrom   *time.Time   `json:"valid_from"`
+	ValidUntil  *time.Time   `json:"valid_until"`
+	Priority    int          `json:"priority"`
+	Stackable   bool         `json:"stackable"`
+}
+
+//UpdateRequest to represent the promotion update request, only the given fields are changed
+type UpdateRequest struct {
+	PromotionID int          `json:"promotion_id" validate:"required"`
+	Name        *string      `json:"name" validate:"omitempty,min=1,max=100"`
+	RuleType    *string      `json:"rule_type"`
+	Scope       *string      `json:"scope"`
+	ScopeID     *int         `json:"scope_id" validate:"omitempty,gte=0"`
+	Percentage  *int         `json:"percentage" validate:"omitempty,gte=0"`
+	Amount      *utils.Money `json:"amount"`
+	BuyQuantity *int         `json:"buy_quantity" validate:"omitempty,gte=0"`
+	GetQuantity *int         `json:"get_quantity" validate:"omitempty,gte=0"`
+	CouponCode  *string      `json:"coupon_code" validate:"omitempty,max=50"`
+	ValidFrom   *time.Time   `json:"valid_from"`
+	ValidUntil  *time.Time   `json:"valid_until"`
+	Priority    *int         `json:"priority"`
+	Stackable   *bool        `json:"stackable"`
+}
+
+//Promotion to represent a promotion rule
+type Promotion struct {
+	ID          int          `json:"promotion_id"`
+	Name        string       `json:"name"`
+	RuleType    string       `json:"rule_type"`
+	Scope       string       `json:"scope"`
+	ScopeID     int          `json:"scope_id,omitempty"`
+	Percentage  int          `json:"percentage,omitempty"`
+	Amount      *utils.Money `json:"amount,omitempty"`
+	BuyQuantity int          `json:"buy_quantity,omitempty"`
+	GetQuantity int          `json:"get_quantity,omitempty"`
+	CouponCode  string       `json:"coupon_code,omitempty"`
+	ValidFrom   *time.Time   `json:"valid_from,omitempty"`
+	ValidUntil  *time.Time   `json:"valid_until,omitempty"`
+	Priority    int          `json:"priority"`
+	Stackable   bool         `json:"stackable"`
+}
+
+//QuoteRequest to represent the price quote request of a cart, prices are quoted in the
+//currency (the own currency of the variants when empty) and sales channel
+type QuoteRequest struct {
+	Items      []QuoteItem `json:"items" validate:"required,min=1,dive"`
+	CouponCode string      `json:"coupon_code"`
+	Currency   string      `json:"currency"`
+	Channel    string      `json:"channel"`
+}
+
+//QuoteItem to represent a line of the price quote request
+type QuoteItem struct {
+	VariantID int `json:"variant_id" validate:"required,gt=0"`
+	Quantity  int `json:"quantity" validate:"required,gt=0"`
+}
+
+//Quote to represent the price quote of a cart
+type Quote struct {
+	Lines    []QuoteLine `json:"lines"`
+	Subtotal utils.Money `json:"subtotal"`
+	Discount utils.Money `json:"discount"`
+	Total    utils.Money `json:"total"`
+}
+
+//QuoteLine to represent a line of the price quote with its applied promotions
+type QuoteLine struct {
+	VariantID      int                `json:"variant_id"`
+	ProductID      int                `json:"product_id"`
+	Quantity       int                `json:"quantity"`
+	UnitPrice      utils.Money        `json:"unit_price"`
+	Subtotal       utils.Money        `json:"subtotal"`
+	Discount       utils.Money        `json:"discount"`
+	EffectivePrice utils.Money        `json:"effective_price"`
+	Promotions     []AppliedPromotion `json:"promotions"`
+}
+
+//AppliedPromotion to represent the discount of a promotion on a quote line
+type AppliedPromotion struct {
+	PromotionID int         `json:"promotion_id"`
+	Name        string      `json:"name"`
+	Discount    utils.Money `json:"discount"`
+}
+
+//QuoteVariant to represent a variant of the quote with its product and category path
+type QuoteVariant struct {
+	VariantID          int
+	ProductID          int
+	CategoryID         int
+	MRP                utils.Money
+	DiscountPrice      *utils.Money
+	DiscountValidFrom  *time.Time
+	DiscountValidUntil *time.Time
+}
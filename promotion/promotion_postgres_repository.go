@@ -0,0 +1,387 @@
+package promotion
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+//Repo is the DB repository struct
+type Repo struct {
+	DB *sql.DB
+}
+
+//rowScanner is implemented by both sql.Row and sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+const promotionColumns = `
+			promotion_id, name, rule_type, scope, scope_id, percentage, amount, currency,
+			buy_quantity, get_quantity, coupon_code, valid_from, valid_until, priority, stackable
+`
+
+func getNullInt(value int) sql.NullInt64 {
+	if value == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{
+		Int64: int64(value),
+		Valid: true,
+	}
+}
+
+func getNullString(value string) sql.NullString {
+	if value == utils.EmptyString {
+		return sql.NullString{}
+	}
+	return sql.NullString{
+		String: value,
+		Valid:  true,
+	}
+}
+
+func getNullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{
+		Time:  *value,
+		Valid: true,
+	}
+}
+
+func getTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+//promotionArgs returns the column values of the promotion in the order of promotionColumns, without the ID
+func promotionArgs(promotion *Promotion) []interface{} {
+	var amount sql.NullInt64
+	var currency sql.NullString
+	if promotion.Amount != nil {
+		amount = sql.NullInt64{Int64: promotion.Amount.Amount, Valid: true}
+		currency = getNullString(promotion.Amount.Currency)
+	}
+	return []interface{}{
+		promotion.Name, promotion.RuleType, promotion.Scope, getNullInt(promotion.ScopeID),
+		getNullInt(promotion.Percentage), amount, currency, getNullInt(promotion.BuyQuantity),
+		getNullInt(promotion.GetQuantity), getNullString(promotion.CouponCode), getNullTime(promotion.ValidFrom),
+		getNullTime(promotion.ValidUntil), promotion.Priority, promotion.Stackable,
+	}
+}
+
+//scanPromotion to scan a promotion row selected with promotionColumns
+func scanPromotion(row rowScanner) (*Promotion, error) {
+	var promotion Promotion
+	var scopeID, percentage, amount, buyQuantity, getQuantity sql.NullInt64
+	var currency, couponCode sql.NullString
+	var validFrom, validUntil sql.NullTime
+	err := row.Scan(&promotion.ID, &promotion.Name, &promotion.RuleType, &promotion.Scope, &scopeID,
+		&percentage, &amount, &currency, &buyQuantity, &getQuantity, &couponCode, &validFrom, &validUntil,
+		&promotion.Priority, &promotion.Stackable)
+	if err != nil {
+		return nil, err
+	}
+	promotion.ScopeID = int(scopeID.Int64)
+	promotion.Percentage = int(percentage.Int64)
+	promotion.BuyQuantity = int(buyQuantity.Int64)
+	promotion.GetQuantity = int(getQuantity.Int64)
+	promotion.CouponCode = couponCode.String
+	if amount.Valid {
+		promotion.Amount = &utils.Money{
+			Amount:   amount.Int64,
+			Currency: currency.String,
+		}
+	}
+	promotion.ValidFrom = getTime(validFrom)
+	promotion.ValidUntil = getTime(validUntil)
+	return &promotion, nil
+}
+
+//IsTargetExists to check if the category, product or variant targeted by a promotion scope exists
+func (repo *Repo) IsTargetExists(scope string, id int) (bool, error) {
+	var count int
+	var query string
+	switch scope {
+	case ScopeCategory:
+		query = `
+			SELECT
+				count(*)
+			FROM
+				tbl_category
+			WHERE
+				category_id = $1
+			AND
+				deleted_at IS NULL
+		`
+	case ScopeProduct:
+		query = `
+			SELECT
+				count(*)
+			FROM
+				tbl_product
+			WHERE
+				product_id = $1
+			AND
+				deleted_at IS NULL
+		`
+	case ScopeVariant:
+		query = `
+			SELECT
+				count(*)
+			FROM
+				tbl_variant
+			WHERE
+				variant_id = $1
+			AND
+				deleted_at IS NULL
+		`
+	default:
+		return true, nil
+	}
+	err := repo.DB.QueryRow(query, id).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//IsCouponCodeExists to check if another promotion uses the coupon code
+func (repo *Repo) IsCouponCodeExists(couponCode string, promotionID int) (bool, error) {
+	var count int
+	query := `
+		SELECT
+			count(*)
+		FROM
+			tbl_promotion
+		WHERE
+			coupon_code = $1
+		AND
+			promotion_id <> $2
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, couponCode, promotionID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//CreatePromotion to create a promotion in DB
+func (repo *Repo) CreatePromotion(promotion *Promotion) (*Promotion, error) {
+	query := `
+		INSERT INTO
+			tbl_promotion (name, rule_type, scope, scope_id, percentage, amount, currency, buy_quantity,
+				get_quantity, coupon_code, valid_from, valid_until, priority, stackable, created_at, updated_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NOW(), NOW())
+		RETURNING
+	` + promotionColumns
+	return scanPromotion(repo.DB.QueryRow(query, promotionArgs(promotion)...))
+}
+
+//GetPromotion to get a promotion
+func (repo *Repo) GetPromotion(promotionID int) (*Promotion, error) {
+	query := `
+		SELECT
+	` + promotionColumns + `
+		FROM
+			tbl_promotion
+		WHERE
+			promotion_id = $1
+		AND
+			deleted_at IS NULL
+	`
+	promotion, err := scanPromotion(repo.DB.QueryRow(query, promotionID))
+	if err == sql.ErrNoRows {
+		return nil, errors.New(utils.PromotionNotExist)
+	}
+	return promotion, err
+}
+
+//ListPromotion to list the promotions in the order they are applied
+func (repo *Repo) ListPromotion() ([]Promotion, error) {
+	query := `
+		SELECT
+	` + promotionColumns + `
+		FROM
+			tbl_promotion
+		WHERE
+			deleted_at IS NULL
+		ORDER BY
+			priority ASC,
+			promotion_id ASC
+	`
+	rows, err := repo.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	promotions := []Promotion{}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *promotion)
+	}
+	return promotions, rows.Err()
+}
+
+//UpdatePromotion to replace the fields of a promotion
+func (repo *Repo) UpdatePromotion(promotion *Promotion) error {
+	query := `
+		UPDATE
+			tbl_promotion
+		SET
+			name = $1,
+			rule_type = $2,
+			scope = $3,
+			scope_id = $4,
+			percentage = $5,
+			amount = $6,
+			currency = $7,
+			buy_quantity = $8,
+			get_quantity = $9,
+			coupon_code = $10,
+			valid_from = $11,
+			valid_until = $12,
+			priority = $13,
+			stackable = $14,
+			updated_at = NOW()
+		WHERE
+			promotion_id = $15
+		AND
+			deleted_at IS NULL
+	`
+	args := append(promotionArgs(promotion), promotion.ID)
+	result, err := repo.DB.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New(utils.PromotionNotExist)
+	}
+	return nil
+}
+
+//DeletePromotion to delete a promotion
+func (repo *Repo) DeletePromotion(promotionID int) error {
+	query := `
+		UPDATE
+			tbl_promotion
+		SET
+			deleted_at = NOW()
+		WHERE
+			promotion_id = $1
+		AND
+			deleted_at IS NULL
+	`
+	result, err := repo.DB.Exec(query, promotionID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New(utils.PromotionNotExist)
+	}
+	return nil
+}
+
+//ListActivePromotion to list the promotions valid at the given time in the order they are applied,
+//the promotions with a coupon code are only listed for their code
+func (repo *Repo) ListActivePromotion(asOf time.Time, couponCode string) ([]Promotion, error) {
+	query := `
+		SELECT
+	` + promotionColumns + `
+		FROM
+			tbl_promotion
+		WHERE
+			deleted_at IS NULL
+		AND
+			(valid_from IS NULL OR valid_from <= $1)
+		AND
+			(valid_until IS NULL OR valid_until > $1)
+		AND
+			(coupon_code IS NULL OR coupon_code = $2)
+		ORDER BY
+			priority ASC,
+			promotion_id ASC
+	`
+	rows, err := repo.DB.Query(query, asOf, couponCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	promotions := []Promotion{}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *promotion)
+	}
+	return promotions, rows.Err()
+}
+
+//GetQuoteVariants to get the prices, products and product categories of the given variants
+func (repo *Repo) GetQuoteVariants(variantIDs []int) ([]QuoteVariant, error) {
+	var discountPrice sql.NullInt64
+	var validFrom, validUntil sql.NullTime
+	query := `
+		SELECT
+			v.variant_id, v.product_id, p.category_id, v.max_retail_price, v.discount_price, v.currency,
+			v.discount_valid_from, v.discount_valid_until
+		FROM
+			tbl_variant v
+		JOIN
+			tbl_product p
+		ON
+			p.product_id = v.product_id
+		WHERE
+			v.variant_id = ANY($1)
+		AND
+			v.deleted_at IS NULL
+		AND
+			p.deleted_at IS NULL
+	`
+	rows, err := repo.DB.Query(query, pq.Array(variantIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var variants []QuoteVariant
+	for rows.Next() {
+		var variant QuoteVariant
+		err := rows.Scan(&variant.VariantID, &variant.ProductID, &variant.CategoryID, &variant.MRP.Amount,
+			&discountPrice, &variant.MRP.Currency, &validFrom, &validUntil)
+		if err != nil {
+			return nil, err
+		}
+		if discountPrice.Valid {
+			variant.DiscountPrice = &utils.Money{
+				Amount:   discountPrice.Int64,
+				Currency: variant.MRP.Currency,
+			}
+		}
+		variant.DiscountValidFrom = getTime(validFrom)
+		variant.DiscountValidUntil = getTime(validUntil)
+		variants = append(variants, variant)
+	}
+	return variants, rows.Err()
+}
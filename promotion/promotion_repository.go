@@ -0,0 +1,26 @@
+package promotion
+
+import (
+	"database/sql"
+	"time"
+)
+
+//RepoInterface for DB operations
+type RepoInterface interface {
+	IsTargetExists(string, int) (bool, error)
+	IsCouponCodeExists(string, int) (bool, error)
+	CreatePromotion(*Promotion) (*Promotion, error)
+	GetPromotion(int) (*Promotion, error)
+	ListPromotion() ([]Promotion, error)
+	UpdatePromotion(*Promotion) error
+	DeletePromotion(int) error
+	ListActivePromotion(time.Time, string) ([]Promotion, error)
+	GetQuoteVariants([]int) ([]QuoteVariant, error)
+}
+
+//NewRepo returns repository interface
+func NewRepo(db *sql.DB) RepoInterface {
+	return &Repo{
+		DB: db,
+	}
+}
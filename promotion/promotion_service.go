@@ -0,0 +1,445 @@
+package promotion
+
+import (
+	"database/sql"
+	"ecommerce/category"
+	"ecommerce/pricing"
+	"ecommerce/utils"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+//ServiceInterface is promotion service interface
+type ServiceInterface interface {
+	CreatePromotion(*CreateRequest) (*Promotion, error)
+	GetPromotion(int) (*Promotion, error)
+	ListPromotion() ([]Promotion, error)
+	UpdatePromotion(*UpdateRequest) (*Promotion, error)
+	DeletePromotion(int) error
+	Quote(*QuoteRequest) (*Quote, error)
+}
+
+//Service struct for service functionalities
+type Service struct {
+	repo       RepoInterface
+	prices     pricing.ServiceInterface
+	categories category.ServiceInterface
+}
+
+//NewService :
+func NewService(db *sql.DB) ServiceInterface {
+	return &Service{
+		repo:       NewRepo(db),
+		prices:     pricing.NewService(db),
+		categories: category.NewService(db, nil),
+	}
+}
+
+//CreatePromotion to create a promotion
+func (service *Service) CreatePromotion(request *CreateRequest) (*Promotion, error) {
+	promotion := Promotion{
+		Name:        request.Name,
+		RuleType:    request.RuleType,
+		Scope:       request.Scope,
+		ScopeID:     request.ScopeID,
+		Percentage:  request.Percentage,
+		Amount:      request.Amount,
+		BuyQuantity: request.BuyQuantity,
+		GetQuantity: request.GetQuantity,
+		CouponCode:  request.CouponCode,
+		ValidFrom:   request.ValidFrom,
+		ValidUntil:  request.ValidUntil,
+		Priority:    request.Priority,
+		Stackable:   request.Stackable,
+	}
+	err := service.validatePromotion(&promotion)
+	if err != nil {
+		return nil, err
+	}
+	return service.repo.CreatePromotion(&promotion)
+}
+
+//GetPromotion to get a promotion
+func (service *Service) GetPromotion(promotionID int) (*Promotion, error) {
+	return service.repo.GetPromotion(promotionID)
+}
+
+//ListPromotion to list the promotions
+func (service *Service) ListPromotion() ([]Promotion, error) {
+	return service.repo.ListPromotion()
+}
+
+//UpdatePromotion to update the given fields of a promotion
+func (service *Service) UpdatePromotion(request *UpdateRequest) (*Promotion, error) {
+	promotion, err := service.repo.GetPromotion(request.PromotionID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Name == nil && request.RuleType == nil && request.Scope == nil && request.ScopeID == nil &&
+		request.Percentage == nil && request.Amount == nil && request.BuyQuantity == nil &&
+		request.GetQuantity == nil && request.CouponCode == nil && request.ValidFrom == nil &&
+		request.ValidUntil == nil && request.Priority == nil && request.Stackable == nil {
+		return nil, errors.New(utils.NothingToUpdateInPromotion)
+	}
+	if request.Name != nil {
+		promotion.Name = *request.Name
+	}
+	if request.RuleType != nil {
+		promotion.RuleType = *request.RuleType
+	}
+	if request.Scope != nil {
+		promotion.Scope = *request.Scope
+	}
+	if request.ScopeID != nil {
+		promotion.ScopeID = *request.ScopeID
+	}
+	if request.Percentage != nil {
+		promotion.Percentage = *request.Percentage
+	}
+	if request.Amount != nil {
+		promotion.Amount = request.Amount
+	}
+	if request.BuyQuantity != nil {
+		promotion.BuyQuantity = *request.BuyQuantity
+	}
+	if request.GetQuantity != nil {
+		promotion.GetQuantity = *request.GetQuantity
+	}
+	if request.CouponCode != nil {
+		promotion.CouponCode = *request.CouponCode
+	}
+	if request.ValidFrom != nil {
+		promotion.ValidFrom = request.ValidFrom
+	}
+	if request.ValidUntil != nil {
+		promotion.ValidUntil = request.ValidUntil
+	}
+	if request.Priority != nil {
+		promotion.Priority = *request.Priority
+	}
+	if request.Stackable != nil {
+		promotion.Stackable = *request.Stackable
+	}
+	err = service.validatePromotion(promotion)
+	if err != nil {
+		return nil, err
+	}
+	err = service.repo.UpdatePromotion(promotion)
+	if err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+//DeletePromotion to delete a promotion
+func (service *Service) DeletePromotion(promotionID int) error {
+	return service.repo.DeletePromotion(promotionID)
+}
+
+//validatePromotion to normalize the promotion and validate its rule, scope, coupon code and window.
+//The values which don't belong to the rule type are cleared
+func (service *Service) validatePromotion(promotion *Promotion) error {
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	promotion.CouponCode = strings.ToUpper(strings.TrimSpace(promotion.CouponCode))
+	switch promotion.RuleType {
+	case RulePercentageOff:
+		if promotion.Percentage <= 0 || promotion.Percentage > MaxPercentage {
+			return errors.New(utils.InvalidPromotionValueError)
+		}
+		promotion.Amount, promotion.BuyQuantity, promotion.GetQuantity = nil, 0, 0
+	case RuleFixedAmount:
+		if promotion.Amount == nil {
+			return errors.New(utils.InvalidPromotionValueError)
+		}
+		promotion.Amount.Normalize()
+		if !utils.IsValidCurrency(promotion.Amount.Currency) {
+			return errors.New(utils.InvalidCurrencyError)
+		}
+		if promotion.Amount.Amount <= 0 {
+			return errors.New(utils.InvalidPromotionValueError)
+		}
+		promotion.Percentage, promotion.BuyQuantity, promotion.GetQuantity = 0, 0, 0
+	case RuleBuyXGetY:
+		if promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+			return errors.New(utils.InvalidPromotionValueError)
+		}
+		promotion.Percentage, promotion.Amount = 0, nil
+	default:
+		return errors.New(utils.InvalidRuleTypeError)
+	}
+	switch promotion.Scope {
+	case ScopeAll:
+		promotion.ScopeID = 0
+	case ScopeCategory, ScopeProduct, ScopeVariant:
+		if promotion.ScopeID <= 0 {
+			return errors.New(utils.PromotionTargetNotExist)
+		}
+		isExist, err := service.repo.IsTargetExists(promotion.Scope, promotion.ScopeID)
+		if err != nil {
+			return err
+		}
+		if !isExist {
+			return errors.New(utils.PromotionTargetNotExist)
+		}
+	default:
+		return errors.New(utils.InvalidScopeError)
+	}
+	if promotion.ValidFrom != nil && promotion.ValidUntil != nil && !promotion.ValidFrom.Before(*promotion.ValidUntil) {
+		return errors.New(utils.InvalidPromotionWindowError)
+	}
+	if promotion.CouponCode != utils.EmptyString {
+		isExist, err := service.repo.IsCouponCodeExists(promotion.CouponCode, promotion.ID)
+		if err != nil {
+			return err
+		}
+		if isExist {
+			return errors.New(utils.CouponExistsError)
+		}
+	}
+	return nil
+}
+
+//getCategoryChains to get the category of each product of the variants with its ancestors, keyed by the
+//category, from the category hierarchy
+func (service *Service) getCategoryChains(variants []QuoteVariant) (map[int][]int, error) {
+	chains := make(map[int][]int)
+	for _, v := range variants {
+		if _, ok := chains[v.CategoryID]; ok {
+			continue
+		}
+		path, err := service.categories.GetCategoryPath(v.CategoryID)
+		if err != nil && err.Error() != utils.CategoryNOTExistsError {
+			return nil, err
+		}
+		chain := []int{v.CategoryID}
+		for _, breadcrumb := range path {
+			if breadcrumb.ID != v.CategoryID {
+				chain = append(chain, breadcrumb.ID)
+			}
+		}
+		chains[v.CategoryID] = chain
+	}
+	return chains, nil
+}
+
+//quoteLine to keep the state of a quote line while the promotions are applied
+type quoteLine struct {
+	line        QuoteLine
+	categoryIDs []int //category of the product and its ancestors
+	remaining   int64 //line subtotal left after the applied discounts
+	locked      bool  //true when a promotion which doesn't stack is applied
+}
+
+//Quote to price the cart lines at their effective prices and apply the active promotions in the order
+//of their priority. A promotion which doesn't stack only applies to the lines without a discount and
+//no other promotion applies after it
+func (service *Service) Quote(request *QuoteRequest) (*Quote, error) {
+	now := time.Now()
+	request.Currency = strings.ToUpper(request.Currency)
+	request.Channel = strings.ToLower(strings.TrimSpace(request.Channel))
+	request.CouponCode = strings.ToUpper(strings.TrimSpace(request.CouponCode))
+	if request.Currency != utils.EmptyString && !utils.IsValidCurrency(request.Currency) {
+		return nil, errors.New(utils.InvalidCurrencyError)
+	}
+	variantIDs := make([]int, len(request.Items))
+	for i, v := range request.Items {
+		variantIDs[i] = v.VariantID
+	}
+	variants, err := service.repo.GetQuoteVariants(variantIDs)
+	if err != nil {
+		return nil, err
+	}
+	categoryChains, err := service.getCategoryChains(variants)
+	if err != nil {
+		return nil, err
+	}
+	variantMap := make(map[int]QuoteVariant)
+	basePrices := make([]pricing.BasePrice, len(variants))
+	for i, v := range variants {
+		variantMap[v.VariantID] = v
+		basePrices[i] = pricing.BasePrice{
+			VariantID:          v.VariantID,
+			MRP:                v.MRP,
+			DiscountPrice:      v.DiscountPrice,
+			DiscountValidFrom:  v.DiscountValidFrom,
+			DiscountValidUntil: v.DiscountValidUntil,
+		}
+	}
+	prices, err := service.prices.ResolvePrices(basePrices, &pricing.ResolveRequest{
+		Currency: request.Currency,
+		Channel:  request.Channel,
+		AsOf:     now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]*quoteLine, len(request.Items))
+	for i, item := range request.Items {
+		variant, ok := variantMap[item.VariantID]
+		if !ok {
+			return nil, errors.New(utils.VariantIDNotExist)
+		}
+		unitPrice := prices[item.VariantID].EffectivePrice
+		if request.Currency == utils.EmptyString {
+			request.Currency = unitPrice.Currency
+		}
+		if unitPrice.Currency != request.Currency {
+			return nil, errors.New(utils.QuoteCurrencyMismatchError)
+		}
+		subtotal := unitPrice.Amount * int64(item.Quantity)
+		lines[i] = &quoteLine{
+			line: QuoteLine{
+				VariantID:  item.VariantID,
+				ProductID:  variant.ProductID,
+				Quantity:   item.Quantity,
+				UnitPrice:  unitPrice,
+				Subtotal:   utils.Money{Amount: subtotal, Currency: unitPrice.Currency},
+				Promotions: []AppliedPromotion{},
+			},
+			categoryIDs: categoryChains[variant.CategoryID],
+			remaining:   subtotal,
+		}
+	}
+	promotions, err := service.repo.ListActivePromotion(now, request.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+	couponFound := false
+	for _, promotion := range promotions {
+		if promotion.CouponCode != utils.EmptyString {
+			couponFound = true
+		}
+		var matched []*quoteLine
+		for _, v := range lines {
+			if v.locked || v.remaining == 0 || !matchScope(&promotion, v) {
+				continue
+			}
+			if !promotion.Stackable && len(v.line.Promotions) > 0 {
+				continue
+			}
+			matched = append(matched, v)
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		discounts := computeDiscounts(&promotion, matched, request.Currency)
+		for i, v := range matched {
+			if discounts[i] <= 0 {
+				continue
+			}
+			v.remaining -= discounts[i]
+			v.line.Promotions = append(v.line.Promotions, AppliedPromotion{
+				PromotionID: promotion.ID,
+				Name:        promotion.Name,
+				Discount:    utils.Money{Amount: discounts[i], Currency: request.Currency},
+			})
+			if !promotion.Stackable {
+				v.locked = true
+			}
+		}
+	}
+	if request.CouponCode != utils.EmptyString && !couponFound {
+		return nil, errors.New(utils.InvalidCouponError)
+	}
+	quote := Quote{
+		Lines:    make([]QuoteLine, len(lines)),
+		Subtotal: utils.Money{Currency: request.Currency},
+		Discount: utils.Money{Currency: request.Currency},
+		Total:    utils.Money{Currency: request.Currency},
+	}
+	for i, v := range lines {
+		v.line.Discount = utils.Money{Amount: v.line.Subtotal.Amount - v.remaining, Currency: request.Currency}
+		v.line.EffectivePrice = utils.Money{Amount: v.remaining, Currency: request.Currency}
+		quote.Lines[i] = v.line
+		quote.Subtotal.Amount += v.line.Subtotal.Amount
+		quote.Discount.Amount += v.line.Discount.Amount
+		quote.Total.Amount += v.remaining
+	}
+	return &quote, nil
+}
+
+//matchScope to check if the quote line is in the scope of the promotion
+func matchScope(promotion *Promotion, line *quoteLine) bool {
+	switch promotion.Scope {
+	case ScopeAll:
+		return true
+	case ScopeVariant:
+		return line.line.VariantID == promotion.ScopeID
+	case ScopeProduct:
+		return line.line.ProductID == promotion.ScopeID
+	case ScopeCategory:
+		for _, v := range line.categoryIDs {
+			if v == promotion.ScopeID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//computeDiscounts returns the discount of the promotion on each matched line, never more than what is
+//left of the line
+func computeDiscounts(promotion *Promotion, lines []*quoteLine, currency string) []int64 {
+	discounts := make([]int64, len(lines))
+	switch promotion.RuleType {
+	case RulePercentageOff:
+		for i, v := range lines {
+			//rounded half up to the minor unit
+			discounts[i] = (v.remaining*int64(promotion.Percentage)*2 + 100) / 200
+		}
+	case RuleFixedAmount:
+		if promotion.Amount == nil || promotion.Amount.Currency != currency {
+			return discounts
+		}
+		var total int64
+		for _, v := range lines {
+			total += v.remaining
+		}
+		amount := promotion.Amount.Amount
+		if amount > total {
+			amount = total
+		}
+		//the amount is split across the lines in proportion to what is left of them,
+		//the last line takes the rounding difference
+		var allocated int64
+		for i, v := range lines {
+			if i == len(lines)-1 {
+				discounts[i] = amount - allocated
+				break
+			}
+			discounts[i] = amount * v.remaining / total
+			allocated += discounts[i]
+		}
+	case RuleBuyXGetY:
+		var quantity int
+		order := make([]int, len(lines))
+		for i, v := range lines {
+			quantity += v.line.Quantity
+			order[i] = i
+		}
+		free := quantity / (promotion.BuyQuantity + promotion.GetQuantity) * promotion.GetQuantity
+		//the cheapest units are free
+		sort.SliceStable(order, func(a, b int) bool {
+			return lines[order[a]].line.UnitPrice.Amount < lines[order[b]].line.UnitPrice.Amount
+		})
+		for _, i := range order {
+			if free == 0 {
+				break
+			}
+			units := lines[i].line.Quantity
+			if units > free {
+				units = free
+			}
+			free -= units
+			discounts[i] = int64(units) * lines[i].line.UnitPrice.Amount
+		}
+	}
+	for i, v := range lines {
+		if discounts[i] > v.remaining {
+			discounts[i] = v.remaining
+		}
+	}
+	return discounts
+}
@@ -3,7 +3,15 @@ package router
 import (
 	"database/sql"
 	"ecommerce/category"
+	"ecommerce/export"
+	"ecommerce/feed"
+	"ecommerce/importer"
+	"ecommerce/inventory"
+	"ecommerce/jobs"
+	"ecommerce/pricing"
 	"ecommerce/product"
+	"ecommerce/promotion"
+	"ecommerce/search"
 	"ecommerce/variant"
 
 	"github.com/go-chi/chi"
@@ -17,26 +25,36 @@ type Router interface {
 
 //ChiRouter chi struct
 type ChiRouter struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Index search.SearchIndex
 }
 
 //NewRouter returns a router struct
-func NewRouter(db *sql.DB) Router {
+func NewRouter(db *sql.DB, index search.SearchIndex) Router {
 	return &ChiRouter{
-		DB: db,
+		DB:    db,
+		Index: index,
 	}
 }
 
 //Setup function to initialize the routing
 func (router *ChiRouter) Setup() *chi.Mux {
 	cr := chi.NewRouter()
-	categoryHandler := category.NewHTTPHandler(router.DB)
-	productHandler := product.NewHTTPHandler(router.DB)
-	variantHandler := variant.NewHTTPHandler(router.DB)
+	categoryHandler := category.NewHTTPHandler(router.DB, router.Index)
+	productHandler := product.NewHTTPHandler(router.DB, router.Index)
+	variantHandler := variant.NewHTTPHandler(router.DB, router.Index)
+	inventoryHandler := inventory.NewHTTPHandler(router.DB)
+	pricingHandler := pricing.NewHTTPHandler(router.DB)
+	promotionHandler := promotion.NewHTTPHandler(router.DB)
+	searchHandler := search.NewHTTPHandler(router.DB, router.Index)
+	importHandler := importer.NewHTTPHandler(router.DB, router.Index)
+	jobHandler := jobs.NewHTTPHandler(router.DB, router.Index)
+	exportHandler := export.NewHTTPHandler(router.DB)
+	feedHandler := feed.NewHTTPHandler(router.DB)
 	cr.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", variant.ChangedByHeader},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
 		MaxAge:           300,
@@ -44,15 +62,66 @@ func (router *ChiRouter) Setup() *chi.Mux {
 	cr.Post("/category", categoryHandler.CreateCategory)
 	cr.Patch("/category", categoryHandler.UpdateCategory)
 	cr.Get("/category", categoryHandler.ListCategory)
+	cr.Get("/category/{category_id}", categoryHandler.ListCategory)
+	cr.Get("/category/{category_id}/path", categoryHandler.GetCategoryPath)
 	cr.Delete("/category/{category_id}", categoryHandler.DeleteCategory)
+	cr.Get("/category/{category_id}/attributes", categoryHandler.ListAttributes)
+	cr.Post("/category/{category_id}/attributes", categoryHandler.CreateAttribute)
+	cr.Delete("/category/{category_id}/attributes/{attribute_id}", categoryHandler.DeleteAttribute)
 	cr.Post("/product", productHandler.CreateProduct)
 	cr.Patch("/product", productHandler.UpdateProduct)
+	cr.Get("/product", productHandler.ListProduct)
 	cr.Get("/product/{product_id}", productHandler.GetProduct)
 	cr.Delete("/product/{product_id}", productHandler.DeleteProduct)
+	cr.Get("/product/{product_id}/options", productHandler.ListOptions)
+	cr.Post("/product/{product_id}/options", productHandler.CreateOption)
+	cr.Post("/product/{product_id}/options/{option_id}/values", productHandler.AddOptionValues)
+	cr.Delete("/product/{product_id}/options/{option_id}", productHandler.DeleteOption)
+	cr.Get("/product/{product_id}/media", productHandler.ListMedia)
+	cr.Post("/product/{product_id}/media", productHandler.AddMedia)
+	cr.Put("/product/{product_id}/media/order", productHandler.ReorderMedia)
+	cr.Patch("/product/{product_id}/media/{media_id}", productHandler.UpdateMedia)
+	cr.Delete("/product/{product_id}/media/{media_id}", productHandler.DeleteMedia)
 	cr.Post("/variant", variantHandler.CreateVariant)
 	cr.Patch("/variant", variantHandler.UpdateVariant)
 	cr.Get("/product/{product_id}/variant/{variant_id}", variantHandler.GetVariant)
 	cr.Get("/product/{product_id}/variant", variantHandler.ListVariant)
+	cr.Post("/product/{product_id}/variants/generate", variantHandler.GenerateVariants)
 	cr.Delete("/variant/{variant_id}", variantHandler.DeleteVariant)
+	cr.Get("/variant/by-sku/{sku}", variantHandler.GetVariantBySKU)
+	cr.Get("/variant/by-barcode/{code}", variantHandler.GetVariantByBarcode)
+	cr.Get("/variant/{variant_id}/price-history", variantHandler.ListPriceHistory)
+	cr.Get("/variant/{variant_id}/prices", pricingHandler.ListPrice)
+	cr.Put("/variant/{variant_id}/prices", pricingHandler.UpsertPrice)
+	cr.Delete("/variant/{variant_id}/prices/{currency}", pricingHandler.DeletePrice)
+	cr.Get("/variant/{variant_id}/price-schedules", pricingHandler.ListSchedule)
+	cr.Post("/variant/{variant_id}/price-schedules", pricingHandler.CreateSchedule)
+	cr.Delete("/variant/{variant_id}/price-schedules/{schedule_id}", pricingHandler.CancelSchedule)
+	cr.Get("/currency-rates", pricingHandler.ListRate)
+	cr.Put("/currency-rates/{currency}", pricingHandler.UpsertRate)
+	cr.Post("/promotion", promotionHandler.CreatePromotion)
+	cr.Patch("/promotion", promotionHandler.UpdatePromotion)
+	cr.Get("/promotion", promotionHandler.ListPromotion)
+	cr.Get("/promotion/{promotion_id}", promotionHandler.GetPromotion)
+	cr.Delete("/promotion/{promotion_id}", promotionHandler.DeletePromotion)
+	cr.Post("/pricing/quote", promotionHandler.Quote)
+	cr.Get("/search", searchHandler.Search)
+	cr.Get("/search/facets", searchHandler.GetFacets)
+	cr.Post("/import", importHandler.Import)
+	cr.Get("/export", exportHandler.Export)
+	cr.Get("/feeds/google.xml", feedHandler.GoogleFeed)
+	cr.Get("/feeds/facebook.csv", feedHandler.FacebookFeed)
+	cr.Post("/jobs/import", jobHandler.CreateImportJob)
+	cr.Get("/jobs/{job_id}", jobHandler.GetJob)
+	cr.Get("/jobs/{job_id}"+jobs.ErrorReportPath, jobHandler.GetJobErrors)
+	cr.Post("/location", inventoryHandler.CreateLocation)
+	cr.Get("/location", inventoryHandler.ListLocation)
+	cr.Get("/variant/{variant_id}/stock", inventoryHandler.GetStock)
+	cr.Post("/variant/{variant_id}/stock", inventoryHandler.AdjustStock)
+	cr.Get("/variant/{variant_id}/stock/movements", inventoryHandler.ListMovement)
+	cr.Post("/variant/{variant_id}/reservations", inventoryHandler.CreateReservation)
+	cr.Get("/variant/{variant_id}/reservations/{reservation_id}", inventoryHandler.GetReservation)
+	cr.Post("/variant/{variant_id}/reservations/{reservation_id}/commit", inventoryHandler.CommitReservation)
+	cr.Post("/variant/{variant_id}/reservations/{reservation_id}/cancel", inventoryHandler.CancelReservation)
 	return cr
 }
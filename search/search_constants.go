@@ -0,0 +1,43 @@
+package search
+
+const (
+	//PriceBucketCount number of price buckets the facets aim for
+	PriceBucketCount = 5
+	//DefaultSearchLimit default number of results in a search page
+	DefaultSearchLimit = 20
+	//MaxSearchLimit maximum number of results in a search page
+	MaxSearchLimit = 100
+	//MaxQueryLength maximum length of a search query
+	MaxQueryLength = 200
+	//FuzzyThreshold minimum word similarity of a product name to the query in the typo tolerant search
+	FuzzyThreshold = "0.4"
+	//SnippetLength maximum number of characters of the description snippet of a typo tolerant match
+	SnippetLength = 160
+	//SearchIndexEnv environment variable selecting the search index implementation
+	SearchIndexEnv = "SearchIndex"
+	//PostgresIndexType search index on the trigger maintained search vectors of tbl_product, the default
+	PostgresIndexType = "postgres"
+	//MemoryIndexType search index held in the memory of the process, built from the database on start
+	MemoryIndexType = "memory"
+	//NameWeight weight of a word of the product name in the in-memory index
+	NameWeight = 1.0
+	//CategoryWeight weight of a word of the category path in the in-memory index
+	CategoryWeight = 0.4
+	//VariantWeight weight of a word of the variant names in the in-memory index
+	VariantWeight = 0.2
+	//DescriptionWeight weight of a word of the description in the in-memory index
+	DescriptionWeight = 0.1
+	//SnippetWords maximum number of words of a snippet of the in-memory index
+	SnippetWords = 30
+	//SnippetContext number of words kept before the first match in a snippet of the in-memory index
+	SnippetContext = 5
+	//highlightStart marks the start of a match in the ts_headline output, it is replaced by a mark tag once
+	//the snippet is HTML escaped and is removed from the text beforehand
+	highlightStart = "\x02"
+	//highlightStop marks the end of a match in the ts_headline output
+	highlightStop = "\x03"
+	//headlineOptions ts_headline options of the search snippets, the matches are wrapped in the highlight
+	//markers
+	headlineOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop +
+		`", MaxWords=30, MinWords=10, MaxFragments=2`
+)
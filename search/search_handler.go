@@ -0,0 +1,122 @@
+package search
+
+import (
+	"database/sql"
+	"ecommerce/pricing"
+	"ecommerce/utils"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//HandlerInterface for product search
+type HandlerInterface interface {
+	GetFacets(http.ResponseWriter, *http.Request)
+	Search(http.ResponseWriter, *http.Request)
+}
+
+//Handler struct for product search
+type Handler struct {
+	cs ServiceInterface
+}
+
+//NewHTTPHandler to handle search requests
+func NewHTTPHandler(db *sql.DB, index SearchIndex) HandlerInterface {
+	return &Handler{
+		cs: NewService(db, index),
+	}
+}
+
+//GetFacets to handle the search facets request
+func (h *Handler) GetFacets(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /search/facets GET API")
+	request, err := parseFacetRequest(r)
+	if err != nil {
+		log.Println("Error : request validation error (GetFacets)", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	facets, err := h.cs.GetFacets(request)
+	if err != nil {
+		log.Println("Error : error computing facets(GetFacets)", err.Error())
+		if err.Error() == utils.CategoryNOTExistsError {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Facets computed successfully, product count =", facets.ProductCount)
+	utils.Send(w, 200, facets)
+}
+
+func parseFacetRequest(r *http.Request) (*FacetRequest, error) {
+	query := r.URL.Query()
+	request := FacetRequest{
+		Currency: pricing.BaseCurrency(),
+	}
+	var err error
+	if value := query.Get("category_id"); value != utils.EmptyString {
+		request.CategoryID, err = strconv.Atoi(value)
+		if err != nil || request.CategoryID <= 0 {
+			return nil, errors.New(utils.InvalidCategoryID)
+		}
+	}
+	if value := query.Get("currency"); value != utils.EmptyString {
+		request.Currency = strings.ToUpper(value)
+		if !utils.IsValidCurrency(request.Currency) {
+			return nil, errors.New(utils.InvalidCurrencyError)
+		}
+	}
+	return &request, nil
+}
+
+//Search to handle the product search request
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /search GET API")
+	request, err := parseSearchRequest(r)
+	if err != nil {
+		log.Println("Error : request validation error (Search)", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	response, err := h.cs.Search(request)
+	if err != nil {
+		log.Println("Error : error searching products(Search)", err.Error())
+		if err.Error() == utils.InvalidSearchQueryError {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Products searched successfully, total =", response.Total)
+	utils.Send(w, 200, response)
+}
+
+func parseSearchRequest(r *http.Request) (*SearchRequest, error) {
+	query := r.URL.Query()
+	request := SearchRequest{
+		Query: query.Get("q"),
+		Limit: DefaultSearchLimit,
+	}
+	if len(request.Query) > MaxQueryLength {
+		return nil, errors.New(utils.InvalidSearchQueryError)
+	}
+	var err error
+	if value := query.Get("limit"); value != utils.EmptyString {
+		request.Limit, err = strconv.Atoi(value)
+		if err != nil || request.Limit <= 0 || request.Limit > MaxSearchLimit {
+			return nil, errors.New(utils.InvalidParameterError + " limit")
+		}
+	}
+	if value := query.Get("offset"); value != utils.EmptyString {
+		request.Offset, err = strconv.Atoi(value)
+		if err != nil || request.Offset < 0 {
+			return nil, errors.New(utils.InvalidParameterError + " offset")
+		}
+	}
+	return &request, nil
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"errors"
+	"os"
+	"strings"
+	"unicode"
+)
+
+//SearchIndex is the index the product search runs on, the product and variant services refresh the
+//products they change
+type SearchIndex interface {
+	//IndexProducts refreshes the given products from the database, deleted products are removed
+	IndexProducts(...int) error
+	//Search returns a page of the products matching the request
+	Search(*SearchRequest) (*SearchResponse, error)
+	//Rebuild rebuilds the index from the database, returning the number of products indexed
+	Rebuild() (int, error)
+}
+
+//NewIndex returns the search index selected by the SearchIndex environment variable
+func NewIndex(db *sql.DB) (SearchIndex, error) {
+	switch IndexType() {
+	case PostgresIndexType:
+		return NewPostgresIndex(db), nil
+	case MemoryIndexType:
+		return NewMemoryIndex(db), nil
+	}
+	return nil, errors.New(utils.InvalidSearchIndexError)
+}
+
+//IndexType returns the configured search index implementation
+func IndexType() string {
+	indexType, ok := os.LookupEnv(SearchIndexEnv)
+	if !ok || indexType == utils.EmptyString {
+		return PostgresIndexType
+	}
+	return strings.ToLower(indexType)
+}
+
+//tokenize to split the text into lower cased words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+//PostgresIndex searches the search vectors of tbl_product, which triggers keep up to date
+type PostgresIndex struct {
+	repo RepoInterface
+}
+
+//NewPostgresIndex returns the Postgres search index
+func NewPostgresIndex(db *sql.DB) SearchIndex {
+	return &PostgresIndex{
+		repo: NewRepo(db),
+	}
+}
+
+//IndexProducts to recompute the search vectors of the products
+func (index *PostgresIndex) IndexProducts(productIDs ...int) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	_, err := index.repo.RefreshSearchVectors(productIDs)
+	return err
+}
+
+//Rebuild to recompute the search vectors of every product
+func (index *PostgresIndex) Rebuild() (int, error) {
+	return index.repo.RefreshSearchVectors(nil)
+}
+
+//Search to get a page of the products matching the words of the query, the words can be the start of
+//a word. When no product matches, the products with names similar to the query are returned
+func (index *PostgresIndex) Search(request *SearchRequest) (*SearchResponse, error) {
+	response := SearchResponse{
+		Query:  request.Query,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}
+	var err error
+	response.Results, response.Total, err = index.repo.SearchProducts(request, prefixQuery(request.Query))
+	if err != nil {
+		return nil, err
+	}
+	if response.Total == 0 {
+		response.Fuzzy = true
+		response.Results, response.Total, err = index.repo.FuzzySearchProducts(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &response, nil
+}
+
+//prefixQuery to build the tsquery text matching all the words of the query as word prefixes, the
+//characters other than letters and digits only separate the words
+func prefixQuery(query string) string {
+	words := tokenize(query)
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
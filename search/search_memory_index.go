@@ -0,0 +1,287 @@
+package search
+
+import (
+	"database/sql"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//MemoryIndex is an inverted index of the product text held in memory, the words of the name weigh the
+//most, then the category path, the variant names and the description
+type MemoryIndex struct {
+	repo      RepoInterface
+	mutex     sync.RWMutex
+	documents map[int]Document
+	postings  map[string]map[int]float64 //word to the weight of the word in each product
+}
+
+//NewMemoryIndex returns an empty in-memory search index, Rebuild loads the products
+func NewMemoryIndex(db *sql.DB) SearchIndex {
+	return &MemoryIndex{
+		repo:      NewRepo(db),
+		documents: map[int]Document{},
+		postings:  map[string]map[int]float64{},
+	}
+}
+
+//IndexProducts to reload the products from the database, the products no longer live are removed
+func (index *MemoryIndex) IndexProducts(productIDs ...int) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	documents, err := index.repo.GetDocuments(productIDs)
+	if err != nil {
+		return err
+	}
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	for _, productID := range productIDs {
+		index.remove(productID)
+	}
+	for _, document := range documents {
+		index.add(document)
+	}
+	return nil
+}
+
+//Rebuild to replace the index with all the live products of the database
+func (index *MemoryIndex) Rebuild() (int, error) {
+	documents, err := index.repo.GetDocuments(nil)
+	if err != nil {
+		return 0, err
+	}
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.documents = map[int]Document{}
+	index.postings = map[string]map[int]float64{}
+	for _, document := range documents {
+		index.add(document)
+	}
+	return len(documents), nil
+}
+
+//add to index the words of the document, the caller holds the write lock
+func (index *MemoryIndex) add(document Document) {
+	index.documents[document.ProductID] = document
+	index.addText(document.ProductID, document.Name, NameWeight)
+	index.addText(document.ProductID, strings.Join(document.CategoryNames, " "), CategoryWeight)
+	index.addText(document.ProductID, strings.Join(document.VariantNames, " "), VariantWeight)
+	index.addText(document.ProductID, document.Description, DescriptionWeight)
+}
+
+//addText to add the weight of each word of the text to the postings of the product
+func (index *MemoryIndex) addText(productID int, text string, weight float64) {
+	for _, word := range tokenize(text) {
+		posting, ok := index.postings[word]
+		if !ok {
+			posting = map[int]float64{}
+			index.postings[word] = posting
+		}
+		posting[productID] += weight
+	}
+}
+
+//remove to drop the document and its postings, the caller holds the write lock
+func (index *MemoryIndex) remove(productID int) {
+	document, ok := index.documents[productID]
+	if !ok {
+		return
+	}
+	delete(index.documents, productID)
+	text := []string{document.Name, document.Description}
+	text = append(text, document.CategoryNames...)
+	text = append(text, document.VariantNames...)
+	for _, word := range tokenize(strings.Join(text, " ")) {
+		posting, ok := index.postings[word]
+		if !ok {
+			continue
+		}
+		delete(posting, productID)
+		if len(posting) == 0 {
+			delete(index.postings, word)
+		}
+	}
+}
+
+//Search to get a page of the products having a word starting with each word of the query, ranked by
+//the weight of the matching words. When no product matches, the products with names similar to the
+//query are returned
+func (index *MemoryIndex) Search(request *SearchRequest) (*SearchResponse, error) {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	words := tokenize(request.Query)
+	response := SearchResponse{
+		Query:  request.Query,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}
+	scores := index.match(words)
+	if len(scores) == 0 {
+		response.Fuzzy = true
+		scores = index.similar(strings.Join(words, " "))
+	}
+	response.Total = len(scores)
+	response.Results = []SearchResult{}
+	for _, productID := range rank(scores, request.Limit, request.Offset) {
+		document := index.documents[productID]
+		snippet := truncate(document.Description)
+		if !response.Fuzzy {
+			snippet = highlight(document.Description, words)
+			if snippet == "" {
+				snippet = highlight(document.Name, words)
+			}
+		}
+		response.Results = append(response.Results, SearchResult{
+			ProductID:  productID,
+			Name:       document.Name,
+			CategoryID: document.CategoryID,
+			Snippet:    snippet,
+			Score:      scores[productID],
+		})
+	}
+	return &response, nil
+}
+
+//match to score the products having a word starting with each of the words, the score of a word is
+//the weight of its best matching indexed word
+func (index *MemoryIndex) match(words []string) map[int]float64 {
+	var scores map[int]float64
+	for _, word := range words {
+		wordScores := map[int]float64{}
+		for indexed, posting := range index.postings {
+			if !strings.HasPrefix(indexed, word) {
+				continue
+			}
+			for productID, weight := range posting {
+				if weight > wordScores[productID] {
+					wordScores[productID] = weight
+				}
+			}
+		}
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for productID, score := range scores {
+			wordScore, ok := wordScores[productID]
+			if !ok {
+				delete(scores, productID)
+				continue
+			}
+			scores[productID] = score + wordScore
+		}
+	}
+	return scores
+}
+
+//similar to score the products whose name has words similar to the query, as the word similarity of
+//pg_trgm the score is the share of the trigrams of the query found in the name
+func (index *MemoryIndex) similar(query string) map[int]float64 {
+	scores := map[int]float64{}
+	queryTrigrams := trigrams(query)
+	if len(queryTrigrams) == 0 {
+		return scores
+	}
+	threshold, _ := strconv.ParseFloat(FuzzyThreshold, 64)
+	for productID, document := range index.documents {
+		nameTrigrams := trigrams(document.Name)
+		shared := 0
+		for trigram := range queryTrigrams {
+			if nameTrigrams[trigram] {
+				shared++
+			}
+		}
+		similarity := float64(shared) / float64(len(queryTrigrams))
+		if similarity >= threshold {
+			scores[productID] = similarity
+		}
+	}
+	return scores
+}
+
+//trigrams to get the trigrams of the words of the text, each word padded as pg_trgm does
+func trigrams(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range tokenize(text) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+//rank to get the page of product IDs by score, the lower product ID first on equal scores
+func rank(scores map[int]float64, limit, offset int) []int {
+	productIDs := make([]int, 0, len(scores))
+	for productID := range scores {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Slice(productIDs, func(i, j int) bool {
+		if scores[productIDs[i]] != scores[productIDs[j]] {
+			return scores[productIDs[i]] > scores[productIDs[j]]
+		}
+		return productIDs[i] < productIDs[j]
+	})
+	if offset >= len(productIDs) {
+		return nil
+	}
+	productIDs = productIDs[offset:]
+	if len(productIDs) > limit {
+		productIDs = productIDs[:limit]
+	}
+	return productIDs
+}
+
+//highlight to get the HTML escaped text around the first word starting with a query word, the matching
+//words wrapped in mark tags, empty when no word matches
+func highlight(text string, words []string) string {
+	fields := strings.Fields(text)
+	first := -1
+	for i, field := range fields {
+		if !matchesAny(field, words) {
+			fields[i] = html.EscapeString(field)
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		fields[i] = "<mark>" + html.EscapeString(field) + "</mark>"
+	}
+	if first < 0 {
+		return ""
+	}
+	start := first - SnippetContext
+	if start < 0 {
+		start = 0
+	}
+	end := start + SnippetWords
+	if end > len(fields) {
+		end = len(fields)
+	}
+	return strings.Join(fields[start:end], " ")
+}
+
+//matchesAny to check if a word of the field starts with one of the query words
+func matchesAny(field string, words []string) bool {
+	for _, fieldWord := range tokenize(field) {
+		for _, word := range words {
+			if strings.HasPrefix(fieldWord, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//truncate to cut the text to the snippet length and HTML escape it
+func truncate(text string) string {
+	runes := []rune(text)
+	if len(runes) > SnippetLength {
+		return html.EscapeString(string(runes[:SnippetLength]))
+	}
+	return html.EscapeString(text)
+}
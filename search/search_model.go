@@ -0,0 +1,94 @@
+package search
+
+import "ecommerce/utils"
+
+//FacetRequest to represent the facet request of a category subtree, all the products when the
+//category is 0, with the price buckets in the currency
+type FacetRequest struct {
+	CategoryID int
+	Currency   string
+}
+
+//Facets to represent the counts of the products for each filter value of the storefront
+type Facets struct {
+	CategoryID   int           `json:"category_id,omitempty"`
+	ProductCount int           `json:"product_count"`
+	Sizes        []FacetValue  `json:"sizes"`
+	Colors       []FacetValue  `json:"colors"`
+	Brands       []FacetValue  `json:"brands"`
+	PriceBuckets []PriceBucket `json:"price_buckets"`
+	Options      []Facet       `json:"options"`
+	Attributes   []Facet       `json:"attributes"`
+}
+
+//Facet to represent the values of a variant option or product attribute with their product counts
+type Facet struct {
+	Name   string       `json:"name"`
+	Values []FacetValue `json:"values"`
+}
+
+//FacetValue to represent the number of products having a value
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+//PriceBucket to represent the number of products whose lowest variant price is from the start up to
+//but excluding the end of the bucket
+type PriceBucket struct {
+	From  utils.Money `json:"from"`
+	To    utils.Money `json:"to"`
+	Count int         `json:"count"`
+}
+
+//FacetRow to represent a name, value and product count row of the facet queries
+type FacetRow struct {
+	Name  string
+	Value string
+	Count int
+}
+
+//PriceRange to represent the lowest and highest product price of the facet request
+type PriceRange struct {
+	Min   int64
+	Max   int64
+	Count int
+}
+
+//SearchRequest to represent the product search request
+type SearchRequest struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+//SearchResponse to represent a page of the products matching a search query, fuzzy is true when
+//no product matched the query and the results are the products with similar names
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+	Fuzzy   bool           `json:"fuzzy"`
+	Results []SearchResult `json:"results"`
+}
+
+//SearchResult to represent a product matching a search query, the snippet is HTML escaped with the
+//matching words wrapped in mark tags
+type SearchResult struct {
+	ProductID  int     `json:"product_id"`
+	Name       string  `json:"product_name"`
+	CategoryID int     `json:"category_id"`
+	Snippet    string  `json:"snippet,omitempty"`
+	Score      float64 `json:"score"`
+}
+
+//Document to represent the searchable text of a product
+type Document struct {
+	ProductID     int
+	Name          string
+	Description   string
+	CategoryID    int
+	CategoryNames []string //names of the category of the product and its ancestors
+	VariantNames  []string //names of the live variants of the product
+}
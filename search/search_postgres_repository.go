@@ -0,0 +1,441 @@
+package search
+
+import (
+	"database/sql"
+	"ecommerce/pricing"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+//Repo is the DB repo struct
+type Repo struct {
+	DB *sql.DB
+}
+
+// scopeQuery selects the products of the facet request, the products of the category subtree of $1
+// or every product when $1 is 0, with the lowest effective price of their variants in the currency $2.
+// The price of a variant is its default channel price list entry in $2 and otherwise its own price when
+// it is in $2, variants priced in other currencies only are left out as prices are not converted here
+var scopeQuery = fmt.Sprintf(`
+		WITH RECURSIVE subtree AS (
+			SELECT
+				category_id
+			FROM
+				tbl_category
+			WHERE
+				category_id = $1
+			AND
+				deleted_at IS NULL
+			UNION
+			SELECT
+				c.category_id
+			FROM
+				tbl_category c
+			JOIN
+				subtree s
+			ON
+				c.parent_category_id = s.category_id
+			WHERE
+				c.deleted_at IS NULL
+		), products AS (
+			SELECT
+				p.product_id
+			FROM
+				tbl_product p
+			WHERE
+				p.deleted_at IS NULL
+			AND
+				($1 = 0 OR p.category_id IN (SELECT category_id FROM subtree))
+		), prices AS (
+			SELECT
+				v.product_id,
+				MIN(COALESCE(%s, %s)) AS price
+			FROM
+				tbl_variant v
+			JOIN
+				products p
+			ON
+				p.product_id = v.product_id
+			LEFT JOIN
+				tbl_variant_price vp
+			ON
+				vp.variant_id = v.variant_id
+			AND
+				vp.currency = $2
+			AND
+				vp.channel = %s
+			WHERE
+				v.deleted_at IS NULL
+			AND
+				(vp.variant_id IS NOT NULL OR v.currency = $2)
+			GROUP BY
+				v.product_id
+		)
+`, pricing.EffectivePriceSQL("vp", "NOW()"), pricing.EffectivePriceSQL("v", "NOW()"), pq.QuoteLiteral(pricing.DefaultChannel))
+
+//CheckCategoryExists function to check if the given category exist in our DB
+func (repo *Repo) CheckCategoryExists(categoryID int) (bool, error) {
+	var count int
+	query := `
+		SELECT
+			count(*)
+		FROM
+			tbl_category
+		WHERE
+			category_id = $1
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, categoryID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//CountProducts to count the products of the facet request
+func (repo *Repo) CountProducts(request *FacetRequest) (int, error) {
+	var count int
+	query := scopeQuery + `
+		SELECT
+			count(*)
+		FROM
+			products
+	`
+	err := repo.DB.QueryRow(query, request.CategoryID, request.Currency).Scan(&count)
+	return count, err
+}
+
+//GetOptionFacets to count the products having a variant with each option value, option names that only
+//differ in case are counted together
+func (repo *Repo) GetOptionFacets(request *FacetRequest) ([]FacetRow, error) {
+	query := scopeQuery + `
+		SELECT
+			MIN(vo.option_name), vo.value, COUNT(DISTINCT v.product_id)
+		FROM
+			tbl_variant v
+		JOIN
+			products p
+		ON
+			p.product_id = v.product_id
+		JOIN
+			vw_variant_option vo
+		ON
+			vo.variant_id = v.variant_id
+		WHERE
+			v.deleted_at IS NULL
+		GROUP BY
+			lower(vo.option_name),
+			vo.value
+		ORDER BY
+			lower(vo.option_name) ASC,
+			COUNT(DISTINCT v.product_id) DESC,
+			vo.value ASC
+	`
+	return repo.getFacetRows(query, request)
+}
+
+//GetAttributeFacets to count the products having each attribute value
+func (repo *Repo) GetAttributeFacets(request *FacetRequest) ([]FacetRow, error) {
+	query := scopeQuery + `
+		SELECT
+			MIN(ca.name), pa.value_text, COUNT(*)
+		FROM
+			tbl_product_attribute pa
+		JOIN
+			products p
+		ON
+			p.product_id = pa.product_id
+		JOIN
+			tbl_category_attribute ca
+		ON
+			ca.attribute_id = pa.attribute_id
+		GROUP BY
+			lower(ca.name),
+			pa.value_text
+		ORDER BY
+			lower(ca.name) ASC,
+			COUNT(*) DESC,
+			pa.value_text ASC
+	`
+	return repo.getFacetRows(query, request)
+}
+
+func (repo *Repo) getFacetRows(query string, request *FacetRequest) ([]FacetRow, error) {
+	rows, err := repo.DB.Query(query, request.CategoryID, request.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var facetRows []FacetRow
+	for rows.Next() {
+		var row FacetRow
+		err := rows.Scan(&row.Name, &row.Value, &row.Count)
+		if err != nil {
+			return nil, err
+		}
+		facetRows = append(facetRows, row)
+	}
+	return facetRows, rows.Err()
+}
+
+//GetPriceRange to get the lowest and highest product price of the facet request
+func (repo *Repo) GetPriceRange(request *FacetRequest) (*PriceRange, error) {
+	var priceRange PriceRange
+	var min, max sql.NullInt64
+	query := scopeQuery + `
+		SELECT
+			MIN(price), MAX(price), COUNT(*)
+		FROM
+			prices
+	`
+	err := repo.DB.QueryRow(query, request.CategoryID, request.Currency).Scan(&min, &max, &priceRange.Count)
+	if err != nil {
+		return nil, err
+	}
+	priceRange.Min = min.Int64
+	priceRange.Max = max.Int64
+	return &priceRange, nil
+}
+
+//GetPriceBuckets to count the products in each price bucket of the given size, keyed by the bucket index
+func (repo *Repo) GetPriceBuckets(request *FacetRequest, size int64) (map[int64]int, error) {
+	query := scopeQuery + `
+		SELECT
+			price / $3 AS bucket, COUNT(*)
+		FROM
+			prices
+		GROUP BY
+			bucket
+	`
+	rows, err := repo.DB.Query(query, request.CategoryID, request.Currency, size)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	buckets := make(map[int64]int)
+	for rows.Next() {
+		var bucket int64
+		var count int
+		err := rows.Scan(&bucket, &count)
+		if err != nil {
+			return nil, err
+		}
+		buckets[bucket] = count
+	}
+	return buckets, rows.Err()
+}
+
+//SearchProducts to get a page of the products matching the text search query, ranked by relevance,
+//with the number of matching products
+func (repo *Repo) SearchProducts(request *SearchRequest, tsQuery string) ([]SearchResult, int, error) {
+	query := `
+		WITH matches AS (
+			SELECT
+				p.product_id, p.name, p.category_id, p.description, ts_rank_cd(p.search_vector, q) AS score, q
+			FROM
+				tbl_product p,
+				to_tsquery('english', $1) q
+			WHERE
+				p.deleted_at IS NULL
+			AND
+				p.search_vector @@ q
+		), page AS (
+			SELECT
+				*
+			FROM
+				matches
+			ORDER BY
+				score DESC,
+				product_id ASC
+			LIMIT $2
+			OFFSET $3
+		)
+		SELECT
+			t.total, pg.product_id, pg.name, pg.category_id,
+			ts_headline('english', translate(COALESCE(NULLIF(pg.description, ''), pg.name), $5, ''), pg.q, $4),
+			pg.score
+		FROM
+			(SELECT count(*) AS total FROM matches) t
+		LEFT JOIN
+			page pg
+		ON
+			TRUE
+		ORDER BY
+			pg.score DESC,
+			pg.product_id ASC
+	`
+	rows, err := repo.DB.Query(query, tsQuery, request.Limit, request.Offset, headlineOptions,
+		highlightStart+highlightStop)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	return scanSearchResults(rows)
+}
+
+//FuzzySearchProducts to get a page of the products whose name has words similar to the query, with the
+//number of similar products. The trigram index is used through the word similarity threshold set for
+//the transaction
+func (repo *Repo) FuzzySearchProducts(request *SearchRequest) ([]SearchResult, int, error) {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)", FuzzyThreshold)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := `
+		WITH matches AS (
+			SELECT
+				p.product_id, p.name, p.category_id, p.description, word_similarity(lower($1), lower(p.name)) AS score
+			FROM
+				tbl_product p
+			WHERE
+				p.deleted_at IS NULL
+			AND
+				lower($1) <% lower(p.name)
+		), page AS (
+			SELECT
+				*
+			FROM
+				matches
+			ORDER BY
+				score DESC,
+				product_id ASC
+			LIMIT $2
+			OFFSET $3
+		)
+		SELECT
+			t.total, pg.product_id, pg.name, pg.category_id, LEFT(translate(COALESCE(pg.description, ''), $5, ''), $4),
+			pg.score
+		FROM
+			(SELECT count(*) AS total FROM matches) t
+		LEFT JOIN
+			page pg
+		ON
+			TRUE
+		ORDER BY
+			pg.score DESC,
+			pg.product_id ASC
+	`
+	rows, err := tx.Query(query, request.Query, request.Limit, request.Offset, SnippetLength,
+		highlightStart+highlightStop)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	return scanSearchResults(rows)
+}
+
+//scanSearchResults to scan the total and the page of results, a page past the last match is a single
+//row with the total only
+func scanSearchResults(rows *sql.Rows) ([]SearchResult, int, error) {
+	results := []SearchResult{}
+	total := 0
+	for rows.Next() {
+		var productID, categoryID sql.NullInt64
+		var name, snippet sql.NullString
+		var score sql.NullFloat64
+		err := rows.Scan(&total, &productID, &name, &categoryID, &snippet, &score)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !productID.Valid {
+			continue
+		}
+		results = append(results, SearchResult{
+			ProductID:  int(productID.Int64),
+			Name:       name.String,
+			CategoryID: int(categoryID.Int64),
+			Snippet:    escapeSnippet(snippet.String),
+			Score:      score.Float64,
+		})
+	}
+	return results, total, rows.Err()
+}
+
+//escapeSnippet to HTML escape a snippet and turn the highlight markers ts_headline put around the
+//matches into mark tags, the text is stripped of the markers before ts_headline runs
+func escapeSnippet(snippet string) string {
+	return snippetReplacer.Replace(html.EscapeString(snippet))
+}
+
+var snippetReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+//RefreshSearchVectors to recompute the search vectors of the given products, of every product when nil,
+//returning the number of products refreshed
+func (repo *Repo) RefreshSearchVectors(productIDs []int) (int, error) {
+	query := `
+		SELECT
+			fn_refresh_product_search(product_id)
+		FROM
+			tbl_product
+		WHERE
+			$1::INT[] IS NULL
+		OR
+			product_id = ANY($1)
+	`
+	result, err := repo.DB.Exec(query, pq.Array(productIDs))
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+//GetDocuments to get the searchable text of the given live products, of every live product when nil
+func (repo *Repo) GetDocuments(productIDs []int) ([]Document, error) {
+	query := `
+		SELECT
+			p.product_id, p.name, COALESCE(p.description, ''), p.category_id,
+			ARRAY(
+				WITH RECURSIVE ancestry AS (
+					SELECT category_id, name, parent_category_id, ARRAY[category_id] AS path
+					FROM tbl_category WHERE category_id = p.category_id
+					UNION ALL
+					SELECT c.category_id, c.name, c.parent_category_id, a.path || c.category_id
+					FROM tbl_category c JOIN ancestry a ON c.category_id = a.parent_category_id
+					WHERE NOT c.category_id = ANY(a.path)
+				)
+				SELECT name FROM ancestry
+			),
+			ARRAY(
+				SELECT v.name FROM tbl_variant v
+				WHERE v.product_id = p.product_id AND v.deleted_at IS NULL AND COALESCE(v.name, '') <> ''
+				ORDER BY v.variant_id
+			)
+		FROM
+			tbl_product p
+		WHERE
+			p.deleted_at IS NULL
+		AND
+			($1::INT[] IS NULL OR p.product_id = ANY($1))
+		ORDER BY
+			p.product_id ASC
+	`
+	rows, err := repo.DB.Query(query, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var documents []Document
+	for rows.Next() {
+		var document Document
+		var categoryNames, variantNames pq.StringArray
+		err := rows.Scan(&document.ProductID, &document.Name, &document.Description, &document.CategoryID,
+			&categoryNames, &variantNames)
+		if err != nil {
+			return nil, err
+		}
+		document.CategoryNames = categoryNames
+		document.VariantNames = variantNames
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
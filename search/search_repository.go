@@ -0,0 +1,24 @@
+package search
+
+import "database/sql"
+
+//RepoInterface for DB operations
+type RepoInterface interface {
+	CheckCategoryExists(int) (bool, error)
+	CountProducts(*FacetRequest) (int, error)
+	GetOptionFacets(*FacetRequest) ([]FacetRow, error)
+	GetAttributeFacets(*FacetRequest) ([]FacetRow, error)
+	GetPriceRange(*FacetRequest) (*PriceRange, error)
+	GetPriceBuckets(*FacetRequest, int64) (map[int64]int, error)
+	SearchProducts(*SearchRequest, string) ([]SearchResult, int, error)
+	FuzzySearchProducts(*SearchRequest) ([]SearchResult, int, error)
+	RefreshSearchVectors([]int) (int, error)
+	GetDocuments([]int) ([]Document, error)
+}
+
+//NewRepo returns repository interface
+func NewRepo(db *sql.DB) RepoInterface {
+	return &Repo{
+		DB: db,
+	}
+}
@@ -0,0 +1,153 @@
+package search
+
+import (
+	"database/sql"
+	"ecommerce/utils"
+	"errors"
+	"strings"
+)
+
+//ServiceInterface is search service interface
+type ServiceInterface interface {
+	GetFacets(*FacetRequest) (*Facets, error)
+	Search(*SearchRequest) (*SearchResponse, error)
+}
+
+//Service struct for service functionalities
+type Service struct {
+	repo  RepoInterface
+	index SearchIndex
+}
+
+//NewService :
+func NewService(db *sql.DB, index SearchIndex) ServiceInterface {
+	return &Service{
+		repo:  NewRepo(db),
+		index: index,
+	}
+}
+
+//GetFacets to count the products of the category subtree for each size, color, brand, price bucket,
+//option value and attribute value
+func (service *Service) GetFacets(request *FacetRequest) (*Facets, error) {
+	if request.CategoryID != 0 {
+		categoryExists, err := service.repo.CheckCategoryExists(request.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if !categoryExists {
+			return nil, errors.New(utils.CategoryNOTExistsError)
+		}
+	}
+	facets := Facets{
+		CategoryID:   request.CategoryID,
+		Sizes:        []FacetValue{},
+		Colors:       []FacetValue{},
+		Brands:       []FacetValue{},
+		PriceBuckets: []PriceBucket{},
+		Options:      []Facet{},
+		Attributes:   []Facet{},
+	}
+	var err error
+	facets.ProductCount, err = service.repo.CountProducts(request)
+	if err != nil {
+		return nil, err
+	}
+	optionRows, err := service.repo.GetOptionFacets(request)
+	if err != nil {
+		return nil, err
+	}
+	for _, facet := range groupFacets(optionRows) {
+		switch strings.ToLower(facet.Name) {
+		case strings.ToLower(utils.SizeOption):
+			facets.Sizes = facet.Values
+		case strings.ToLower(utils.ColorOption):
+			facets.Colors = facet.Values
+		default:
+			facets.Options = append(facets.Options, facet)
+		}
+	}
+	attributeRows, err := service.repo.GetAttributeFacets(request)
+	if err != nil {
+		return nil, err
+	}
+	for _, facet := range groupFacets(attributeRows) {
+		if strings.EqualFold(facet.Name, utils.BrandAttribute) {
+			facets.Brands = facet.Values
+			continue
+		}
+		facets.Attributes = append(facets.Attributes, facet)
+	}
+	facets.PriceBuckets, err = service.getPriceBuckets(request)
+	if err != nil {
+		return nil, err
+	}
+	return &facets, nil
+}
+
+//groupFacets to group the facet rows, ordered by name, into a facet for each name
+func groupFacets(rows []FacetRow) []Facet {
+	var facets []Facet
+	for _, row := range rows {
+		if len(facets) == 0 || !strings.EqualFold(facets[len(facets)-1].Name, row.Name) {
+			facets = append(facets, Facet{Name: row.Name})
+		}
+		last := &facets[len(facets)-1]
+		last.Values = append(last.Values, FacetValue{
+			Value: row.Value,
+			Count: row.Count,
+		})
+	}
+	return facets
+}
+
+//getPriceBuckets to count the products in price buckets of a round size covering the price range of
+//the products, the empty buckets are left out
+func (service *Service) getPriceBuckets(request *FacetRequest) ([]PriceBucket, error) {
+	buckets := []PriceBucket{}
+	priceRange, err := service.repo.GetPriceRange(request)
+	if err != nil {
+		return nil, err
+	}
+	if priceRange.Count == 0 {
+		return buckets, nil
+	}
+	size := bucketSize(priceRange.Min, priceRange.Max)
+	counts, err := service.repo.GetPriceBuckets(request, size)
+	if err != nil {
+		return nil, err
+	}
+	for bucket := priceRange.Min / size; bucket <= priceRange.Max/size; bucket++ {
+		if counts[bucket] == 0 {
+			continue
+		}
+		buckets = append(buckets, PriceBucket{
+			From:  utils.Money{Amount: bucket * size, Currency: request.Currency},
+			To:    utils.Money{Amount: (bucket + 1) * size, Currency: request.Currency},
+			Count: counts[bucket],
+		})
+	}
+	return buckets, nil
+}
+
+//bucketSize returns the smallest of 1, 2 and 5 times a power of ten splitting the price range into
+//at most about PriceBucketCount buckets
+func bucketSize(min int64, max int64) int64 {
+	target := (max - min) / PriceBucketCount
+	for scale := int64(1); ; scale *= 10 {
+		for _, step := range []int64{1, 2, 5} {
+			if step*scale >= target {
+				return step * scale
+			}
+		}
+	}
+}
+
+//Search to get a page of the products matching the words of the query from the search index
+func (service *Service) Search(request *SearchRequest) (*SearchResponse, error) {
+	request.Query = strings.TrimSpace(request.Query)
+	if len(tokenize(request.Query)) == 0 {
+		return nil, errors.New(utils.InvalidSearchQueryError)
+	}
+	return service.index.Search(request)
+}
@@ -0,0 +1,32 @@
+package utils
+
+//gtinLengths are the digit counts of the GTIN-8, UPC-A (GTIN-12), EAN-13 and GTIN-14 barcodes
+var gtinLengths = map[int]bool{
+	8:  true,
+	12: true,
+	13: true,
+	14: true,
+}
+
+//IsValidGTIN to check if the code is a GTIN-8, UPC-A, EAN-13 or GTIN-14 barcode with a valid check digit
+func IsValidGTIN(code string) bool {
+	if !gtinLengths[len(code)] {
+		return false
+	}
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+		digit := int(code[i] - '0')
+		if i == len(code)-1 {
+			continue
+		}
+		//the digits are weighted 3 and 1 alternately starting from the one left of the check digit
+		if (len(code)-1-i)%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
@@ -3,4 +3,31 @@ package utils
 const (
 	//EmptyString denotes null string
 	EmptyString = ""
-)
\ No newline at end of file
+
+	//DefaultCurrency currency used when a price doesn't specify one
+	DefaultCurrency = "INR"
+
+	//AttributeString category attribute type of text values
+	AttributeString = "string"
+
+	//AttributeInt category attribute type of integer values
+	AttributeInt = "int"
+
+	//AttributeFloat category attribute type of decimal values
+	AttributeFloat = "float"
+
+	//AttributeBool category attribute type of true or false values
+	AttributeBool = "bool"
+
+	//AttributeEnum category attribute type of values from a fixed list
+	AttributeEnum = "enum"
+
+	//SizeOption name of the product option holding the size of the variants
+	SizeOption = "Size"
+
+	//ColorOption name of the product option holding the color of the variants
+	ColorOption = "Color"
+
+	//BrandAttribute name of the category attribute holding the brand of the products
+	BrandAttribute = "Brand"
+)
@@ -51,4 +51,232 @@ const (
 
 	//NoDataFoundError to show no data found in DB
 	NoDataFoundError = "No data found"
+
+	//InvalidCursorError to show the pagination cursor can't be decoded
+	InvalidCursorError = "Invalid cursor"
+
+	//InvalidSortError to show the sort parameter is not supported
+	InvalidSortError = "Invalid sort parameter"
+
+	//InvalidPriceRangeError to show the price range is invalid
+	InvalidPriceRangeError = "Invalid price range"
+
+	//ParentCategoryNotExist to show the given parent category doesn't exist
+	ParentCategoryNotExist = "Parent category doesn't exist"
+
+	//CategoryCycleError to show the category can't be moved under itself or its sub category
+	CategoryCycleError = "Category can't be moved under itself or its sub category"
+
+	//AttributeConflictError to show the category can't be moved under a parent that defines an attribute of the same name
+	AttributeConflictError = "Attribute name of the category or its sub categories is already used by the new parent categories"
+
+	//LocationNotExist to show the given location doesn't exist
+	LocationNotExist = "Location doesn't exist"
+
+	//InsufficientStockError to show there is not enough stock for the request
+	InsufficientStockError = "Insufficient stock"
+
+	//ReservationNotExist to show the given stock reservation doesn't exist
+	ReservationNotExist = "Reservation doesn't exist"
+
+	//ReservationNotActive to show the stock reservation is already committed, cancelled or expired
+	ReservationNotActive = "Reservation is not active"
+
+	//InvalidCurrencyError to show the currency code is not a supported ISO 4217 code
+	InvalidCurrencyError = "Invalid currency code"
+
+	//InvalidPriceError to show the price amount is invalid
+	InvalidPriceError = "Price must be greater than zero"
+
+	//CurrencyMismatchError to show the discount price and max retail price currencies differ
+	CurrencyMismatchError = "Discount price currency must match the max retail price currency"
+
+	//DiscountExceedsMRPError to show the discount price is more than the max retail price
+	DiscountExceedsMRPError = "Discount price can't be greater than the max retail price"
+
+	//PriceNotExist to show the price list entry doesn't exist
+	PriceNotExist = "Price doesn't exist for the given currency and channel"
+
+	//InvalidRateError to show the conversion rate is invalid
+	InvalidRateError = "Conversion rate must be a number greater than zero"
+
+	//RateNotExist to show there is no conversion rate for the currency
+	RateNotExist = "Conversion rate doesn't exist for the currency"
+
+	//InvalidDiscountWindowError to show the discount validity window is invalid
+	InvalidDiscountWindowError = "Discount valid_from must be before valid_until"
+
+	//InvalidAsOfError to show the as_of time can't be parsed
+	InvalidAsOfError = "Invalid as_of time, expected RFC3339 format"
+
+	//InvalidEffectiveAtError to show the scheduled price change is not in the future
+	InvalidEffectiveAtError = "Scheduled price change must take effect in the future"
+
+	//ScheduleNotExist to show the scheduled price change doesn't exist
+	ScheduleNotExist = "Scheduled price change doesn't exist"
+
+	//ScheduleNotPending to show the scheduled price change is already applied or cancelled
+	ScheduleNotPending = "Scheduled price change is not pending"
+
+	//PromotionNotExist to show the promotion doesn't exist
+	PromotionNotExist = "Promotion doesn't exist"
+
+	//InvalidRuleTypeError to show the promotion rule type is not supported
+	InvalidRuleTypeError = "Invalid promotion rule type"
+
+	//InvalidScopeError to show the promotion scope is not supported
+	InvalidScopeError = "Invalid promotion scope"
+
+	//PromotionTargetNotExist to show the category, product or variant of the promotion scope doesn't exist
+	PromotionTargetNotExist = "Promotion scope target doesn't exist"
+
+	//InvalidPromotionValueError to show the promotion values don't match the rule type
+	InvalidPromotionValueError = "Invalid promotion value for the rule type"
+
+	//InvalidPromotionWindowError to show the promotion validity window is invalid
+	InvalidPromotionWindowError = "Promotion valid_from must be before valid_until"
+
+	//CouponExistsError to show the coupon code is used by another promotion
+	CouponExistsError = "Coupon code already exists"
+
+	//InvalidCouponError to show the coupon code doesn't match an active promotion
+	InvalidCouponError = "Coupon code is invalid or expired"
+
+	//QuoteCurrencyMismatchError to show the quote items are priced in different currencies
+	QuoteCurrencyMismatchError = "Quote items have prices in different currencies, a currency is required"
+
+	//NothingToUpdateInPromotion to show the promotion update request has no fields
+	NothingToUpdateInPromotion = "Nothing to update in promotion"
+
+	//OptionNotExist to show the option is not defined for the product
+	OptionNotExist = "Option doesn't exist for the product"
+
+	//OptionValueNotExist to show the value is not defined for the option
+	OptionValueNotExist = "Option value doesn't exist for the option"
+
+	//OptionExistsError to show the product already has an option with the name
+	OptionExistsError = "Option already exists for the product"
+
+	//OptionInUseError to show the option can't be deleted while variants use it
+	OptionInUseError = "Option is used by the variants of the product"
+
+	//DuplicateOptionError to show an option is given more than once for a variant
+	DuplicateOptionError = "Option is given more than once"
+
+	//OptionNameError to show the option name is empty
+	OptionNameError = "Option name can't be empty"
+
+	//OptionValueError to show no option value is given
+	OptionValueError = "Option values can't be empty"
+
+	//VariantOptionsExistError to show another variant of the product has the same option values
+	VariantOptionsExistError = "A variant with the same options already exists for the product"
+
+	//TooManyVariantsError to show the variant generation request gives too many combinations
+	TooManyVariantsError = "Too many option combinations to generate"
+
+	//InvalidPatternError to show the name or SKU pattern has an unknown placeholder
+	InvalidPatternError = "Pattern has a placeholder that is not an option of the request"
+
+	//SKUPatternError to show the SKU pattern doesn't give a unique SKU to each generated variant
+	SKUPatternError = "SKU pattern doesn't give a unique SKU of at most 64 characters for each variant"
+
+	//InvalidBarcodeError to show the barcode is not a GTIN with a valid check digit
+	InvalidBarcodeError = "Barcode must be a GTIN-8, UPC-A, EAN-13 or GTIN-14 code with a valid check digit"
+
+	//SKUExistsError to show another variant already has the SKU
+	SKUExistsError = "A variant with the SKU already exists"
+
+	//BarcodeExistsError to show another variant already has the barcode
+	BarcodeExistsError = "A variant with the barcode already exists"
+
+	//SKUNotExist to show no variant has the SKU
+	SKUNotExist = "No variant has the SKU"
+
+	//BarcodeNotExist to show no variant has the barcode
+	BarcodeNotExist = "No variant has the barcode"
+
+	//AttributeExistsError to show the attribute name is already used in the category tree
+	AttributeExistsError = "Attribute already exists for the category, its parents or its sub categories"
+
+	//InvalidAttributeError to show the values don't match the attribute type
+	InvalidAttributeError = "Enum attributes need values and other attribute types can't have them"
+
+	//AttributeNotExist to show the attribute is not defined by the category
+	AttributeNotExist = "Attribute doesn't exist for the category"
+
+	//AttributeInUseError to show the attribute can't be deleted while products have values for it
+	AttributeInUseError = "Attribute has values for products"
+
+	//UnknownAttributeError to show the attribute is not defined for the category of the product
+	UnknownAttributeError = "Attribute is not defined for the category of the product"
+
+	//InvalidAttributeValueError to show the attribute value doesn't match the attribute type
+	InvalidAttributeValueError = "Attribute value doesn't match the attribute type"
+
+	//AttributeRequiredError to show a required attribute has no value
+	AttributeRequiredError = "Required attribute is missing"
+
+	//InvalidAttributeFilterError to show the attribute filter of the listing is not valid
+	InvalidAttributeFilterError = "Invalid attribute filter"
+
+	//InvalidSearchQueryError to show the search query has no words or is too long
+	InvalidSearchQueryError = "Search query must have a word and at most 200 characters"
+
+	//InvalidSearchIndexError to show the configured search index is not supported
+	InvalidSearchIndexError = "SearchIndex environment variable must be postgres or memory"
+
+	//InvalidImportFileError to show the import file is not a CSV with the required columns
+	InvalidImportFileError = "Import file must be a CSV with a header row having the category, product_name and max_retail_price columns, each column once"
+
+	//TooManyImportRowsError to show the import file has more rows than an import takes
+	TooManyImportRowsError = "Import file can't have more than 10000 rows"
+
+	//ImportFileReadError to show the import file couldn't be read to the end
+	ImportFileReadError = "Import file can't be read, it must be at most 32 MB"
+
+	//InvalidImportRowError to show a row of the import file doesn't match its header
+	InvalidImportRowError = "Row doesn't match the columns of the header"
+
+	//InvalidImportValueError to show a value of an import row can't be parsed
+	InvalidImportValueError = "Invalid value in column"
+
+	//CategoryPathError to show the category path of an import row has an empty category name
+	CategoryPathError = "Category path must have category names separated by >"
+
+	//ImportCategoryMismatchError to show the product of an import row exists in another category
+	ImportCategoryMismatchError = "Product already exists in another category"
+
+	//JobNotExist to show the job doesn't exist
+	JobNotExist = "Job doesn't exist"
+
+	//InvalidJobID to show the job ID is not valid
+	InvalidJobID = "Invalid job ID"
+
+	//InvalidExportFormatError to show the export format is not supported
+	InvalidExportFormatError = "Export format must be csv, jsonl or xlsx"
+
+	//InvalidFeedFormatError to show the feed format is not supported
+	InvalidFeedFormatError = "Feed format must be google or facebook"
+
+	//StorefrontURLError to show the storefront URL the feed links to is not configured
+	StorefrontURLError = "StorefrontURL environment variable must be an http or https URL"
+
+	//MediaNotExist to show the image is not in the gallery of the product
+	MediaNotExist = "Media doesn't exist for the product"
+
+	//InvalidMediaID to show the media ID is not valid
+	InvalidMediaID = "Invalid media ID"
+
+	//MediaVariantError to show a variant of the image is not a variant of the product
+	MediaVariantError = "Media variants must be variants of the product"
+
+	//MediaOrderError to show the reorder request doesn't list each image of the gallery once
+	MediaOrderError = "Media order must list each media of the product once"
+
+	//NothingToUpdateInMedia to show when nothing to update in a media update request
+	NothingToUpdateInMedia = "Nothing to update in media"
+
+	//PrimaryMediaError to show the primary flag can only be set, another image is made primary instead
+	PrimaryMediaError = "Set another media as primary to unset the primary media"
 )
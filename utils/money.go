@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//Money to represent an amount in the minor unit (paise, cents) of its ISO 4217 currency
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+//currencyExponents maps the supported ISO 4217 currency codes to their number of minor unit digits
+var currencyExponents = map[string]int{
+	"AED": 2,
+	"AUD": 2,
+	"BHD": 3,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"SGD": 2,
+	"USD": 2,
+}
+
+//IsValidCurrency to check if the currency code is a supported ISO 4217 code
+func IsValidCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+	return ok
+}
+
+//CurrencyExponent returns the number of minor unit digits of the currency
+func CurrencyExponent(currency string) int {
+	return currencyExponents[currency]
+}
+
+//String formats the money in major units, e.g. 49999 INR as "499.99 INR"
+func (m Money) String() string {
+	exponent := CurrencyExponent(m.Currency)
+	amount := m.Amount
+	sign := EmptyString
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if exponent == 0 {
+		return fmt.Sprintf("%s%d %s", sign, amount, m.Currency)
+	}
+	unit := int64(1)
+	for i := 0; i < exponent; i++ {
+		unit *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/unit, exponent, amount%unit, m.Currency)
+}
+
+//Normalize upper cases the currency code, using the default currency when it's empty
+func (m *Money) Normalize() {
+	m.Currency = strings.ToUpper(strings.TrimSpace(m.Currency))
+	if m.Currency == EmptyString {
+		m.Currency = DefaultCurrency
+	}
+}
+
+//ValidatePrice to validate a max retail price and its optional discount price
+func ValidatePrice(mrp Money, discountPrice *Money) error {
+	if !IsValidCurrency(mrp.Currency) {
+		return errors.New(InvalidCurrencyError)
+	}
+	if mrp.Amount <= 0 {
+		return errors.New(InvalidPriceError)
+	}
+	if discountPrice == nil {
+		return nil
+	}
+	if discountPrice.Currency != mrp.Currency {
+		return errors.New(CurrencyMismatchError)
+	}
+	if discountPrice.Amount < 0 {
+		return errors.New(InvalidPriceError)
+	}
+	if discountPrice.Amount > mrp.Amount {
+		return errors.New(DiscountExceedsMRPError)
+	}
+	return nil
+}
+
+//ValidateDiscountWindow to validate the optional validity window of a discount price
+func ValidateDiscountWindow(validFrom *time.Time, validUntil *time.Time) error {
+	if validFrom != nil && validUntil != nil && !validFrom.Before(*validUntil) {
+		return errors.New(InvalidDiscountWindowError)
+	}
+	return nil
+}
+
+//IsWithinWindow to check if the time is within the validity window, a nil bound is open
+func IsWithinWindow(at time.Time, validFrom *time.Time, validUntil *time.Time) bool {
+	if validFrom != nil && at.Before(*validFrom) {
+		return false
+	}
+	if validUntil != nil && !at.Before(*validUntil) {
+		return false
+	}
+	return true
+}
+
+//ParseAsOf to parse the optional RFC3339 as_of query parameter, an empty value is the zero time
+func ParseAsOf(value string) (time.Time, error) {
+	if value == EmptyString {
+		return time.Time{}, nil
+	}
+	asOf, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.New(InvalidAsOfError)
+	}
+	return asOf, nil
+}
@@ -0,0 +1,28 @@
+package variant
+
+const (
+	//ChangedByHeader request header identifying the user making a price change
+	ChangedByHeader = "X-Changed-By"
+	//PriceSourceAPI price history source of the price changes made through the variant API
+	PriceSourceAPI = "api"
+	//ListHistoryLimit default limit for price history listing
+	ListHistoryLimit = 50
+	//MaxHistoryLimit maximum limit for price history listing
+	MaxHistoryLimit = 500
+	//Offset default offset value for price history listing
+	Offset = 0
+	//MaxGeneratedVariants maximum number of option combinations of a variant generation request
+	MaxGeneratedVariants = 500
+	//MaxSKULength maximum length of a variant SKU
+	MaxSKULength = 64
+	//ProductIDPlaceholder pattern placeholder replaced by the product ID
+	ProductIDPlaceholder = "product_id"
+	//uniqueViolation postgres error code of a unique constraint violation
+	uniqueViolation = "23505"
+	//skuIndex unique index of the SKUs of the variants
+	skuIndex = "idx_variant_sku"
+	//barcodeIndex unique index of the barcodes of the variants
+	barcodeIndex = "idx_variant_barcode"
+	//optionSignatureIndex unique index of the option signatures of the variants of a product
+	optionSignatureIndex = "idx_variant_option_signature"
+)
@@ -2,6 +2,7 @@ package variant
 
 import (
 	"database/sql"
+	"ecommerce/search"
 	"ecommerce/utils"
 	"encoding/json"
 	"errors"
@@ -17,10 +18,14 @@ import (
 //HandlerInterface for variant management
 type HandlerInterface interface {
 	CreateVariant(http.ResponseWriter, *http.Request)
+	GenerateVariants(http.ResponseWriter, *http.Request)
 	UpdateVariant(http.ResponseWriter, *http.Request)
 	DeleteVariant(http.ResponseWriter, *http.Request)
 	GetVariant(http.ResponseWriter, *http.Request)
 	ListVariant(http.ResponseWriter, *http.Request)
+	ListPriceHistory(http.ResponseWriter, *http.Request)
+	GetVariantBySKU(http.ResponseWriter, *http.Request)
+	GetVariantByBarcode(http.ResponseWriter, *http.Request)
 }
 
 //Handler struct for variant management
@@ -29,9 +34,9 @@ type Handler struct {
 }
 
 //NewHTTPHandler to handle variant requests
-func NewHTTPHandler(db *sql.DB) HandlerInterface {
+func NewHTTPHandler(db *sql.DB, index search.SearchIndex) HandlerInterface {
 	return &Handler{
-		cs: NewService(db),
+		cs: NewService(db, index),
 	}
 }
 
@@ -53,6 +58,7 @@ func (h *Handler) CreateVariant(w http.ResponseWriter, r *http.Request) {
 		utils.Fail(w, 400, errors.New("Error validating request").Error())
 		return
 	}
+	request.ChangedBy = r.Header.Get(ChangedByHeader)
 	variant, err := h.cs.CreateVariant(&request)
 	if err != nil {
 		if err.Error() == utils.ProductIDNotExist {
@@ -60,6 +66,16 @@ func (h *Handler) CreateVariant(w http.ResponseWriter, r *http.Request) {
 			utils.Fail(w, 400, err.Error())
 			return
 		}
+		if isExistsError(err) {
+			log.Println("Error : Variant exists error(CreateVariant) -", err.Error())
+			utils.Fail(w, 200, err.Error())
+			return
+		}
+		if isRequestError(err) {
+			log.Println("Error : Validation error(CreateVariant) -", err.Error())
+			utils.Fail(w, 400, err.Error())
+			return
+		}
 		log.Println("Error : Variant creation error(CreateVariant) -", err.Error())
 		utils.Fail(w, 500, err.Error())
 		return
@@ -68,6 +84,49 @@ func (h *Handler) CreateVariant(w http.ResponseWriter, r *http.Request) {
 	utils.Send(w, 200, variant)
 }
 
+//GenerateVariants to handle the variant generation request of a product
+func (h *Handler) GenerateVariants(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /product/{product_id}/variants/generate POST API")
+	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
+	if err != nil {
+		log.Println("Error : (GenerateVariants)", err.Error())
+		utils.Fail(w, 400, utils.InvalidProductID)
+		return
+	}
+	var request GenerateRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		log.Println("Error : Decode error(GenerateVariants) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		log.Println("Error : Validation error(GenerateVariants) -", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	request.ProductID = productID
+	request.ChangedBy = r.Header.Get(ChangedByHeader)
+	response, err := h.cs.GenerateVariants(&request)
+	if err != nil {
+		log.Println("Error : (GenerateVariants) -", err.Error())
+		if err.Error() == utils.ProductIDNotExist || isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		if isExistsError(err) {
+			utils.Fail(w, 200, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Variants generated successfully, created =", len(response.Created), "skipped =", len(response.Skipped))
+	utils.Send(w, 200, response)
+}
+
 //UpdateVariant to handle the variant post request
 func (h *Handler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
 	log.Println("App : /variant PATCH API")
@@ -86,6 +145,7 @@ func (h *Handler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
 		utils.Fail(w, 400, err.Error())
 		return
 	}
+	request.ChangedBy = r.Header.Get(ChangedByHeader)
 	err = h.cs.UpdateVariant(&request)
 	if err != nil {
 		log.Println("Error : (UpdateVariant) -", err.Error())
@@ -97,6 +157,14 @@ func (h *Handler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
 			utils.Fail(w, 400, err.Error())
 			return
 		}
+		if isExistsError(err) {
+			utils.Fail(w, 200, err.Error())
+			return
+		}
+		if isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
 		utils.Fail(w, 500, err.Error())
 		return
 	}
@@ -166,8 +234,15 @@ func (h *Handler) ListVariant(w http.ResponseWriter, r *http.Request) {
 		utils.Fail(w, 400, utils.InvalidProductID)
 		return
 	}
+	asOf, err := utils.ParseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		log.Println("Error : request validation error (ListVariant)", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
 	request := &GetRequest{
 		ProductID: productID,
+		AsOf:      asOf,
 	}
 	variants, err := h.cs.ListVariant(request)
 	if err != nil {
@@ -184,6 +259,50 @@ func (h *Handler) ListVariant(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+//ListPriceHistory to handle the variant price history request
+func (h *Handler) ListPriceHistory(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/{variant_id}/price-history GET API")
+	request, err := parseHistoryRequest(r)
+	if err != nil {
+		log.Println("Error : request validation error (ListPriceHistory)", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	history, err := h.cs.ListPriceHistory(request)
+	if err != nil {
+		log.Println("Error : error fetching price history(ListPriceHistory)", err.Error())
+		if err.Error() == utils.VariantIDNotExist {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : Variant price history fetched successfully, variant id =", request.VariantID)
+	utils.Send(w, 200, history)
+}
+
+//isRequestError to check if the error is a price or option validation error of the request
+func isRequestError(err error) bool {
+	switch err.Error() {
+	case utils.InvalidCurrencyError, utils.InvalidPriceError, utils.CurrencyMismatchError, utils.DiscountExceedsMRPError,
+		utils.InvalidDiscountWindowError, utils.OptionNotExist, utils.OptionValueNotExist, utils.DuplicateOptionError,
+		utils.OptionNameError, utils.OptionValueError, utils.TooManyVariantsError, utils.InvalidPatternError,
+		utils.SKUPatternError, utils.InvalidBarcodeError:
+		return true
+	}
+	return false
+}
+
+//isExistsError to check if the error is caused by another variant with the same identifiers
+func isExistsError(err error) bool {
+	switch err.Error() {
+	case utils.VariantOptionsExistError, utils.SKUExistsError, utils.BarcodeExistsError:
+		return true
+	}
+	return false
+}
+
 func validateRequest(r *http.Request) (*GetRequest, error) {
 	productID, err := strconv.Atoi(chi.URLParam(r, "product_id"))
 	if err != nil {
@@ -193,9 +312,79 @@ func validateRequest(r *http.Request) (*GetRequest, error) {
 	if err != nil {
 		return nil, errors.New(utils.InvalidVariantID)
 	}
+	asOf, err := utils.ParseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		return nil, err
+	}
 	request := GetRequest{
 		ProductID: productID,
 		VariantID: variantID,
+		AsOf:      asOf,
 	}
 	return &request, nil
 }
+
+func parseHistoryRequest(r *http.Request) (*HistoryRequest, error) {
+	variantID, err := strconv.Atoi(chi.URLParam(r, "variant_id"))
+	if err != nil {
+		return nil, errors.New(utils.InvalidVariantID)
+	}
+	request := HistoryRequest{
+		VariantID: variantID,
+		Limit:     ListHistoryLimit,
+		Offset:    Offset,
+	}
+	query := r.URL.Query()
+	if value := query.Get("limit"); value != utils.EmptyString {
+		request.Limit, err = strconv.Atoi(value)
+		if err != nil || request.Limit <= 0 || request.Limit > MaxHistoryLimit {
+			return nil, errors.New(utils.InvalidParameterError + " limit")
+		}
+	}
+	if value := query.Get("offset"); value != utils.EmptyString {
+		request.Offset, err = strconv.Atoi(value)
+		if err != nil || request.Offset < 0 {
+			return nil, errors.New(utils.InvalidParameterError + " offset")
+		}
+	}
+	return &request, nil
+}
+
+//GetVariantBySKU to handle the variant get request by SKU
+func (h *Handler) GetVariantBySKU(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/by-sku/{sku} GET API")
+	h.lookupVariant(w, r, &LookupRequest{
+		SKU: chi.URLParam(r, "sku"),
+	})
+}
+
+//GetVariantByBarcode to handle the variant get request by barcode
+func (h *Handler) GetVariantByBarcode(w http.ResponseWriter, r *http.Request) {
+	log.Println("App : /variant/by-barcode/{code} GET API")
+	h.lookupVariant(w, r, &LookupRequest{
+		Barcode: chi.URLParam(r, "code"),
+	})
+}
+
+//lookupVariant to respond with the variant with the SKU or barcode of the request
+func (h *Handler) lookupVariant(w http.ResponseWriter, r *http.Request, request *LookupRequest) {
+	var err error
+	request.AsOf, err = utils.ParseAsOf(r.URL.Query().Get("as_of"))
+	if err != nil {
+		log.Println("Error : request validation error (lookupVariant)", err.Error())
+		utils.Fail(w, 400, err.Error())
+		return
+	}
+	variant, err := h.cs.LookupVariant(request)
+	if err != nil {
+		log.Println("Error : error while fetching variant details(lookupVariant)", err.Error())
+		if err.Error() == utils.SKUNotExist || err.Error() == utils.BarcodeNotExist || isRequestError(err) {
+			utils.Fail(w, 400, err.Error())
+			return
+		}
+		utils.Fail(w, 500, err.Error())
+		return
+	}
+	log.Println("App : variant details fetched successfully, variant id =", variant.ID)
+	utils.Send(w, 200, variant)
+}
@@ -1,49 +1,166 @@
 package variant
 
+import (
+	"ecommerce/utils"
+	"time"
+)
+
 //CreateRequest struct to manage variant create request
 type CreateRequest struct {
-	Name          string  `json:"name"`
-	MRP           float64 `json:"max_retail_price" validate:"required"`
-	DiscountPrice float64 `json:"discount_price"`
-	Size          string  `json:"size"`
-	Color         string  `json:"color"`
-	ProductID     int     `json:"product_id" validate:"required,gt=0"`
+	Name               string        `json:"name"`
+	MRP                utils.Money   `json:"max_retail_price"`
+	DiscountPrice      *utils.Money  `json:"discount_price"`
+	DiscountValidFrom  *time.Time    `json:"discount_valid_from"`
+	DiscountValidUntil *time.Time    `json:"discount_valid_until"`
+	Size               string        `json:"size" validate:"max=50"`
+	Color              string        `json:"color" validate:"max=50"`
+	Options            []OptionValue `json:"options" validate:"dive"`
+	SKU                string        `json:"sku" validate:"max=64"`
+	Barcode            string        `json:"barcode"`
+	ProductID          int           `json:"product_id" validate:"required,gt=0"`
+	ChangedBy          string        `json:"-"`
 }
 
 // CreateResponse variant details create response
 type CreateResponse struct {
-	ID            int     `json:"id"`
-	Name          string  `json:"name,omitempty"`
-	MRP           float64 `json:"max_retail_price"`
-	DiscountPrice float64 `json:"discount_price,omitempty"`
-	Size          string  `json:"size,omitempty"`
-	Color         string  `json:"color,omitempty"`
-	ProductID     int     `json:"product_id"`
+	ID                 int           `json:"id"`
+	Name               string        `json:"name,omitempty"`
+	MRP                utils.Money   `json:"max_retail_price"`
+	DiscountPrice      *utils.Money  `json:"discount_price,omitempty"`
+	DiscountValidFrom  *time.Time    `json:"discount_valid_from,omitempty"`
+	DiscountValidUntil *time.Time    `json:"discount_valid_until,omitempty"`
+	Size               string        `json:"size,omitempty"`
+	Color              string        `json:"color,omitempty"`
+	Options            []OptionValue `json:"options,omitempty"`
+	SKU                string        `json:"sku,omitempty"`
+	Barcode            string        `json:"barcode,omitempty"`
+	ProductID          int           `json:"product_id"`
 }
 
-//UpdateRequest struct to represent the variant update request
+//UpdateRequest struct to represent the variant update request, a discount price with
+//a zero amount removes the discount and its validity window, an option with an empty
+//value is removed from the variant, an empty SKU or barcode removes it from the variant
 type UpdateRequest struct {
-	VariantID     int     `json:"variant_id" validate:"required"`
-	Name          string  `json:"name"`
-	MRP           float64 `json:"max_retail_price"`
-	DiscountPrice float64 `json:"discount_price"`
-	Size          string  `json:"size"`
-	Color         string  `json:"color"`
+	VariantID          int           `json:"variant_id" validate:"required"`
+	Name               string        `json:"name"`
+	MRP                *utils.Money  `json:"max_retail_price"`
+	DiscountPrice      *utils.Money  `json:"discount_price"`
+	DiscountValidFrom  *time.Time    `json:"discount_valid_from"`
+	DiscountValidUntil *time.Time    `json:"discount_valid_until"`
+	Size               string        `json:"size" validate:"max=50"`
+	Color              string        `json:"color" validate:"max=50"`
+	Options            []OptionValue `json:"options" validate:"dive"`
+	SKU                *string       `json:"sku" validate:"omitempty,max=64"`
+	Barcode            *string       `json:"barcode"`
+	ChangedBy          string        `json:"-"`
+}
+
+//OptionValue to represent the value of a product option for a variant, the size and color
+//of the requests are registered as the Size and Color options of the product when missing
+type OptionValue struct {
+	Name     string `json:"name" validate:"required,max=50"`
+	Value    string `json:"value" validate:"max=50"`
+	Register bool   `json:"-"` //true to define the option and value on the product when missing
 }
 
-// GetRequest to represent get variant request
+// GetRequest to represent get variant request, the prices are resolved as of the given time
 type GetRequest struct {
 	ProductID int
 	VariantID int
+	AsOf      time.Time
 }
 
 // Variant to represent variant struct
 type Variant struct {
-	ID              int     `json:"variant_id"`
-	Name            string  `json:"name,omitempty"`
-	MRP             float64 `json:"max_retail_price"`
-	DiscountPrice float64 `json:"discount_price,omitempty"`
-	Size            string  `json:"size,omitempty"`
-	Color          string  `json:"color,omitempty"`
-	ProductID       int     `json:"product_id"`
+	ID                 int           `json:"variant_id"`
+	Name               string        `json:"name,omitempty"`
+	MRP                utils.Money   `json:"max_retail_price"`
+	DiscountPrice      *utils.Money  `json:"discount_price,omitempty"`
+	DiscountValidFrom  *time.Time    `json:"discount_valid_from,omitempty"`
+	DiscountValidUntil *time.Time    `json:"discount_valid_until,omitempty"`
+	EffectivePrice     utils.Money   `json:"effective_price"`
+	LowestPrice30d     *utils.Money  `json:"lowest_price_30d,omitempty"`
+	Size               string        `json:"size,omitempty"`
+	Color              string        `json:"color,omitempty"`
+	Options            []OptionValue `json:"options,omitempty"`
+	SKU                string        `json:"sku,omitempty"`
+	Barcode            string        `json:"barcode,omitempty"`
+	ProductID          int           `json:"product_id"`
+	AvailableQuantity  int           `json:"available_quantity"`
+	InStock            bool          `json:"in_stock"`
+}
+
+//LookupRequest to represent the request to get a variant by its SKU or barcode, the prices are resolved
+//as of the given time
+type LookupRequest struct {
+	SKU     string
+	Barcode string
+	AsOf    time.Time
+}
+
+//Price to represent the current price of a variant
+type Price struct {
+	MRP                utils.Money
+	DiscountPrice      *utils.Money
+	DiscountValidFrom  *time.Time
+	DiscountValidUntil *time.Time
+}
+
+//HistoryRequest to represent the price history listing request of a variant
+type HistoryRequest struct {
+	VariantID int
+	Limit     int
+	Offset    int
+}
+
+//PriceHistory to represent a recorded price change of a variant
+type PriceHistory struct {
+	ID                    int          `json:"history_id"`
+	VariantID             int          `json:"variant_id"`
+	OldMRP                *utils.Money `json:"old_max_retail_price,omitempty"`
+	OldDiscountPrice      *utils.Money `json:"old_discount_price,omitempty"`
+	OldDiscountValidFrom  *time.Time   `json:"old_discount_valid_from,omitempty"`
+	OldDiscountValidUntil *time.Time   `json:"old_discount_valid_until,omitempty"`
+	NewMRP                utils.Money  `json:"new_max_retail_price"`
+	NewDiscountPrice      *utils.Money `json:"new_discount_price,omitempty"`
+	NewDiscountValidFrom  *time.Time   `json:"new_discount_valid_from,omitempty"`
+	NewDiscountValidUntil *time.Time   `json:"new_discount_valid_until,omitempty"`
+	Source                string       `json:"source"`
+	ChangedBy             string       `json:"changed_by,omitempty"`
+	ChangedAt             time.Time    `json:"changed_at"`
+}
+
+//GenerateRequest to represent the request to generate the variants of a product for every combination
+//of the option values. The patterns can refer to the option values as {option name} and to the product
+//as {product_id}, the name defaults to the option values separated by " / "
+type GenerateRequest struct {
+	ProductID          int              `json:"-"`
+	Options            []GenerateOption `json:"options" validate:"required,min=1,dive"`
+	MRP                utils.Money      `json:"max_retail_price"`
+	DiscountPrice      *utils.Money     `json:"discount_price"`
+	DiscountValidFrom  *time.Time       `json:"discount_valid_from"`
+	DiscountValidUntil *time.Time       `json:"discount_valid_until"`
+	NamePattern        string           `json:"name_pattern"`
+	SKUPattern         string           `json:"sku_pattern"`
+	ChangedBy          string           `json:"-"`
+}
+
+//GenerateOption to represent an option and the values to generate variants for
+type GenerateOption struct {
+	Name   string   `json:"name" validate:"required,max=50"`
+	Values []string `json:"values" validate:"required,min=1,dive,required,max=50"`
+}
+
+//Combination to represent the option values of a generated variant
+type Combination struct {
+	Name    string        `json:"name,omitempty"`
+	SKU     string        `json:"sku,omitempty"`
+	Options []OptionValue `json:"options"`
+}
+
+//GenerateResponse to represent the variants created by a generation request and the combinations
+//skipped as a variant of the product already has their option values
+type GenerateResponse struct {
+	Created []CreateResponse `json:"created"`
+	Skipped []Combination    `json:"skipped"`
 }
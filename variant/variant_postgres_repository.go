@@ -5,7 +5,11 @@ import (
 	"ecommerce/utils"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 //Repo is the DB repository struct
@@ -36,47 +40,278 @@ func (repo *Repo) CheckProductExists(productID int) (bool, error) {
 	return false, nil
 }
 
-func getNullFloat64(value float64) sql.NullFloat64 {
-	if value == 0 {
-		return sql.NullFloat64{}
+func getNullAmount(value *utils.Money) sql.NullInt64 {
+	if value == nil || value.Amount == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{
+		Int64: value.Amount,
+		Valid: true,
+	}
+}
+
+func getMoney(amount sql.NullInt64, currency string) *utils.Money {
+	if !amount.Valid {
+		return nil
 	}
-	return sql.NullFloat64{
-		Float64: value,
-		Valid:   true,
+	return &utils.Money{
+		Amount:   amount.Int64,
+		Currency: currency,
 	}
 }
 
+func getNullString(value string) sql.NullString {
+	return sql.NullString{
+		String: value,
+		Valid:  value != utils.EmptyString,
+	}
+}
+
+func getNullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{
+		Time:  *value,
+		Valid: true,
+	}
+}
+
+func getTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+//setPriceAudit to set the source and the user of the price changes made in the transaction,
+//which are recorded in the price history by the tbl_variant trigger
+func setPriceAudit(tx *sql.Tx, source string, changedBy string) error {
+	query := `
+		SELECT
+			set_config('ecommerce.price_source', $1, true),
+			set_config('ecommerce.changed_by', $2, true)
+	`
+	_, err := tx.Exec(query, source, changedBy)
+	return err
+}
+
 //CreateVariant to create a variant in DB
 func (repo *Repo) CreateVariant(request *CreateRequest) (*CreateResponse, error) {
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	err = setPriceAudit(tx, PriceSourceAPI, request.ChangedBy)
+	if err != nil {
+		return nil, err
+	}
+	createResponse, err := insertVariant(tx, request)
+	if err != nil {
+		return nil, err
+	}
+	err = setCreatedOptions(tx, []CreateResponse{*createResponse})
+	if err != nil {
+		return nil, err
+	}
+	return createResponse, tx.Commit()
+}
+
+//insertVariant to insert a variant with its option values in the transaction
+func insertVariant(tx *sql.Tx, request *CreateRequest) (*CreateResponse, error) {
 	var createResponse CreateResponse
-	var name, size, color sql.NullString
-	var discountPrice sql.NullFloat64
+	var name, sku, barcode sql.NullString
+	var discountPrice sql.NullInt64
+	var validFrom, validUntil sql.NullTime
 	query := `
 		INSERT INTO 
-			tbl_variant (name, max_retail_price, discount_price, size, color, product_id, created_at, updated_at)
+			tbl_variant (name, max_retail_price, discount_price, currency, discount_valid_from, discount_valid_until,
+				sku, barcode, product_id, created_at, updated_at)
 		VALUES
-			($1, $2, $3, $4, $5, $6, NOW(), NOW())
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
 		RETURNING
-			variant_id, name, max_retail_price, discount_price, size, color, product_id
+			variant_id, name, max_retail_price, discount_price, currency, discount_valid_from, discount_valid_until,
+			sku, barcode, product_id
 	`
-	row := repo.DB.QueryRow(query, request.Name, request.MRP, getNullFloat64(request.DiscountPrice), request.Size, request.Color, request.ProductID)
-	err := row.Scan(&createResponse.ID, &name, &createResponse.MRP, &discountPrice, &size, &color, &createResponse.ProductID)
+	row := tx.QueryRow(query, request.Name, request.MRP.Amount, getNullAmount(request.DiscountPrice),
+		request.MRP.Currency, getNullTime(request.DiscountValidFrom), getNullTime(request.DiscountValidUntil),
+		getNullString(request.SKU), getNullString(request.Barcode), request.ProductID)
+	err := row.Scan(&createResponse.ID, &name, &createResponse.MRP.Amount, &discountPrice,
+		&createResponse.MRP.Currency, &validFrom, &validUntil, &sku, &barcode, &createResponse.ProductID)
+	if err != nil {
+		return nil, uniqueError(err)
+	}
+	err = setVariantOptions(tx, request.ProductID, createResponse.ID, request.Options)
 	if err != nil {
 		return nil, err
 	}
 	if name.Valid {
 		createResponse.Name = name.String
 	}
-	if size.Valid {
-		createResponse.Size = size.String
+	createResponse.SKU = sku.String
+	createResponse.Barcode = barcode.String
+	createResponse.DiscountPrice = getMoney(discountPrice, createResponse.MRP.Currency)
+	createResponse.DiscountValidFrom = getTime(validFrom)
+	createResponse.DiscountValidUntil = getTime(validUntil)
+	return &createResponse, nil
+}
+
+//setCreatedOptions to fill the option values of the created variants
+func setCreatedOptions(tx *sql.Tx, created []CreateResponse) error {
+	if len(created) == 0 {
+		return nil
+	}
+	variantIDs := make([]int, len(created))
+	for i, v := range created {
+		variantIDs[i] = v.ID
 	}
-	if color.Valid {
-		createResponse.Color = color.String
+	optionMap, err := getVariantOptions(tx, variantIDs)
+	if err != nil {
+		return err
 	}
-	if discountPrice.Valid {
-		createResponse.DiscountPrice = discountPrice.Float64
+	for i, v := range created {
+		created[i].Options = optionMap[v.ID]
+		created[i].Size, created[i].Color = legacyOptions(created[i].Options)
 	}
-	return &createResponse, nil
+	return nil
+}
+
+//GenerateVariants to create the given variants of a product in one transaction, a variant is skipped
+//when another variant of the product already has exactly its option values
+func (repo *Repo) GenerateVariants(requests []CreateRequest) (*GenerateResponse, error) {
+	response := GenerateResponse{
+		Created: []CreateResponse{},
+		Skipped: []Combination{},
+	}
+	if len(requests) == 0 {
+		return &response, nil
+	}
+	productID := requests[0].ProductID
+	tx, err := repo.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	err = setPriceAudit(tx, PriceSourceAPI, requests[0].ChangedBy)
+	if err != nil {
+		return nil, err
+	}
+	signatures, err := getOptionSignatures(tx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range requests {
+		request := &requests[i]
+		signature, err := getOptionSignature(tx, productID, request.Options)
+		if err != nil {
+			return nil, err
+		}
+		if signatures[signature] {
+			response.Skipped = append(response.Skipped, Combination{
+				Name:    request.Name,
+				SKU:     request.SKU,
+				Options: request.Options,
+			})
+			continue
+		}
+		created, err := insertVariant(tx, request)
+		if err != nil {
+			return nil, err
+		}
+		signatures[signature] = true
+		response.Created = append(response.Created, *created)
+	}
+	err = setCreatedOptions(tx, response.Created)
+	if err != nil {
+		return nil, err
+	}
+	return &response, tx.Commit()
+}
+
+//getOptionSignatures to get the option signatures of the variants of the product that are not deleted
+func getOptionSignatures(tx *sql.Tx, productID int) (map[string]bool, error) {
+	query := `
+		SELECT
+			option_signature
+		FROM
+			tbl_variant
+		WHERE
+			product_id = $1
+		AND
+			option_signature IS NOT NULL
+		AND
+			deleted_at IS NULL
+	`
+	rows, err := tx.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	signatures := make(map[string]bool)
+	for rows.Next() {
+		var signature string
+		err := rows.Scan(&signature)
+		if err != nil {
+			return nil, err
+		}
+		signatures[signature] = true
+	}
+	return signatures, rows.Err()
+}
+
+//getOptionSignature to build the option signature of the option values the way setVariantOptions stores
+//it, the options and values are defined on the product when missing and register is set
+func getOptionSignature(tx *sql.Tx, productID int, options []OptionValue) (string, error) {
+	var pairs [][2]int
+	for _, v := range options {
+		optionID, err := getOptionID(tx, productID, v.Name, v.Register)
+		if err != nil {
+			return utils.EmptyString, err
+		}
+		valueID, err := getOptionValueID(tx, optionID, v.Value, v.Register)
+		if err != nil {
+			return utils.EmptyString, err
+		}
+		pairs = append(pairs, [2]int{optionID, valueID})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0]
+	})
+	parts := make([]string, len(pairs))
+	for i, pair := range pairs {
+		parts[i] = fmt.Sprintf("%d:%d", pair[0], pair[1])
+	}
+	return strings.Join(parts, ","), nil
+}
+
+//GetVariantPrice to get the current price of a variant
+func (repo *Repo) GetVariantPrice(variantID int) (*Price, error) {
+	var price Price
+	var discountPrice sql.NullInt64
+	var validFrom, validUntil sql.NullTime
+	query := `
+		SELECT
+			max_retail_price, discount_price, currency, discount_valid_from, discount_valid_until
+		FROM
+			tbl_variant
+		WHERE
+			variant_id = $1
+		AND
+			deleted_at IS NULL
+	`
+	err := repo.DB.QueryRow(query, variantID).Scan(&price.MRP.Amount, &discountPrice, &price.MRP.Currency,
+		&validFrom, &validUntil)
+	if err == sql.ErrNoRows {
+		return nil, errors.New(utils.InvalidVariantID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	price.DiscountPrice = getMoney(discountPrice, price.MRP.Currency)
+	price.DiscountValidFrom = getTime(validFrom)
+	price.DiscountValidUntil = getTime(validUntil)
+	return &price, nil
 }
 
 //IsVariantIDExists function to check if the variant ID already exists
@@ -102,23 +337,58 @@ func (repo *Repo) IsVariantIDExists(id int) (bool, error) {
 	return false, nil
 }
 
+//GetVariantProductID to get the product of the given variant
+func (repo *Repo) GetVariantProductID(variantID int) (int, error) {
+	var productID int
+	query := `
+		SELECT
+			product_id
+		FROM
+			tbl_variant
+		WHERE
+			variant_id = $1
+	`
+	err := repo.DB.QueryRow(query, variantID).Scan(&productID)
+	if err != nil {
+		return 0, err
+	}
+	return productID, nil
+}
+
 //UpdateVariant to update a variant
 func (repo *Repo) UpdateVariant(request *UpdateRequest) error {
 	var slice []string
+	args := []interface{}{request.VariantID}
+	addField := func(column string, value interface{}) {
+		args = append(args, value)
+		slice = append(slice, fmt.Sprintf(" %s = $%d ", column, len(args)))
+	}
 	if len(request.Name) > 0 && request.Name != utils.EmptyString {
-		slice = append(slice, fmt.Sprintf(" name = '%s' ", request.Name))
+		addField("name", request.Name)
+	}
+	if request.DiscountPrice != nil {
+		addField("discount_price", getNullAmount(request.DiscountPrice))
 	}
-	if len(request.Size) > 0 && request.Size != utils.EmptyString {
-		slice = append(slice, fmt.Sprintf(" size = '%s' ", request.Size))
+	if request.DiscountPrice != nil && request.DiscountPrice.Amount == 0 {
+		addField("discount_valid_from", sql.NullTime{})
+		addField("discount_valid_until", sql.NullTime{})
+	} else {
+		if request.DiscountValidFrom != nil {
+			addField("discount_valid_from", *request.DiscountValidFrom)
+		}
+		if request.DiscountValidUntil != nil {
+			addField("discount_valid_until", *request.DiscountValidUntil)
+		}
 	}
-	if len(request.Color) > 0 && request.Color != utils.EmptyString {
-		slice = append(slice, fmt.Sprintf(" color = '%s' ", request.Color))
+	if request.MRP != nil {
+		addField("max_retail_price", request.MRP.Amount)
+		addField("currency", request.MRP.Currency)
 	}
-	if request.DiscountPrice != 0 {
-		slice = append(slice, fmt.Sprintf(" discount_price = %f ", request.DiscountPrice))
+	if request.SKU != nil {
+		addField("sku", getNullString(*request.SKU))
 	}
-	if request.MRP != 0 {
-		slice = append(slice, fmt.Sprintf(" max_retail_price = %f ", request.MRP))
+	if request.Barcode != nil {
+		addField("barcode", getNullString(*request.Barcode))
 	}
 	slice = append(slice, fmt.Sprintf(" updated_at = NOW() "))
 	updateQuery := strings.Join(slice, ", ")
@@ -133,15 +403,38 @@ func (repo *Repo) UpdateVariant(request *UpdateRequest) error {
 			deleted_at IS NULL
 	`
 	query := fmt.Sprintf(mainQuery, updateQuery)
-	result, err := repo.DB.Exec(query, request.VariantID)
+	tx, err := repo.DB.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+	err = setPriceAudit(tx, PriceSourceAPI, request.ChangedBy)
+	if err != nil {
+		return err
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return uniqueError(err)
+	}
 	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
 	if rowsAffected == 0 {
 		return errors.New(utils.InvalidVariantID)
 	}
-	return err
+	if len(request.Options) > 0 {
+		var productID int
+		err = tx.QueryRow("SELECT product_id FROM tbl_variant WHERE variant_id = $1", request.VariantID).Scan(&productID)
+		if err != nil {
+			return err
+		}
+		err = setVariantOptions(tx, productID, request.VariantID, request.Options)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 // DeleteVariant to delete the variant from DB
@@ -173,52 +466,368 @@ func (repo *Repo) DeleteVariant(variantID int) error {
 // ListVariant is the DB function to list variants
 func (repo *Repo) ListVariant(request *GetRequest) ([]Variant, error) {
 	var variants []Variant
-	var name, size, color sql.NullString
-	var discountPrice sql.NullFloat64
+	var name, sku, barcode sql.NullString
+	var discountPrice sql.NullInt64
+	var validFrom, validUntil sql.NullTime
+	var lowestPrice sql.NullInt64
 	var subQuery string
 	if request.VariantID != 0 {
-		subQuery = fmt.Sprintf(" AND variant_id = %d ", request.VariantID)
+		subQuery = fmt.Sprintf(" AND v.variant_id = %d ", request.VariantID)
 	}
 	query := `
 		SELECT
-			variant_id, name, max_retail_price, discount_price, size, color
+			v.variant_id, v.name, v.max_retail_price, v.discount_price, v.currency, v.discount_valid_from,
+			v.discount_valid_until, v.sku, v.barcode, COALESCE(s.available_quantity, 0), l.lowest_price
 		FROM
-			tbl_variant
+			tbl_variant v
+		LEFT JOIN
+			vw_variant_stock s
+		ON
+			s.variant_id = v.variant_id
+		LEFT JOIN
+			vw_variant_lowest_price_30d l
+		ON
+			l.variant_id = v.variant_id
+		AND
+			l.currency = v.currency
 		WHERE
-			product_id = $1
+			v.product_id = $1
 			%s
 		AND 
-			deleted_at IS NULL
+			v.deleted_at IS NULL
+		ORDER BY
+			v.variant_id ASC
 	`
 	mainQuery := fmt.Sprintf(query, subQuery)
 	rows, err := repo.DB.Query(mainQuery, request.ProductID)
 	if err != nil {
 		return nil, err
 	}
-	var variant Variant
 	defer rows.Close()
 	for rows.Next() {
-		err := rows.Scan(&variant.ID, &name, &variant.MRP, &discountPrice, &size, &color)
+		var variant Variant
+		err := rows.Scan(&variant.ID, &name, &variant.MRP.Amount, &discountPrice, &variant.MRP.Currency,
+			&validFrom, &validUntil, &sku, &barcode, &variant.AvailableQuantity, &lowestPrice)
 		if err != nil {
 			return nil, err
 		}
 		if name.Valid {
 			variant.Name = name.String
 		}
-		if size.Valid {
-			variant.Size = size.String
-		}
-		if color.Valid {
-			variant.Color = color.String
-		}
-		if discountPrice.Valid {
-			variant.DiscountPrice = discountPrice.Float64
-		}
+		variant.SKU = sku.String
+		variant.Barcode = barcode.String
+		variant.DiscountPrice = getMoney(discountPrice, variant.MRP.Currency)
+		variant.DiscountValidFrom = getTime(validFrom)
+		variant.DiscountValidUntil = getTime(validUntil)
+		variant.LowestPrice30d = getMoney(lowestPrice, variant.MRP.Currency)
+		variant.InStock = variant.AvailableQuantity > 0
 		variant.ProductID = request.ProductID
 		variants = append(variants, variant)
 	}
 	if len(variants) <= 0 {
 		return nil, errors.New(utils.NoDataFoundError)
 	}
+	variantIDs := make([]int, len(variants))
+	for i, v := range variants {
+		variantIDs[i] = v.ID
+	}
+	optionMap, err := getVariantOptions(repo.DB, variantIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range variants {
+		variants[i].Options = optionMap[v.ID]
+		variants[i].Size, variants[i].Color = legacyOptions(variants[i].Options)
+	}
 	return variants, nil
 }
+
+//ListPriceHistory to list the recorded price changes of a variant, latest first
+func (repo *Repo) ListPriceHistory(request *HistoryRequest) ([]PriceHistory, error) {
+	var oldCurrency, changedBy sql.NullString
+	var oldMRP, oldDiscountPrice, newDiscountPrice sql.NullInt64
+	var oldValidFrom, oldValidUntil, newValidFrom, newValidUntil sql.NullTime
+	query := `
+		SELECT
+			history_id, variant_id, old_currency, old_max_retail_price, old_discount_price,
+			old_discount_valid_from, old_discount_valid_until, new_currency, new_max_retail_price,
+			new_discount_price, new_discount_valid_from, new_discount_valid_until, source, changed_by, changed_at
+		FROM
+			tbl_price_history
+		WHERE
+			variant_id = $1
+		ORDER BY
+			changed_at DESC,
+			history_id DESC
+		LIMIT $2
+		OFFSET $3
+	`
+	rows, err := repo.DB.Query(query, request.VariantID, request.Limit, request.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	history := []PriceHistory{}
+	for rows.Next() {
+		var change PriceHistory
+		err := rows.Scan(&change.ID, &change.VariantID, &oldCurrency, &oldMRP, &oldDiscountPrice, &oldValidFrom,
+			&oldValidUntil, &change.NewMRP.Currency, &change.NewMRP.Amount, &newDiscountPrice, &newValidFrom,
+			&newValidUntil, &change.Source, &changedBy, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		change.OldMRP = getMoney(oldMRP, oldCurrency.String)
+		change.OldDiscountPrice = getMoney(oldDiscountPrice, oldCurrency.String)
+		change.OldDiscountValidFrom = getTime(oldValidFrom)
+		change.OldDiscountValidUntil = getTime(oldValidUntil)
+		change.NewDiscountPrice = getMoney(newDiscountPrice, change.NewMRP.Currency)
+		change.NewDiscountValidFrom = getTime(newValidFrom)
+		change.NewDiscountValidUntil = getTime(newValidUntil)
+		if changedBy.Valid {
+			change.ChangedBy = changedBy.String
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+//querier is implemented by both sql.DB and sql.Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+//getVariantOptions to get the option values of the given variants in the order of the product options
+func getVariantOptions(db querier, variantIDs []int) (map[int][]OptionValue, error) {
+	query := `
+		SELECT
+			variant_id, option_name, value
+		FROM
+			vw_variant_option
+		WHERE
+			variant_id = ANY($1)
+		ORDER BY
+			variant_id ASC,
+			position ASC,
+			option_id ASC
+	`
+	rows, err := db.Query(query, pq.Array(variantIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	optionMap := make(map[int][]OptionValue)
+	for rows.Next() {
+		var variantID int
+		var option OptionValue
+		err := rows.Scan(&variantID, &option.Name, &option.Value)
+		if err != nil {
+			return nil, err
+		}
+		optionMap[variantID] = append(optionMap[variantID], option)
+	}
+	return optionMap, rows.Err()
+}
+
+//legacyOptions returns the values of the Size and Color options
+func legacyOptions(options []OptionValue) (string, string) {
+	var size, color string
+	for _, v := range options {
+		switch strings.ToLower(v.Name) {
+		case strings.ToLower(utils.SizeOption):
+			size = v.Value
+		case strings.ToLower(utils.ColorOption):
+			color = v.Value
+		}
+	}
+	return size, color
+}
+
+//getOptionID to get the ID of the product option with the name, the option is defined on the
+//product when it is missing and register is true
+func getOptionID(tx *sql.Tx, productID int, name string, register bool) (int, error) {
+	var optionID int
+	query := `
+		SELECT
+			option_id
+		FROM
+			tbl_product_option
+		WHERE
+			product_id = $1
+		AND
+			lower(name) = lower($2)
+	`
+	err := tx.QueryRow(query, productID, name).Scan(&optionID)
+	if err != sql.ErrNoRows {
+		return optionID, err
+	}
+	if !register {
+		return 0, errors.New(utils.OptionNotExist)
+	}
+	insertQuery := `
+		INSERT INTO
+			tbl_product_option (product_id, name, position, created_at, updated_at)
+		SELECT
+			$1, $2, COALESCE(MAX(position), 0) + 1, NOW(), NOW()
+		FROM
+			tbl_product_option
+		WHERE
+			product_id = $1
+		ON CONFLICT (product_id, lower(name)) DO NOTHING
+	`
+	_, err = tx.Exec(insertQuery, productID, name)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.QueryRow(query, productID, name).Scan(&optionID)
+	return optionID, err
+}
+
+//getOptionValueID to get the ID of the option value, the value is defined on the option when it is
+//missing and register is true
+func getOptionValueID(tx *sql.Tx, optionID int, value string, register bool) (int, error) {
+	var valueID int
+	query := `
+		SELECT
+			value_id
+		FROM
+			tbl_product_option_value
+		WHERE
+			option_id = $1
+		AND
+			value = $2
+	`
+	err := tx.QueryRow(query, optionID, value).Scan(&valueID)
+	if err != sql.ErrNoRows {
+		return valueID, err
+	}
+	if !register {
+		return 0, errors.New(utils.OptionValueNotExist)
+	}
+	insertQuery := `
+		INSERT INTO
+			tbl_product_option_value (option_id, value, created_at)
+		VALUES
+			($1, $2, NOW())
+		ON CONFLICT (option_id, value) DO NOTHING
+	`
+	_, err = tx.Exec(insertQuery, optionID, value)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.QueryRow(query, optionID, value).Scan(&valueID)
+	return valueID, err
+}
+
+//setVariantOptions to set the option values of a variant, an empty value removes the option from the
+//variant. The option signature of the variant is refreshed so that the unique index rejects another
+//variant of the product with the same option values
+func setVariantOptions(tx *sql.Tx, productID int, variantID int, options []OptionValue) error {
+	for _, v := range options {
+		optionID, err := getOptionID(tx, productID, v.Name, v.Register && v.Value != utils.EmptyString)
+		if err != nil {
+			if v.Value == utils.EmptyString && err.Error() == utils.OptionNotExist {
+				continue
+			}
+			return err
+		}
+		if v.Value == utils.EmptyString {
+			_, err = tx.Exec("DELETE FROM tbl_variant_option_value WHERE variant_id = $1 AND option_id = $2", variantID, optionID)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		valueID, err := getOptionValueID(tx, optionID, v.Value, v.Register)
+		if err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO
+				tbl_variant_option_value (variant_id, option_id, value_id)
+			VALUES
+				($1, $2, $3)
+			ON CONFLICT (variant_id, option_id) DO UPDATE
+			SET
+				value_id = EXCLUDED.value_id
+		`
+		_, err = tx.Exec(query, variantID, optionID, valueID)
+		if err != nil {
+			return err
+		}
+	}
+	query := `
+		UPDATE
+			tbl_variant
+		SET
+			option_signature = (
+				SELECT
+					string_agg(option_id || ':' || value_id, ',' ORDER BY option_id)
+				FROM
+					tbl_variant_option_value
+				WHERE
+					variant_id = $1
+			)
+		WHERE
+			variant_id = $1
+	`
+	_, err := tx.Exec(query, variantID)
+	return uniqueError(err)
+}
+
+//uniqueError to turn the unique index violations of the variants into their errors
+func uniqueError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != uniqueViolation {
+		return err
+	}
+	switch pqErr.Constraint {
+	case skuIndex:
+		return errors.New(utils.SKUExistsError)
+	case barcodeIndex:
+		return errors.New(utils.BarcodeExistsError)
+	case optionSignatureIndex:
+		return errors.New(utils.VariantOptionsExistError)
+	}
+	return err
+}
+
+//LookupVariant to get the product and variant IDs of the variant with the SKU or barcode of the request
+func (repo *Repo) LookupVariant(request *LookupRequest) (*GetRequest, error) {
+	getRequest := GetRequest{
+		AsOf: request.AsOf,
+	}
+	query := `
+		SELECT
+			product_id, variant_id
+		FROM
+			tbl_variant
+		WHERE
+			sku = $1
+		AND
+			deleted_at IS NULL
+	`
+	value, notExist := request.SKU, utils.SKUNotExist
+	if request.Barcode != utils.EmptyString {
+		query = `
+			SELECT
+				product_id, variant_id
+			FROM
+				tbl_variant
+			WHERE
+				lpad(barcode, 14, '0') = lpad($1, 14, '0')
+			AND
+				barcode IS NOT NULL
+			AND
+				deleted_at IS NULL
+		`
+		value, notExist = request.Barcode, utils.BarcodeNotExist
+	}
+	err := repo.DB.QueryRow(query, value).Scan(&getRequest.ProductID, &getRequest.VariantID)
+	if err == sql.ErrNoRows {
+		return nil, errors.New(notExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &getRequest, nil
+}
@@ -5,16 +5,21 @@ import "database/sql"
 //RepoInterface for DB operations
 type RepoInterface interface {
 	CreateVariant(*CreateRequest) (*CreateResponse, error)
+	GenerateVariants([]CreateRequest) (*GenerateResponse, error)
 	CheckProductExists(int) (bool, error)
 	IsVariantIDExists(int) (bool, error)
+	GetVariantProductID(int) (int, error)
 	UpdateVariant(*UpdateRequest) error
 	DeleteVariant(int) error
 	ListVariant(*GetRequest) ([]Variant, error)
+	GetVariantPrice(int) (*Price, error)
+	ListPriceHistory(*HistoryRequest) ([]PriceHistory, error)
+	LookupVariant(*LookupRequest) (*GetRequest, error)
 }
 
-//NewRepo returns repository interface 
+//NewRepo returns repository interface
 func NewRepo(db *sql.DB) RepoInterface {
 	return &Repo{
 		DB: db,
 	}
-}
\ No newline at end of file
+}
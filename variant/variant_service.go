@@ -2,32 +2,59 @@ package variant
 
 import (
 	"database/sql"
+	"ecommerce/pricing"
+	"ecommerce/search"
 	"ecommerce/utils"
 	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
 )
 
 //ServiceInterface is variant service interface
 type ServiceInterface interface {
 	CreateVariant(*CreateRequest) (*CreateResponse, error)
+	GenerateVariants(*GenerateRequest) (*GenerateResponse, error)
 	UpdateVariant(*UpdateRequest) error
 	DeleteVariant(int) error
 	ListVariant(*GetRequest) ([]Variant, error)
+	ListPriceHistory(*HistoryRequest) ([]PriceHistory, error)
+	LookupVariant(*LookupRequest) (*Variant, error)
 }
 
 //Service struct for service functionalities
 type Service struct {
-	repo RepoInterface
+	repo   RepoInterface
+	prices pricing.ServiceInterface
+	index  search.SearchIndex
 }
 
 //NewService :
-func NewService(db *sql.DB) ServiceInterface {
+func NewService(db *sql.DB, index search.SearchIndex) ServiceInterface {
 	return &Service{
-		repo: NewRepo(db),
+		repo:   NewRepo(db),
+		prices: pricing.NewService(db),
+		index:  index,
 	}
 }
 
 //CreateVariant service function to create a variant
 func (service *Service) CreateVariant(request *CreateRequest) (*CreateResponse, error) {
+	err := validateCreatePrice(request)
+	if err != nil {
+		return nil, err
+	}
+	request.Options, err = mergeOptions(request.Options, request.Size, request.Color)
+	if err != nil {
+		return nil, err
+	}
+	request.SKU = strings.TrimSpace(request.SKU)
+	request.Barcode = strings.TrimSpace(request.Barcode)
+	if request.Barcode != utils.EmptyString && !utils.IsValidGTIN(request.Barcode) {
+		return nil, errors.New(utils.InvalidBarcodeError)
+	}
 	isValidProduct, err := service.repo.CheckProductExists(request.ProductID)
 	if err != nil {
 		return nil, err
@@ -39,9 +66,206 @@ func (service *Service) CreateVariant(request *CreateRequest) (*CreateResponse,
 	if err != nil {
 		return nil, err
 	}
+	service.reindex(request.ProductID)
 	return variant, nil
 }
 
+//validateCreatePrice to normalize and validate the price of a new variant, a discount with a zero
+//amount is dropped with its validity window
+func validateCreatePrice(request *CreateRequest) error {
+	request.MRP.Normalize()
+	if request.DiscountPrice != nil {
+		normalizeDiscount(request.DiscountPrice, request.MRP.Currency)
+		if request.DiscountPrice.Amount == 0 {
+			request.DiscountPrice = nil
+		}
+	}
+	if request.DiscountPrice == nil {
+		request.DiscountValidFrom, request.DiscountValidUntil = nil, nil
+	}
+	err := utils.ValidatePrice(request.MRP, request.DiscountPrice)
+	if err != nil {
+		return err
+	}
+	return utils.ValidateDiscountWindow(request.DiscountValidFrom, request.DiscountValidUntil)
+}
+
+//GenerateVariants to create a variant of the product for every combination of the option values,
+//the combinations a variant of the product already has are skipped
+func (service *Service) GenerateVariants(request *GenerateRequest) (*GenerateResponse, error) {
+	template := CreateRequest{
+		MRP:                request.MRP,
+		DiscountPrice:      request.DiscountPrice,
+		DiscountValidFrom:  request.DiscountValidFrom,
+		DiscountValidUntil: request.DiscountValidUntil,
+		ProductID:          request.ProductID,
+		ChangedBy:          request.ChangedBy,
+	}
+	err := validateCreatePrice(&template)
+	if err != nil {
+		return nil, err
+	}
+	options, err := normalizeGenerateOptions(request.Options)
+	if err != nil {
+		return nil, err
+	}
+	isValidProduct, err := service.repo.CheckProductExists(request.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidProduct {
+		return nil, errors.New(utils.ProductIDNotExist)
+	}
+	requests, err := buildCombinations(&template, options, request.NamePattern, request.SKUPattern)
+	if err != nil {
+		return nil, err
+	}
+	response, err := service.repo.GenerateVariants(requests)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Created) > 0 {
+		service.reindex(request.ProductID)
+	}
+	return response, nil
+}
+
+//normalizeGenerateOptions to trim the options and their values dropping repeated values, an option can
+//only be given once and the number of combinations is limited to MaxGeneratedVariants
+func normalizeGenerateOptions(options []GenerateOption) ([]GenerateOption, error) {
+	var normalized []GenerateOption
+	names := make(map[string]bool)
+	combinations := 1
+	for _, option := range options {
+		name := strings.TrimSpace(option.Name)
+		if name == utils.EmptyString || strings.EqualFold(name, ProductIDPlaceholder) {
+			return nil, errors.New(utils.OptionNameError)
+		}
+		if names[strings.ToLower(name)] {
+			return nil, errors.New(utils.DuplicateOptionError)
+		}
+		names[strings.ToLower(name)] = true
+		seen := make(map[string]bool)
+		var values []string
+		for _, value := range option.Values {
+			value = strings.TrimSpace(value)
+			if value == utils.EmptyString || seen[value] {
+				continue
+			}
+			seen[value] = true
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			return nil, errors.New(utils.OptionValueError)
+		}
+		combinations *= len(values)
+		if combinations > MaxGeneratedVariants {
+			return nil, errors.New(utils.TooManyVariantsError)
+		}
+		normalized = append(normalized, GenerateOption{Name: name, Values: values})
+	}
+	return normalized, nil
+}
+
+//buildCombinations to build a create request from the template for every combination of the option
+//values, the values of the first option change the slowest
+func buildCombinations(template *CreateRequest, options []GenerateOption, namePattern string, skuPattern string) ([]CreateRequest, error) {
+	var requests []CreateRequest
+	skus := make(map[string]bool)
+	indexes := make([]int, len(options))
+	for {
+		request := *template
+		values := map[string]string{
+			ProductIDPlaceholder: strconv.Itoa(template.ProductID),
+		}
+		var names []string
+		for i, option := range options {
+			value := option.Values[indexes[i]]
+			request.Options = append(request.Options, OptionValue{
+				Name:     option.Name,
+				Value:    value,
+				Register: true,
+			})
+			values[strings.ToLower(option.Name)] = value
+			names = append(names, value)
+		}
+		request.Name = strings.Join(names, " / ")
+		if namePattern != utils.EmptyString {
+			name, err := expandPattern(namePattern, values, false)
+			if err != nil {
+				return nil, err
+			}
+			request.Name = name
+		}
+		if skuPattern != utils.EmptyString {
+			sku, err := expandPattern(skuPattern, values, true)
+			if err != nil {
+				return nil, err
+			}
+			if sku == utils.EmptyString || len(sku) > MaxSKULength || skus[sku] {
+				return nil, errors.New(utils.SKUPatternError)
+			}
+			skus[sku] = true
+			request.SKU = sku
+		}
+		requests = append(requests, request)
+		i := len(indexes) - 1
+		for ; i >= 0; i-- {
+			indexes[i]++
+			if indexes[i] < len(options[i].Values) {
+				break
+			}
+			indexes[i] = 0
+		}
+		if i < 0 {
+			return requests, nil
+		}
+	}
+}
+
+//patternPlaceholder matches the {name} placeholders of a name or SKU pattern
+var patternPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+//expandPattern to replace the placeholders of the pattern with their values, for SKUs the values are
+//upper cased with the runs of other characters than letters and digits replaced by a hyphen
+func expandPattern(pattern string, values map[string]string, sku bool) (string, error) {
+	var err error
+	expanded := patternPlaceholder.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		key := strings.ToLower(strings.TrimSpace(placeholder[1 : len(placeholder)-1]))
+		value, ok := values[key]
+		if !ok {
+			err = errors.New(utils.InvalidPatternError)
+			return placeholder
+		}
+		if sku {
+			value = skuSegment(value)
+		}
+		return value
+	})
+	if err != nil {
+		return utils.EmptyString, err
+	}
+	return strings.TrimSpace(expanded), nil
+}
+
+//skuSegment to turn an option value into a SKU segment, "Extra Large" becomes "EXTRA-LARGE"
+func skuSegment(value string) string {
+	var builder strings.Builder
+	hyphen := false
+	for _, r := range strings.ToUpper(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && builder.Len() > 0 {
+				builder.WriteRune('-')
+			}
+			builder.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return builder.String()
+}
+
 //UpdateVariant to update the variant
 func (service *Service) UpdateVariant(request *UpdateRequest) error {
 	isExist, err := service.repo.IsVariantIDExists(request.VariantID)
@@ -51,10 +275,107 @@ func (service *Service) UpdateVariant(request *UpdateRequest) error {
 	if !isExist {
 		return errors.New(utils.InvalidVariantID)
 	}
-	if len(request.Name) <= 0 && len(request.Size) <= 0 && len(request.Color) <= 0 && request.MRP == 0 && request.DiscountPrice == 0 {
+	if len(request.Name) <= 0 && len(request.Size) <= 0 && len(request.Color) <= 0 && request.MRP == nil && request.DiscountPrice == nil &&
+		request.DiscountValidFrom == nil && request.DiscountValidUntil == nil && len(request.Options) == 0 &&
+		request.SKU == nil && request.Barcode == nil {
 		return errors.New(utils.NothingToUpdateInVariant)
 	}
-	return service.repo.UpdateVariant(request)
+	if request.SKU != nil {
+		*request.SKU = strings.TrimSpace(*request.SKU)
+	}
+	if request.Barcode != nil {
+		*request.Barcode = strings.TrimSpace(*request.Barcode)
+		if *request.Barcode != utils.EmptyString && !utils.IsValidGTIN(*request.Barcode) {
+			return errors.New(utils.InvalidBarcodeError)
+		}
+	}
+	request.Options, err = mergeOptions(request.Options, request.Size, request.Color)
+	if err != nil {
+		return err
+	}
+	if request.MRP != nil || request.DiscountPrice != nil || request.DiscountValidFrom != nil || request.DiscountValidUntil != nil {
+		err = service.validatePriceUpdate(request)
+		if err != nil {
+			return err
+		}
+	}
+	err = service.repo.UpdateVariant(request)
+	if err != nil {
+		return err
+	}
+	if len(request.Name) > 0 {
+		service.reindexVariant(request.VariantID)
+	}
+	return nil
+}
+
+//validatePriceUpdate to validate the updated prices against the current price of the variant
+func (service *Service) validatePriceUpdate(request *UpdateRequest) error {
+	price, err := service.repo.GetVariantPrice(request.VariantID)
+	if err != nil {
+		return err
+	}
+	if request.MRP != nil {
+		request.MRP.Normalize()
+		price.MRP = *request.MRP
+	}
+	if request.DiscountPrice != nil {
+		normalizeDiscount(request.DiscountPrice, price.MRP.Currency)
+		price.DiscountPrice = request.DiscountPrice
+		if request.DiscountPrice.Amount == 0 {
+			//the validity window is removed with the discount
+			return utils.ValidatePrice(price.MRP, nil)
+		}
+	}
+	if request.DiscountValidFrom != nil {
+		price.DiscountValidFrom = request.DiscountValidFrom
+	}
+	if request.DiscountValidUntil != nil {
+		price.DiscountValidUntil = request.DiscountValidUntil
+	}
+	err = utils.ValidatePrice(price.MRP, price.DiscountPrice)
+	if err != nil {
+		return err
+	}
+	return utils.ValidateDiscountWindow(price.DiscountValidFrom, price.DiscountValidUntil)
+}
+
+//mergeOptions to add the size and color to the option values, they are registered on the product when
+//missing unlike the options which must be defined on the product. An option can only be given once
+func mergeOptions(options []OptionValue, size string, color string) ([]OptionValue, error) {
+	var merged []OptionValue
+	names := make(map[string]bool)
+	for _, v := range options {
+		v.Name = strings.TrimSpace(v.Name)
+		v.Value = strings.TrimSpace(v.Value)
+		if names[strings.ToLower(v.Name)] {
+			return nil, errors.New(utils.DuplicateOptionError)
+		}
+		names[strings.ToLower(v.Name)] = true
+		merged = append(merged, v)
+	}
+	legacy := []OptionValue{
+		{Name: utils.SizeOption, Value: strings.TrimSpace(size), Register: true},
+		{Name: utils.ColorOption, Value: strings.TrimSpace(color), Register: true},
+	}
+	for _, v := range legacy {
+		if v.Value == utils.EmptyString {
+			continue
+		}
+		if names[strings.ToLower(v.Name)] {
+			return nil, errors.New(utils.DuplicateOptionError)
+		}
+		merged = append(merged, v)
+	}
+	return merged, nil
+}
+
+//normalizeDiscount to normalize the discount price, defaulting to the currency of the max retail price
+func normalizeDiscount(discountPrice *utils.Money, currency string) {
+	if discountPrice.Currency == utils.EmptyString {
+		discountPrice.Currency = currency
+	}
+	discountPrice.Normalize()
 }
 
 //DeleteVariant to delete the given variant
@@ -66,7 +387,31 @@ func (service *Service) DeleteVariant(variantID int) error {
 	if !isVariantExist {
 		return errors.New(utils.VariantIDNotExist)
 	}
-	return service.repo.DeleteVariant(variantID)
+	err = service.repo.DeleteVariant(variantID)
+	if err != nil {
+		return err
+	}
+	service.reindexVariant(variantID)
+	return nil
+}
+
+//reindexVariant to refresh the product of the variant in the search index
+func (service *Service) reindexVariant(variantID int) {
+	productID, err := service.repo.GetVariantProductID(variantID)
+	if err != nil {
+		log.Println("Error : error refreshing the search index(reindex) - variant", variantID, "-", err.Error())
+		return
+	}
+	service.reindex(productID)
+}
+
+//reindex to refresh the product in the search index, the change is already saved so a failure is
+//only logged and the next rebuild of the index picks it up
+func (service *Service) reindex(productID int) {
+	err := service.index.IndexProducts(productID)
+	if err != nil {
+		log.Println("Error : error refreshing the search index(reindex) - product", productID, "-", err.Error())
+	}
 }
 
 // ListVariant : to list out all variants of a product
@@ -78,5 +423,76 @@ func (service *Service) ListVariant(request *GetRequest) ([]Variant, error) {
 	if !isValid {
 		return nil, errors.New(utils.ProductIDNotExist)
 	}
-	return service.repo.ListVariant(request)
+	variants, err := service.repo.ListVariant(request)
+	if err != nil {
+		return nil, err
+	}
+	err = service.resolvePrices(variants, request)
+	if err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+//resolvePrices to replace the variant prices with their prices in effect at the requested time
+func (service *Service) resolvePrices(variants []Variant, request *GetRequest) error {
+	basePrices := make([]pricing.BasePrice, len(variants))
+	for i, v := range variants {
+		basePrices[i] = pricing.BasePrice{
+			VariantID:          v.ID,
+			MRP:                v.MRP,
+			DiscountPrice:      v.DiscountPrice,
+			DiscountValidFrom:  v.DiscountValidFrom,
+			DiscountValidUntil: v.DiscountValidUntil,
+		}
+	}
+	resolved, err := service.prices.ResolvePrices(basePrices, &pricing.ResolveRequest{
+		Channel: pricing.DefaultChannel,
+		AsOf:    request.AsOf,
+	})
+	if err != nil {
+		return err
+	}
+	for i, v := range variants {
+		price := resolved[v.ID]
+		variants[i].MRP = price.MRP
+		variants[i].DiscountPrice = price.DiscountPrice
+		variants[i].DiscountValidFrom = price.DiscountValidFrom
+		variants[i].DiscountValidUntil = price.DiscountValidUntil
+		variants[i].EffectivePrice = price.EffectivePrice
+	}
+	return nil
+}
+
+//ListPriceHistory to list the recorded price changes of a variant
+func (service *Service) ListPriceHistory(request *HistoryRequest) ([]PriceHistory, error) {
+	isVariantExist, err := service.repo.IsVariantIDExists(request.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	if !isVariantExist {
+		return nil, errors.New(utils.VariantIDNotExist)
+	}
+	return service.repo.ListPriceHistory(request)
+}
+
+//LookupVariant to get the variant with the SKU or barcode of the request
+func (service *Service) LookupVariant(request *LookupRequest) (*Variant, error) {
+	request.SKU = strings.TrimSpace(request.SKU)
+	request.Barcode = strings.TrimSpace(request.Barcode)
+	if request.Barcode != utils.EmptyString && !utils.IsValidGTIN(request.Barcode) {
+		return nil, errors.New(utils.InvalidBarcodeError)
+	}
+	if request.SKU == utils.EmptyString && request.Barcode == utils.EmptyString {
+		return nil, errors.New(utils.SKUNotExist)
+	}
+	getRequest, err := service.repo.LookupVariant(request)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := service.ListVariant(getRequest)
+	if err != nil {
+		return nil, err
+	}
+	return &variants[0], nil
 }